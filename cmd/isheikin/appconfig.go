@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/spf13/cobra"
+)
+
+// loadAppConfig читает файл настроек при создании команды: он задает
+// значения флагов по умолчанию, поэтому читается до разбора флагов.
+// Возвращенную prepare команда вызывает в начале RunE: она сообщает ошибку
+// чтения файла, применяет переменные окружения, подставляет ссылки ${VAR}
+// и проверяет настройки.
+func loadAppConfig() (cfg *appconfig.AppConfig, prepare func(cmd *cobra.Command) error) {
+	cfg, loadErr := appconfig.Load(os.Args[1:])
+	if loadErr != nil {
+		cfg = appconfig.Default()
+	}
+	return cfg, func(cmd *cobra.Command) error {
+		if loadErr != nil {
+			return configError{loadErr}
+		}
+		if err := appconfig.ApplyEnv(cmd.Flags()); err != nil {
+			return usageError{err}
+		}
+		cfg.MarkOverrides(cmd.Flags())
+		if err := cfg.ExpandEnv(); err != nil {
+			return configError{err}
+		}
+		if err := cfg.Validate(); err != nil {
+			return configError{err}
+		}
+		return nil
+	}
+}
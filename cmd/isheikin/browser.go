@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+)
+
+// openBrowser запускает браузер с параметрами из настроек или подключается
+// к уже запущенному. Браузер возвращается и при ошибке, чтобы задачи
+// без браузера могли выполниться; запущенный при этом Chrome завершается.
+// closeBrowser нужно вызвать всегда, в том числе при ошибке: она закрывает
+// браузер и удаляет временный профиль запущенного Chrome.
+func openBrowser(cfg *appconfig.AppConfig) (browser *rod.Browser, closeBrowser func(), err error) {
+	noop := func() {}
+	proxy, err := proxyURL(cfg.Proxy)
+	if err != nil {
+		return rod.New(), noop, err
+	}
+
+	var l *launcher.Launcher
+	controlURL := cfg.Browser.ControlURL
+	if controlURL == "" {
+		l = launcher.New().
+			Headless(cfg.Browser.Headless).
+			NoSandbox(cfg.Browser.NoSandbox)
+		if cfg.Browser.Bin != "" {
+			l = l.Bin(cfg.Browser.Bin)
+		}
+		if cfg.Browser.UserDataDir != "" {
+			l = l.UserDataDir(cfg.Browser.UserDataDir)
+		}
+		if proxy != nil {
+			// Chrome не принимает учетные данные в адресе прокси
+			server := *proxy
+			server.User = nil
+			l = l.Proxy(server.String())
+			if len(cfg.Proxy.Bypass) > 0 {
+				l = l.Set(flags.Flag("proxy-bypass-list"), strings.Join(cfg.Proxy.Bypass, ";"))
+			}
+		}
+		if controlURL, err = l.Launch(); err != nil {
+			stopLauncher(cfg, l)
+			return rod.New(), noop, fmt.Errorf("failed to launch browser: %w", err)
+		}
+	}
+
+	browser = rod.New().ControlURL(controlURL)
+	if err := browser.Connect(); err != nil {
+		stopLauncher(cfg, l)
+		return rod.New(), noop, err
+	}
+	if proxy != nil && proxy.User != nil {
+		password, _ := proxy.User.Password()
+		if _, err := scrp.HandleProxyAuth(browser, proxy.User.Username(), password); err != nil {
+			_ = browser.Close()
+			stopLauncher(cfg, l)
+			return rod.New(), noop, err
+		}
+	}
+	return browser, func() {
+		if err := browser.Close(); err != nil {
+			stopLauncher(cfg, l)
+			return
+		}
+		if l != nil && cfg.Browser.UserDataDir == "" {
+			l.Cleanup()
+		}
+	}, nil
+}
+
+// stopLauncher завершает браузер, запущенный l, и удаляет его временный
+// профиль. Профиль из настроек остается.
+func stopLauncher(cfg *appconfig.AppConfig, l *launcher.Launcher) {
+	if l == nil || l.PID() == 0 {
+		return
+	}
+	l.Kill()
+	if cfg.Browser.UserDataDir == "" {
+		l.Cleanup()
+	}
+}
+
+// proxyURL разбирает адрес прокси из настроек и добавляет к нему учетные
+// данные, раскрыв ссылки на секреты. Без прокси возвращает nil.
+func proxyURL(cfg appconfig.ProxyConfig) (*url.URL, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		username, err := taskconfig.ResolveSecret(cfg.Username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve proxy username: %w", err)
+		}
+		password, err := taskconfig.ResolveSecret(cfg.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve proxy password: %w", err)
+		}
+		u.User = url.UserPassword(username, password)
+	}
+	return u, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/spf13/cobra"
+)
+
+// newCompletionCmd создает команду "completion": выводит скрипт автодополнения
+// для bash, zsh или fish.
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Print the shell completion script",
+		Long: `Print the shell completion script. Task names for --task, --only and --skip
+are completed from the task config given with -c.
+
+  bash:  source <(isheikin completion bash)
+  zsh:   isheikin completion zsh > "${fpath[1]}/_isheikin"
+  fish:  isheikin completion fish > ~/.config/fish/completions/isheikin.fish`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs)(cmd, args); err != nil {
+				return usageError{err}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, out := cmd.Root(), cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			default:
+				return root.GenFishCompletion(out, true)
+			}
+		},
+	}
+}
+
+// completeTaskNames дополняет имена задач из файла, заданного флагом -c.
+// Значение может быть списком через запятую, тогда дополняется последний элемент.
+func completeTaskNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	path, err := cmd.Flags().GetString("tasks")
+	if err != nil || path == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	loader, err := taskconfig.NewLoaderFor(path)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// Секреты и переменные окружения не раскрываются: нужны только имена
+	tasks, err := loader.Load(path)
+	if err != nil || taskconfig.AssignIDs(tasks) != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	names := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		name := task.Name
+		if name == "" {
+			name = task.ID
+		}
+		names = append(names, fmt.Sprintf("%s%s\t%s", prefix, name, task.URL))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerTaskCompletion включает дополнение имен задач для флагов cmd.
+func registerTaskCompletion(cmd *cobra.Command, flags ...string) {
+	for _, flag := range flags {
+		_ = cmd.RegisterFlagCompletionFunc(flag, completeTaskNames)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/export"
+	"github.com/rx3lixir/ish3ikin/internal/queue"
+)
+
+// coordinate кладет задачи в очередь и собирает их итоги от исполнителей,
+// запущенных с --consume --reply: выгружает записи, выводит итоги и
+// отправляет уведомления, как обычный запуск. Исполнители не ждут
+// зависимостей, поэтому задача кладется в очередь только после успешного
+// итога задач, от которых она зависит.
+func coordinate(ctx context.Context, cfg *appconfig.AppConfig, q queue.Queue, results queue.Results,
+	tasks, disabled []taskconfig.Task, summary *runSummary, manifest *runManifest, logger *slog.Logger) error {
+	// По сигналу перестаем ждать итоги. Задачи остаются в очереди.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	alerts, err := newAlerts(cfg, true, logger)
+	if err != nil {
+		return configError{err}
+	}
+
+	var exporter export.Exporter
+	if cfg.Output.Path != "" {
+		if exporter, err = export.Open(cfg.Output.Path, cfg.Output.Format); err != nil {
+			return err
+		}
+	}
+
+	d := newDispatch(tasks)
+	failDependents := func() {
+		for _, t := range d.blocked() {
+			logger.Warn("⭕ Dependency failed", "task id:", t.ID, "task:", t.Name, "depends on:", t.DependsOn)
+			err := errors.New("not queued: dependency failed")
+			summary.fail(t.ID, t.URL, err)
+			manifest.task(t, taskFailed, 0, 0, 0, err)
+		}
+	}
+	push := func() error {
+		for _, task := range d.ready() {
+			// Зависимости уже выполнены, исполнителю они не нужны
+			task.DependsOn = nil
+			if err := q.Push(ctx, task.Unresolved()); err != nil {
+				return err
+			}
+			logger.Debug("Task queued", "task id:", task.ID, "url:", task.URL)
+		}
+		failDependents()
+		return nil
+	}
+
+	logger.Info("📤 Coordinating tasks", "queue:", cfg.QueueURL, "tasks:", len(tasks))
+	var duplicates int
+	err = push()
+	for err == nil && d.running() {
+		res, nextErr := results.Next(ctx)
+		if nextErr != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				break
+			}
+			logger.Error("Failed to take task result", "error:", nextErr)
+			select {
+			case <-ctx.Done():
+			case <-time.After(queueRetryDelay):
+			}
+			continue
+		}
+		task, ok := d.finish(res)
+		if !ok {
+			logger.Warn("⭕ Ignoring result of an unknown task", "task id:", res.TaskID, "url:", res.URL, "worker:", res.Worker)
+			continue
+		}
+
+		switch {
+		case res.Duplicate:
+			duplicates++
+			logger.Info("🔁 Duplicate task", "task id:", task.ID, "url:", task.URL, "worker:", res.Worker)
+			manifest.task(task, taskDuplicate, 0, 0, 0, nil)
+		case res.Error != "":
+			taskErr := errors.New(res.Error)
+			summary.observe(res.Duration, 0, taskErr)
+			summary.fail(task.ID, task.URL, taskErr)
+			alerts.taskDone("", task, nil, taskErr)
+			manifest.task(task, taskFailed, 0, res.Attempts, res.Duration, taskErr)
+			logger.Error("Task failed", "task id:", task.ID, "task:", task.Name, "worker:", res.Worker, "attempts:", res.Attempts, "error:", res.Error)
+		default:
+			summary.observe(res.Duration, len(res.Records), nil)
+			alerts.taskDone("", task, res.Records, nil)
+			manifest.task(task, taskSucceeded, len(res.Records), res.Attempts, res.Duration, nil)
+			logger.Info("Got results", "task id:", task.ID, "task:", task.Name, "worker:", res.Worker, "duration:", res.Duration, "records:", len(res.Records))
+			if exporter != nil {
+				if err := exporter.Export(res.Records); err != nil {
+					logger.Warn("⭕ Failed to write results", "task id:", task.ID, "error:", err)
+				}
+			}
+		}
+		err = push()
+	}
+
+	if err != nil {
+		logger.Warn("Run interrupted", "error:", err)
+		for _, t := range d.unfinished() {
+			logger.Warn("⭕ Unfinished task", "task id:", t.ID, "task:", t.Name)
+			summary.interrupt(t.ID, t.URL)
+			manifest.task(t, taskAbandoned, 0, 0, 0, nil)
+		}
+		logger.Info("Queued tasks stay in the queue for the workers", "queue:", cfg.QueueURL)
+	}
+
+	if len(disabled) > 0 {
+		logger.Info("Skipped disabled tasks", "count:", len(disabled))
+		for _, t := range disabled {
+			logger.Info("⏸️ Disabled task", "task id:", t.ID, "task:", t.Name, "url:", t.URL)
+			manifest.task(t, taskDisabled, 0, 0, 0, nil)
+		}
+	}
+
+	var output string
+	if exporter != nil {
+		if err := exporter.Close(); err != nil {
+			logger.Error("Failed to write results", "path:", cfg.Output.Path, "error:", err)
+		} else {
+			logger.Info("💾 Results saved", "path:", cfg.Output.Path)
+			output = cfg.Output.Path
+		}
+	}
+	summary.finish(len(disabled)+duplicates, output)
+	reportSummary(cfg, summary, logger)
+	if path := manifestPath(cfg, ""); path != "" {
+		if err := writeManifest(path, manifest, &runState{}, output, cfg.Output.Format, summary.Records); err != nil {
+			logger.Warn("⭕ Failed to write run manifest", "path:", path, "error:", err)
+		} else {
+			logger.Info("🧾 Run manifest saved", "path:", path)
+		}
+	}
+	alerts.runDone("", summary.notification())
+	alerts.close()
+	return runOutcome(cfg, summary, logger)
+}
+
+// dispatch следит, какие задачи координатора можно класть в очередь:
+// задачи ждут успешного итога своих зависимостей.
+type dispatch struct {
+	// waiting - задачи, которые еще не в очереди, queued - задачи
+	// в очереди по ID.
+	waiting []taskconfig.Task
+	queued  map[string]taskconfig.Task
+	// succeeded и failed - имена завершенных задач.
+	succeeded map[string]bool
+	failed    map[string]bool
+}
+
+func newDispatch(tasks []taskconfig.Task) *dispatch {
+	return &dispatch{
+		waiting:   slices.Clone(tasks),
+		queued:    make(map[string]taskconfig.Task),
+		succeeded: make(map[string]bool),
+		failed:    make(map[string]bool),
+	}
+}
+
+// ready возвращает задачи, все зависимости которых выполнены, и считает
+// их поставленными в очередь.
+func (d *dispatch) ready() []taskconfig.Task {
+	var ready []taskconfig.Task
+	d.waiting = slices.DeleteFunc(d.waiting, func(t taskconfig.Task) bool {
+		for _, dep := range t.DependsOn {
+			if !d.succeeded[dep] {
+				return false
+			}
+		}
+		ready = append(ready, t)
+		d.queued[t.ID] = t
+		return true
+	})
+	return ready
+}
+
+// blocked убирает и возвращает задачи, зависимости которых упали,
+// вместе с задачами, зависящими от них.
+func (d *dispatch) blocked() []taskconfig.Task {
+	var blocked []taskconfig.Task
+	for {
+		n := len(blocked)
+		d.waiting = slices.DeleteFunc(d.waiting, func(t taskconfig.Task) bool {
+			if !slices.ContainsFunc(t.DependsOn, func(dep string) bool { return d.failed[dep] }) {
+				return false
+			}
+			blocked = append(blocked, t)
+			d.failed[t.Name] = true
+			return true
+		})
+		if len(blocked) == n {
+			return blocked
+		}
+	}
+}
+
+// finish отмечает итог задачи из очереди. Итоги чужих задач, например
+// оставшиеся от прошлого координатора, не подходят: ok - false. URL
+// итога - URL задачи в очереди, до подстановки переменных окружения.
+func (d *dispatch) finish(res queue.Result) (taskconfig.Task, bool) {
+	task, ok := d.queued[res.TaskID]
+	if !ok || task.Unresolved().URL != res.URL {
+		return taskconfig.Task{}, false
+	}
+	delete(d.queued, task.ID)
+	if res.Error != "" {
+		d.failed[task.Name] = true
+	} else {
+		d.succeeded[task.Name] = true
+	}
+	return task, true
+}
+
+// running сообщает, что итогов еще ждут задачи в очереди.
+func (d *dispatch) running() bool {
+	return len(d.queued) > 0
+}
+
+// unfinished возвращает задачи без итога: в очереди и ждущие зависимостей.
+func (d *dispatch) unfinished() []taskconfig.Task {
+	tasks := slices.Collect(maps.Values(d.queued))
+	slices.SortFunc(tasks, func(a, b taskconfig.Task) int { return strings.Compare(a.ID, b.ID) })
+	return append(tasks, d.waiting...)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/queue"
+)
+
+func taskIDs(tasks []taskconfig.Task) []string {
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+func coordinatedTasks() []taskconfig.Task {
+	return []taskconfig.Task{
+		{ID: "1", Name: "list", URL: "https://example.com/list"},
+		{ID: "2", Name: "page", URL: "https://example.com/page", DependsOn: []string{"list"}},
+		{ID: "3", Name: "item", URL: "https://example.com/item", DependsOn: []string{"page"}},
+		{ID: "4", Name: "other", URL: "https://example.com/other"},
+		{ID: "5", Name: "both", URL: "https://example.com/both", DependsOn: []string{"list", "other"}},
+	}
+}
+
+func TestDispatchFailedDependencyChain(t *testing.T) {
+	d := newDispatch(coordinatedTasks())
+
+	if got := taskIDs(d.ready()); !slices.Equal(got, []string{"1", "4"}) {
+		t.Fatalf("ready = %v, want the tasks without dependencies", got)
+	}
+	if got := d.blocked(); len(got) != 0 {
+		t.Errorf("blocked = %v before any result, want none", taskIDs(got))
+	}
+
+	if _, ok := d.finish(queue.Result{TaskID: "4", URL: "https://example.com/other"}); !ok {
+		t.Fatal("finish of a queued task was not accepted")
+	}
+	if got := d.ready(); len(got) != 0 {
+		t.Errorf("ready = %v while list is still running, want none", taskIDs(got))
+	}
+
+	task, ok := d.finish(queue.Result{TaskID: "1", URL: "https://example.com/list", Error: "timeout"})
+	if !ok || task.Name != "list" {
+		t.Fatalf("finish = %v, %v, want the list task", task, ok)
+	}
+	if got := d.ready(); len(got) != 0 {
+		t.Errorf("ready = %v after list failed, want none", taskIDs(got))
+	}
+	// Падение list блокирует всю цепочку, а не только прямых зависимых
+	blocked := taskIDs(d.blocked())
+	slices.Sort(blocked)
+	if !slices.Equal(blocked, []string{"2", "3", "5"}) {
+		t.Errorf("blocked = %v, want page, item and both", blocked)
+	}
+	if d.running() || len(d.unfinished()) != 0 {
+		t.Errorf("running = %v, unfinished = %v, want the run to be over", d.running(), taskIDs(d.unfinished()))
+	}
+}
+
+func TestDispatchSucceededChain(t *testing.T) {
+	d := newDispatch(coordinatedTasks())
+	var order []string
+	for {
+		ready := d.ready()
+		if len(ready) == 0 {
+			break
+		}
+		for _, task := range ready {
+			order = append(order, task.ID)
+			if _, ok := d.finish(queue.Result{TaskID: task.ID, URL: task.URL}); !ok {
+				t.Fatalf("finish of %s was not accepted", task.ID)
+			}
+		}
+	}
+	if !slices.Equal(order, []string{"1", "4", "2", "5", "3"}) {
+		t.Errorf("queued in order %v, want every task after its dependencies", order)
+	}
+	if d.running() {
+		t.Error("dispatch is still running after every task finished")
+	}
+}
+
+func TestDispatchIgnoresForeignResults(t *testing.T) {
+	d := newDispatch(coordinatedTasks())
+	d.ready()
+
+	for _, res := range []queue.Result{
+		{TaskID: "9", URL: "https://example.com/list"},
+		// ID из прошлого запуска с другим конфигом
+		{TaskID: "1", URL: "https://example.com/old"},
+		// Задача еще не в очереди
+		{TaskID: "2", URL: "https://example.com/page"},
+	} {
+		if _, ok := d.finish(res); ok {
+			t.Errorf("finish(%+v) was accepted, want it ignored", res)
+		}
+	}
+	if _, ok := d.finish(queue.Result{TaskID: "1", URL: "https://example.com/list"}); !ok {
+		t.Fatal("finish of a queued task was not accepted")
+	}
+	if _, ok := d.finish(queue.Result{TaskID: "1", URL: "https://example.com/list"}); ok {
+		t.Error("second result of the same task was accepted")
+	}
+	if got := taskIDs(d.unfinished()); !slices.Equal(got, []string{"4", "2", "3", "5"}) {
+		t.Errorf("unfinished = %v, want the queued task first, then the waiting ones", got)
+	}
+}
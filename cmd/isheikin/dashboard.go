@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+
+	applog "github.com/rx3lixir/ish3ikin/internal/lib/logger"
+	"github.com/rx3lixir/ish3ikin/internal/tui"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+// dashboardHooks дополняет обработчики пула передачей событий задач в панель.
+// Без панели возвращает hooks как есть.
+func dashboardHooks(hooks workerpool.Hooks, dash *tui.Dashboard, entries *runEntries) workerpool.Hooks {
+	if dash == nil {
+		return hooks
+	}
+	onStart, onRetry := hooks.OnTaskStart, hooks.OnTaskRetry
+	hooks.OnTaskStart = func(e workerpool.TaskEvent) {
+		onStart(e)
+		task := entries.get(e.TaskID).task
+		dash.TaskStarted(task.ID, e.Name, task.URL, e.Attempt)
+	}
+	hooks.OnTaskRetry = func(e workerpool.TaskEvent) {
+		onRetry(e)
+		dash.TaskRetrying(entries.get(e.TaskID).task.ID, e.Attempt, e.Err)
+	}
+	return hooks
+}
+
+// stopDashboard закрывает панель и возвращает лог в stdout.
+func stopDashboard(dash *tui.Dashboard, logOut *applog.Output) {
+	dash.Stop()
+	logOut.SetConsole(os.Stdout)
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/diff"
+	applog "github.com/rx3lixir/ish3ikin/internal/lib/logger"
+	"github.com/rx3lixir/ish3ikin/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// newDiffCmd создает команду "diff": сравнивает результаты двух запусков
+// из каталога запусков по задачам.
+func newDiffCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+
+	cmd := &cobra.Command{
+		Use:   "diff [<old-run> [<new-run>]]",
+		Short: "Show changed records between two runs",
+		Long: `Compare the records of runs kept in the runs directory, task by task.
+Without arguments every task of the last run is compared to the latest
+earlier run that completed it, with one argument the same is done for the
+given run, with two arguments the second run is compared to the first one.
+Tasks the new run did not run are not compared. "last" names the latest run.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MaximumNArgs(2)(cmd, args); err != nil {
+				return usageError{err}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+			if cfg.RunsDir == "" {
+				return usageError{errors.New("runs directory is not set, use --runs-dir")}
+			}
+			history, next, err := diffRunIDs(cfg.RunsDir, args)
+			if err != nil {
+				return usageError{err}
+			}
+			return diffRuns(cmd.OutOrStdout(), cfg.RunsDir, history, next)
+		},
+	}
+	cmd.Flags().StringVar(&cfg.RunsDir, "runs-dir", cfg.RunsDir, "Directory keeping the state of every run")
+	return cmd
+}
+
+// diffRunIDs выбирает по аргументам команды новый запуск и запуски,
+// с которыми он сравнивается, от новых к старым.
+func diffRunIDs(dir string, args []string) (history []string, next string, err error) {
+	runs, err := listRuns(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	resolve := func(id string) string {
+		if id == appconfig.ResumeLast && len(runs) > 0 {
+			return runs[len(runs)-1]
+		}
+		return id
+	}
+
+	switch len(args) {
+	case 2:
+		return []string{resolve(args[0])}, resolve(args[1]), nil
+	case 1:
+		next = resolve(args[0])
+	default:
+		if len(runs) == 0 {
+			return nil, "", fmt.Errorf("no runs in %s", dir)
+		}
+		next = runs[len(runs)-1]
+	}
+	if history = earlierRuns(runs, next); len(history) == 0 {
+		return nil, "", fmt.Errorf("no run before %s in %s", next, dir)
+	}
+	return history, next, nil
+}
+
+// diffRuns выводит изменения запуска next по сравнению с запусками history.
+func diffRuns(out io.Writer, dir string, history []string, next string) error {
+	if slices.Contains(history, next) {
+		return usageError{fmt.Errorf("cannot compare run %s to itself", next)}
+	}
+	nextStore, err := openRun(dir, next)
+	if err != nil {
+		return err
+	}
+	defer nextStore.Close()
+	h := newRunHistory(dir, history)
+	defer h.Close()
+
+	changes, unchanged, err := compareRuns(h, nextStore)
+	if err != nil {
+		return err
+	}
+	if len(history) == 1 {
+		fmt.Fprintf(out, "Comparing run %s to %s\n", next, history[0])
+	} else {
+		fmt.Fprintf(out, "Comparing run %s to the latest earlier run of each task\n", next)
+	}
+	printDiff(out, changes, unchanged)
+	return nil
+}
+
+// openRun открывает состояние запуска id из каталога запусков.
+func openRun(dir, id string) (*state.Store, error) {
+	path := filepath.Join(dir, id+stateExt)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("run %s not found in %s", id, dir)
+	}
+	return state.Open(path)
+}
+
+// printRunDiff выводит изменения записей текущего запуска по сравнению
+// с запуском, начатым перед ним.
+func printRunDiff(cfg *appconfig.AppConfig, rs *runState, logger *slog.Logger) error {
+	if rs.id == "" {
+		return errors.New("--diff needs the runs directory, it cannot be used with --state or --consume")
+	}
+	runs, err := listRuns(cfg.RunsDir)
+	if err != nil {
+		return err
+	}
+	history := earlierRuns(runs, rs.id)
+	if len(history) == 0 {
+		logger.Info("🔀 No previous run to compare with")
+		return nil
+	}
+	h := newRunHistory(cfg.RunsDir, history)
+	defer h.Close()
+
+	changes, unchanged, err := compareRuns(h, rs.store)
+	if err != nil {
+		return err
+	}
+	if cfg.Log.Format == applog.FormatJSON {
+		logDiff(logger, changes, unchanged)
+		return nil
+	}
+	fmt.Fprintln(console(cfg), "\nChanges since the previous runs")
+	printDiff(console(cfg), changes, unchanged)
+	return nil
+}
+
+// taskChange - изменение результатов одной задачи. Added - задача
+// выполнилась впервые, Changed - изменились записи. Failed отмечает задачу,
+// которая в новом запуске не выполнилась, поэтому ее записи
+// не сравнивались. since - запуск, с которым сравнивалась задача.
+type taskChange struct {
+	task    state.TaskInfo
+	kind    diff.Kind
+	failed  bool
+	count   int
+	since   string
+	records []diff.Change
+}
+
+// compareRuns сравнивает записи задач, выполненных в запуске next,
+// с последним более ранним запуском, где задача выполнилась. Задачи,
+// которых не было в next, не сравниваются: запуск с --only не делает
+// остальные задачи удаленными. Задачи сопоставляются по ключу состояния,
+// поэтому задача, измененная в конфиге, считается новой.
+func compareRuns(history *runHistory, next *state.Store) (changes []taskChange, unchanged int, err error) {
+	nextKeys, err := next.Completed()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read state: %w", err)
+	}
+	pending, err := next.Pending()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	for _, key := range nextKeys {
+		task, records, err := taskRecords(next, key)
+		if err != nil {
+			return nil, 0, err
+		}
+		since, before, found, err := history.records(key)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !found {
+			changes = append(changes, taskChange{task: task, kind: diff.Added, count: len(records)})
+			continue
+		}
+		if rc := diff.Records(before, records); len(rc) > 0 {
+			changes = append(changes, taskChange{task: task, kind: diff.Changed, since: since, records: rc})
+		} else {
+			unchanged++
+		}
+	}
+	for _, key := range pending {
+		since, before, found, err := history.records(key)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !found {
+			continue
+		}
+		task, _, err := taskRecords(next, key)
+		if err != nil {
+			return nil, 0, err
+		}
+		changes = append(changes, taskChange{task: task, kind: diff.Removed, failed: true, since: since, count: len(before)})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].task.Name < changes[j].task.Name })
+	return changes, unchanged, nil
+}
+
+// runHistory - более ранние запуски, в которых ищутся прошлые записи задач.
+// Состояния запусков открываются по мере надобности.
+type runHistory struct {
+	dir string
+	// runs - идентификаторы запусков от новых к старым.
+	runs   []string
+	stores map[string]*state.Store
+	done   map[string]map[string]bool
+}
+
+func newRunHistory(dir string, runs []string) *runHistory {
+	return &runHistory{dir: dir, runs: runs, stores: make(map[string]*state.Store), done: make(map[string]map[string]bool)}
+}
+
+// records возвращает записи задачи key из последнего запуска, где она
+// выполнилась, и идентификатор этого запуска.
+func (h *runHistory) records(key string) (run string, records []map[string]string, found bool, err error) {
+	for _, run := range h.runs {
+		store, done, err := h.open(run)
+		if err != nil {
+			return "", nil, false, err
+		}
+		if !done[key] {
+			continue
+		}
+		_, records, err := taskRecords(store, key)
+		return run, records, err == nil, err
+	}
+	return "", nil, false, nil
+}
+
+func (h *runHistory) open(run string) (*state.Store, map[string]bool, error) {
+	if store, ok := h.stores[run]; ok {
+		return store, h.done[run], nil
+	}
+	store, err := openRun(h.dir, run)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := store.Completed()
+	if err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	h.stores[run], h.done[run] = store, keySet(keys)
+	return store, h.done[run], nil
+}
+
+func (h *runHistory) Close() {
+	for _, store := range h.stores {
+		store.Close()
+	}
+}
+
+func taskRecords(store *state.Store, key string) (state.TaskInfo, []map[string]string, error) {
+	task, err := store.Task(key)
+	if err != nil {
+		return task, nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if task.Name == "" && task.ID == "" {
+		task.ID = key
+	}
+	records, err := store.Records(key)
+	if err != nil {
+		return task, nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	return task, records, nil
+}
+
+func keySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+// printDiff выводит изменения по задачам: + добавлено, - удалено, ~ изменено.
+func printDiff(out io.Writer, changes []taskChange, unchanged int) {
+	r := lipgloss.NewRenderer(out)
+	var (
+		added   = r.NewStyle().Foreground(lipgloss.Color("10"))
+		removed = r.NewStyle().Foreground(lipgloss.Color("9"))
+		changed = r.NewStyle().Foreground(lipgloss.Color("11"))
+		dim     = r.NewStyle().Faint(true)
+	)
+	mark := func(kind diff.Kind) string {
+		switch kind {
+		case diff.Added:
+			return added.Render("+")
+		case diff.Removed:
+			return removed.Render("-")
+		default:
+			return changed.Render("~")
+		}
+	}
+
+	for _, c := range changes {
+		title := fmt.Sprintf("%s %s %s", mark(c.kind), c.task.ID, c.task.Name)
+		switch {
+		case c.failed:
+			fmt.Fprintln(out, title, dim.Render(c.task.URL), removed.Render("failed in the new run"), dim.Render("last completed in "+c.since))
+			continue
+		case c.kind == diff.Added:
+			fmt.Fprintln(out, title, dim.Render(c.task.URL), added.Render(fmt.Sprintf("new task, %d records", c.count)))
+			continue
+		}
+
+		fmt.Fprintln(out, title, dim.Render(c.task.URL), dim.Render("since "+c.since))
+		for _, rc := range c.records {
+			fmt.Fprintf(out, "    %s %s\n", mark(rc.Kind), rc.Key)
+			for _, f := range rc.Fields {
+				switch f.Kind {
+				case diff.Added:
+					fmt.Fprintf(out, "        %s %s: %s\n", mark(f.Kind), f.Name, shortText(f.New))
+				case diff.Removed:
+					fmt.Fprintf(out, "        %s %s: %s\n", mark(f.Kind), f.Name, shortText(f.Old))
+				default:
+					fmt.Fprintf(out, "        %s %s: %s → %s\n", mark(f.Kind), f.Name, removed.Render(shortText(f.Old)), added.Render(shortText(f.New)))
+				}
+			}
+		}
+	}
+	fmt.Fprintf(out, "%d tasks changed, %d unchanged\n", len(changes), unchanged)
+}
+
+// logDiff пишет изменения по задачам в лог, по записи на задачу.
+func logDiff(logger *slog.Logger, changes []taskChange, unchanged int) {
+	for _, c := range changes {
+		switch {
+		case c.failed:
+			logger.Warn("🔀 Task failed, not compared", "task id:", c.task.ID, "task:", c.task.Name, "url:", c.task.URL)
+		case c.kind == diff.Changed:
+			logger.Info("🔀 Task changed", "task id:", c.task.ID, "task:", c.task.Name, "url:", c.task.URL, "since:", c.since, "records:", len(c.records))
+		default:
+			logger.Info("🔀 Task "+c.kind.String(), "task id:", c.task.ID, "task:", c.task.Name, "url:", c.task.URL, "records:", c.count)
+		}
+	}
+	logger.Info("🔀 Run diff", "changed:", len(changes), "unchanged:", unchanged)
+}
+
+// earlierRuns возвращает запуски, сохраненные перед id, от новых к старым,
+// или все запуски, если id нет в списке.
+func earlierRuns(runs []string, id string) []string {
+	if i := slices.Index(runs, id); i >= 0 {
+		runs = runs[:i]
+	}
+	earlier := slices.Clone(runs)
+	slices.Reverse(earlier)
+	return earlier
+}
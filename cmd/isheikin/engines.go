@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rx3lixir/ish3ikin/internal/captcha"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/replay"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+)
+
+// newEngines создает скраперы всех движков по настройкам cfg.
+func newEngines(cfg *appconfig.AppConfig, browser *rod.Browser, logger *slog.Logger) (scrp.Engines, error) {
+	rodScraper := scrp.NewRodScraper(browser, logger)
+	rodScraper.DebugDir = cfg.DebugArtifacts
+	rodScraper.NavigationTimeout = time.Duration(cfg.NavigationTimeout) * time.Second
+	rodScraper.ExtractionTimeout = time.Duration(cfg.ExtractionTimeout) * time.Second
+	if cfg.CaptchaKey != "" {
+		captchaKey, err := taskconfig.ResolveSecret(cfg.CaptchaKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve captcha key: %w", err)
+		}
+		rodScraper.CaptchaSolver = captcha.NewTwoCaptcha(captchaKey, cfg.CaptchaURL)
+	}
+
+	feedScraper := scrp.NewFeedScraper(logger)
+	feedScraper.Timeout = time.Duration(cfg.NavigationTimeout) * time.Second
+	if proxy, err := proxyURL(cfg.Proxy); err == nil && proxy != nil {
+		feedScraper.Client.Transport = scrp.ProxyTransport(proxy, cfg.Proxy.Bypass)
+	}
+
+	cassette, err := openCassette(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cassette != nil {
+		rodScraper.Cassette = cassette
+		feedScraper.Client.Transport = cassette.Transport(feedScraper.Client.Transport)
+	}
+
+	return scrp.Engines{
+		taskconfig.EngineBrowser: rodScraper,
+		taskconfig.EngineFeed:    feedScraper,
+	}, nil
+}
+
+// openCassette открывает каталог записи ответов для --record или --replay.
+// Без этих флагов возвращает nil.
+func openCassette(cfg *appconfig.AppConfig) (*replay.Store, error) {
+	switch {
+	case cfg.Record != "":
+		return replay.Open(cfg.Record, replay.Record)
+	case cfg.Replay != "":
+		return replay.Open(cfg.Replay, replay.Replay)
+	}
+	return nil, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// templates - примеры файлов задач и настроек для команды "init".
+//
+//go:embed templates
+var templates embed.FS
+
+const (
+	// defaultTemplate - шаблон задач без аргумента команды.
+	defaultTemplate = "basic"
+	// appConfigTemplate - шаблон файла настроек.
+	appConfigTemplate = "ish3ikin"
+	// initTasksFile - имя создаваемого файла задач, на него ссылается файл настроек.
+	initTasksFile = "tasks.yaml"
+)
+
+// newInitCmd создает команду "init", которая записывает в каталог пример
+// файла задач с комментариями и файл настроек.
+func newInitCmd() *cobra.Command {
+	var (
+		dir   string
+		force bool
+		list  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "init [template]",
+		Short: "Write an annotated example task file and app config",
+		Long: "Write tasks.yaml and ish3ikin.yaml into the directory. The task file is taken\n" +
+			"from a built-in template, see --list.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := templateNames()
+			if err != nil {
+				return err
+			}
+			if list {
+				fmt.Fprintln(cmd.OutOrStdout(), strings.Join(names, "\n"))
+				return nil
+			}
+
+			name := defaultTemplate
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if !slices.Contains(names, name) {
+				return usageError{fmt.Errorf("unknown template %q, available: %s", name, strings.Join(names, ", "))}
+			}
+
+			files := []struct{ path, template string }{
+				{filepath.Join(dir, initTasksFile), name},
+				{filepath.Join(dir, appConfigTemplate+".yaml"), appConfigTemplate},
+			}
+			// Проверяем оба файла заранее, чтобы не записать только один из них
+			if !force {
+				for _, file := range files {
+					if _, err := os.Stat(file.path); err == nil {
+						return fmt.Errorf("%s already exists, use --force to overwrite it", file.path)
+					}
+				}
+			}
+			for _, file := range files {
+				if err := writeTemplate(file.path, file.template, force); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", file.path)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Next: edit the URLs and selectors, then run 'isheikin validate -c tasks.yaml' and 'isheikin run'")
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&dir, "dir", "d", ".", "Directory to write the files into")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing files")
+	cmd.Flags().BoolVar(&list, "list", false, "List the built-in task templates")
+	return cmd
+}
+
+// writeTemplate записывает шаблон name в path. Существующий файл
+// перезаписывается только с force.
+func writeTemplate(path, name string, force bool) error {
+	data, err := templates.ReadFile("templates/" + name + ".yaml")
+	if err != nil {
+		return fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+		}
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// templateNames возвращает имена встроенных шаблонов задач.
+func templateNames() ([]string, error) {
+	entries, err := templates.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".yaml")
+		if name != appConfigTemplate {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -1,73 +1,109 @@
 package main
 
 import (
-	"context"
-	"log"
-	"time"
+	"errors"
+	"fmt"
+	"os"
 
-	"github.com/go-rod/rod"
-	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
-	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
-	"github.com/rx3lixir/ish3ikin/internal/lib/logger"
-	"github.com/rx3lixir/ish3ikin/internal/lib/work"
-	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
-)
-
-const (
-	numWorkers = 6
+	"github.com/spf13/cobra"
 )
 
 func main() {
-	// Инициализация логгера
-	logger := logger.NewLogger()
-
-	// Загрузка конфигурации
-	cfg := appconfig.NewAppConfig()
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
 
-	// В зависимости от расширения файла конфигурации создаем лоадер
-	loader := taskconfig.NewJSONLoader()
+// newRootCmd собирает дерево команд приложения.
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "isheikin",
+		Short:        "Scrape web pages and feeds described by task config files",
+		Version:      readBuildInfo().Version,
+		SilenceUsage: true,
+		// Свою команду completion объявляем сами, см. newCompletionCmd
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+	root.AddCommand(
+		newRunCmd(),
+		newValidateCmd(),
+		newInitCmd(),
+		newMigrateCmd(),
+		newReplCmd(),
+		newTestCmd(),
+		newScrapeCmd(),
+		newSuggestCmd(),
+		newServeCmd(),
+		newScheduleCmd(),
+		newDiffCmd(),
+		newCompletionCmd(),
+		newVersionCmd(),
+	)
+	return root
+}
 
-	// Создаем контекст
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(time.Second*time.Duration(cfg.Timeout)))
-	defer cancel()
+// Коды выхода, по которым cron и CI отличают сломанный конфиг
+// от упавших задач.
+const (
+	exitError      = 1
+	exitUsage      = 2
+	exitConfig     = 3
+	exitSomeFailed = 4
+	exitAllFailed  = 5
+)
 
-	// Загружаем задачи
-	tasks, err := loader.Load(cfg.ConfigPath)
-	if err != nil {
-		logger.Error("Failed to load tasks", err)
-	}
+// usageError - ошибка в аргументах команды.
+type usageError struct {
+	error
+}
 
-	// Создаем инстанс браузера
-	browser := rod.New()
-	if err := browser.Connect(); err != nil {
-		logger.Error("Error connecting to browser", err)
-	}
-	defer browser.Close()
+func (e usageError) Unwrap() error {
+	return e.error
+}
 
-	// Создаем новый скраппер
-	scraper := scrp.NewRodScraper(browser, *logger)
+// configError - ошибка в файле настроек или файле задач.
+type configError struct {
+	error
+}
 
-	// Инициализируем воркерпул
-	pool, err := work.NewPool(numWorkers, len(tasks))
-	if err != nil {
-		log.Fatalf("Failed to create worker pool: %v", err)
-	}
+func (e configError) Unwrap() error {
+	return e.error
+}
 
-	pool.Start(ctx)
+// tasksFailedError сообщает, что часть задач запуска завершилась ошибкой.
+type tasksFailedError struct {
+	failed, total int
+}
 
-	// Добавляем задачи
-	for _, task := range tasks {
-		scraperTask := scrp.NewScraperTask(task, ctx, scraper, *logger)
-		pool.AddTask(scraperTask)
-	}
+func (e tasksFailedError) Error() string {
+	return fmt.Sprintf("%d of %d tasks failed", e.failed, e.total)
+}
 
-	// Выводим результаты
-	go func() {
-		for res := range pool.Results() {
-			logger.Printf("Got results: %v\n", res)
-		}
-	}()
+func (e tasksFailedError) all() bool {
+	return e.failed == e.total
+}
 
-	pool.Stop()
-	logger.Info("All tasks completed!")
+// exitCode возвращает код выхода для ошибки команды: 2 - неверные
+// аргументы, 3 - ошибка конфига, 4 - часть задач упала, 5 - упали
+// все задачи, 1 - остальные ошибки.
+func exitCode(err error) int {
+	var (
+		usage  usageError
+		config configError
+		failed tasksFailedError
+	)
+	switch {
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &config):
+		return exitConfig
+	case errors.As(err, &failed) && failed.all():
+		return exitAllFailed
+	case errors.As(err, &failed):
+		return exitSomeFailed
+	}
+	return exitError
 }
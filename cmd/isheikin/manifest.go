@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/export"
+)
+
+// Состояния задач в манифесте запуска.
+const (
+	taskSucceeded = "succeeded"
+	taskFailed    = "failed"
+	taskAbandoned = "abandoned"
+	taskDuplicate = "duplicate"
+	taskDisabled  = "disabled"
+	// taskFresh - URL задачи выполнялся недавно, см. --fresh-for.
+	taskFresh = "fresh"
+	// taskResumed - задача выполнена в прошлой попытке продолженного запуска.
+	taskResumed = "resumed"
+)
+
+// runManifest описывает запуск для аудита: с какой сборкой и каким
+// конфигом он выполнен, чем закончилась каждая задача и какие файлы записаны.
+type runManifest struct {
+	RunID string `json:",omitempty"`
+	Build buildInfo
+	// TasksPath и AppConfig - файл задач и файл настроек запуска, ConfigHash
+	// и AppConfigHash - SHA-256 загруженных задач и итоговых настроек.
+	TasksPath     string
+	AppConfig     string `json:",omitempty"`
+	Profile       string `json:",omitempty"`
+	ConfigHash    string
+	AppConfigHash string
+	Started       time.Time
+	Finished      time.Time
+	Tasks         []manifestTask
+	Outputs       []manifestOutput `json:",omitempty"`
+}
+
+// manifestTask - итог одной задачи запуска.
+type manifestTask struct {
+	ID       string
+	Name     string
+	URL      string
+	Status   string
+	Records  int          `json:",omitempty"`
+	Attempts int          `json:",omitempty"`
+	Duration jsonDuration `json:",omitempty"`
+	Error    string       `json:",omitempty"`
+}
+
+// manifestOutput - файл, записанный запуском.
+type manifestOutput struct {
+	Path    string
+	Format  string
+	Records int
+	Bytes   int64
+	SHA256  string
+}
+
+// newRunManifest начинает манифест запуска. ConfigHash задается после
+// загрузки задач.
+func newRunManifest(cfg *appconfig.AppConfig) *runManifest {
+	return &runManifest{
+		Build:         readBuildInfo(),
+		TasksPath:     cfg.ConfigPath,
+		AppConfig:     cfg.File,
+		Profile:       cfg.Profile,
+		AppConfigHash: hashJSON(cfg),
+		Started:       time.Now(),
+	}
+}
+
+// task добавляет итог задачи.
+func (m *runManifest) task(task taskconfig.Task, status string, records, attempts int, d time.Duration, err error) {
+	t := manifestTask{
+		ID:       task.ID,
+		Name:     task.Name,
+		URL:      task.URL,
+		Status:   status,
+		Records:  records,
+		Attempts: attempts,
+		Duration: jsonDuration(d),
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	m.Tasks = append(m.Tasks, t)
+}
+
+// output добавляет записанный файл результатов.
+func (m *runManifest) output(path, format string, records int) error {
+	if format == "" {
+		format = export.FormatFor(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	m.Outputs = append(m.Outputs, manifestOutput{
+		Path:    path,
+		Format:  format,
+		Records: records,
+		Bytes:   n,
+		SHA256:  hex.EncodeToString(h.Sum(nil)),
+	})
+	return nil
+}
+
+// writeManifest дополняет манифест задачами, выполненными в прошлых попытках
+// запуска, и файлом результатов output и записывает его в path.
+func writeManifest(path string, m *runManifest, rs *runState, output, format string, records int) error {
+	for i, task := range rs.doneTasks {
+		done, err := rs.store.Records(rs.done[i])
+		if err != nil {
+			return fmt.Errorf("failed to read state: %w", err)
+		}
+		m.task(task, taskResumed, len(done), 0, 0, nil)
+	}
+	if output != "" {
+		if err := m.output(output, format, records); err != nil {
+			return fmt.Errorf("failed to read output file: %w", err)
+		}
+	}
+
+	m.Finished = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// manifestPath выбирает файл манифеста: --manifest или файл рядом
+// с состоянием запуска в каталоге запусков. Пустой путь - манифест не пишется.
+func manifestPath(cfg *appconfig.AppConfig, runID string) string {
+	if cfg.ManifestPath != "" {
+		return cfg.ManifestPath
+	}
+	if runID == "" || cfg.RunsDir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.RunsDir, runID+manifestExt)
+}
+
+// manifestExt - расширение манифестов в каталоге запусков.
+const manifestExt = ".manifest.json"
+
+// hashJSON возвращает SHA-256 значения v в JSON.
+func hashJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
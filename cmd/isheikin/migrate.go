@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCmd создает команду "migrate", которая приводит файлы задач
+// к текущей версии формата.
+func newMigrateCmd() *cobra.Command {
+	var write bool
+	cmd := &cobra.Command{
+		Use:   "migrate <file|dir|glob>...",
+		Short: "Upgrade task files to the current format version",
+		Long: fmt.Sprintf("Report task files older than format version %d, or rewrite them with --write.\n"+
+			"Version %d only adds the Version field and wraps a bare array of tasks in an object with Tasks;\n"+
+			"task fields are left as they are. Rewritten files lose comments and key order.",
+			taskconfig.CurrentVersion, taskconfig.CurrentVersion),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			for _, arg := range args {
+				files, err := taskconfig.ConfigFiles(arg)
+				if err != nil {
+					return err
+				}
+				for _, file := range files {
+					data, from, err := taskconfig.MigrateFile(file)
+					if err != nil {
+						return fmt.Errorf("%s: %w", file, err)
+					}
+					if from == taskconfig.CurrentVersion {
+						fmt.Fprintf(out, "%s: up to date\n", file)
+						continue
+					}
+					if !write {
+						fmt.Fprintf(out, "%s: version %d, needs migration to %d\n", file, from, taskconfig.CurrentVersion)
+						continue
+					}
+					info, err := os.Stat(file)
+					if err != nil {
+						return err
+					}
+					if err := os.WriteFile(file, data, info.Mode().Perm()); err != nil {
+						return fmt.Errorf("failed to write %s: %w", file, err)
+					}
+					fmt.Fprintf(out, "%s: migrated from version %d to %d\n", file, from, taskconfig.CurrentVersion)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&write, "write", "w", false, "Rewrite the files in place")
+	return cmd
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/changes"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/diff"
+	"github.com/rx3lixir/ish3ikin/internal/notify"
+	"github.com/rx3lixir/ish3ikin/internal/rules"
+)
+
+const (
+	// notifyTimeout ограничивает доставку одного события во все каналы.
+	notifyTimeout = time.Minute
+	// streaksFile - файл счетчиков неудач подряд в каталоге запусков.
+	streaksFile = "failure-streaks.json"
+	// brokenPrefix отличает в счетчиках запуски с пустыми полями от ошибок.
+	brokenPrefix = "selectors:"
+	// changesFile - файл последних записей задач в каталоге запусков.
+	// Расширение не stateExt, чтобы файл не считался запуском.
+	changesFile = "changes.bolt"
+)
+
+// alerts отправляет уведомления о завершении запусков, о задачах, которые
+// падают несколько раз подряд, селекторы которых перестали находить поля
+// или записи которых изменились.
+type alerts struct {
+	dispatcher *notify.Dispatcher
+	streaks    *notify.Streaks
+	changes    *changeFile
+	threshold  int
+	logger     *slog.Logger
+	wg         sync.WaitGroup
+}
+
+// newAlerts создает уведомления по cfg.Notify. Если persist, счетчики неудач
+// хранятся в каталоге запусков, чтобы их не сбрасывал каждый запуск из cron;
+// долгоживущие команды держат их в памяти.
+func newAlerts(cfg *appconfig.AppConfig, persist bool, logger *slog.Logger) (*alerts, error) {
+	dispatcher := notify.NewDispatcher(logger)
+	for _, hook := range cfg.Notify.Webhooks {
+		headers := make(map[string]string, len(hook.Headers))
+		for name, value := range hook.Headers {
+			resolved, err := taskconfig.ResolveSecret(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve webhook header %s: %w", name, err)
+			}
+			headers[name] = resolved
+		}
+		u, _ := url.Parse(hook.URL)
+		dispatcher.Add("webhook "+u.Host, &notify.Webhook{URL: hook.URL, Headers: headers}, hook.Events)
+	}
+	for _, chat := range cfg.Notify.Telegram {
+		token, err := taskconfig.ResolveSecret(chat.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve telegram token: %w", err)
+		}
+		chatID := string(chat.ChatID)
+		dispatcher.Add("telegram "+chatID, &notify.Telegram{Token: token, ChatID: chatID}, chat.Events)
+	}
+	for i, slack := range cfg.Notify.Slack {
+		endpoint, err := taskconfig.ResolveSecret(slack.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve slack webhook URL: %w", err)
+		}
+		templates, err := notify.ParseTemplates(slack.Templates)
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.Add(fmt.Sprintf("slack %d", i+1), &notify.Slack{URL: endpoint, Templates: templates}, slack.Events)
+	}
+	for i, discord := range cfg.Notify.Discord {
+		endpoint, err := taskconfig.ResolveSecret(discord.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve discord webhook URL: %w", err)
+		}
+		dispatcher.Add(fmt.Sprintf("discord %d", i+1), &notify.Discord{URL: endpoint, Username: discord.Username}, discord.Events)
+	}
+	for _, email := range cfg.Notify.Email {
+		password, err := taskconfig.ResolveSecret(email.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve email password: %w", err)
+		}
+		subject, body, err := notify.ParseEmailTemplates(email.Subject, email.Template)
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.Add("email "+strings.Join(email.To, ","), &notify.Email{
+			Host:     email.Host,
+			Port:     email.Port,
+			Username: email.Username,
+			Password: password,
+			From:     email.From,
+			To:       email.To,
+			Subject:  subject,
+			Body:     body,
+			Attach:   email.Attach,
+			LinkBase: email.LinkBase,
+		}, email.Events)
+	}
+
+	a := &alerts{dispatcher: dispatcher, threshold: cfg.Notify.FailureStreak, logger: logger}
+	if dispatcher.Enabled() {
+		var path string
+		if persist && cfg.RunsDir != "" {
+			path = filepath.Join(cfg.RunsDir, streaksFile)
+		}
+		streaks, err := notify.OpenStreaks(path)
+		if err != nil {
+			return nil, err
+		}
+		a.streaks = streaks
+		// Без каналов уведомлений изменения некому сообщать
+		if path := changesPath(cfg); path != "" {
+			a.changes = &changeFile{path: path}
+		}
+	}
+	return a, nil
+}
+
+// changesPath выбирает файл последних записей задач: --changes или файл
+// в каталоге запусков. Пустой путь - изменения не отслеживаются.
+func changesPath(cfg *appconfig.AppConfig) string {
+	if cfg.Notify.ChangesPath != "" {
+		return cfg.Notify.ChangesPath
+	}
+	if cfg.RunsDir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.RunsDir, changesFile)
+}
+
+// changeFile открывает файл последних записей задач только на время
+// сравнения, чтобы его могли делить несколько процессов. Одновременные
+// сравнения в одном процессе, например в запусках serve, делят одно
+// открытое хранилище: bbolt не открывает файл второй раз.
+type changeFile struct {
+	path  string
+	mu    sync.Mutex
+	store *changes.Store
+	users int
+}
+
+// use вызывает fn с открытым хранилищем и закрывает его за последним
+// пользователем.
+func (f *changeFile) use(fn func(store *changes.Store) error) error {
+	f.mu.Lock()
+	if f.store == nil {
+		store, err := changes.Open(f.path)
+		if err != nil {
+			f.mu.Unlock()
+			return err
+		}
+		f.store = store
+	}
+	f.users++
+	store := f.store
+	f.mu.Unlock()
+
+	err := fn(store)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.users--; f.users == 0 {
+		if closeErr := f.store.Close(); err == nil {
+			err = closeErr
+		}
+		f.store = nil
+	}
+	return err
+}
+
+// taskDone учитывает завершение задачи. Если она упала threshold раз подряд,
+// ее селекторы впервые ничего не нашли или изменились ее записи,
+// отправляет событие.
+func (a *alerts) taskDone(runID string, task taskconfig.Task, records []map[string]string, err error) {
+	if err == nil {
+		a.compare(runID, task, records)
+		a.evaluate(runID, task, records)
+	}
+	if a.streaks == nil {
+		return
+	}
+	if err != nil {
+		n := a.streaks.Observe(task.Name, true)
+		if n != a.threshold {
+			return
+		}
+		a.logger.Warn("🚨 Task keeps failing", "task id:", task.ID, "task:", task.Name, "failures in a row:", n)
+		a.send(notify.NewTaskEvent(notify.EventTaskFailing, runID, notify.Task{
+			ID: task.ID, Name: task.Name, URL: task.URL, ConsecutiveFailures: n, Error: err.Error(),
+		}))
+		return
+	}
+	a.streaks.Observe(task.Name, false)
+
+	// О поломке сообщается один раз, пока поля снова не найдутся
+	empty := emptyFields(task, records)
+	if a.streaks.Observe(brokenPrefix+task.Name, len(empty) > 0) != 1 {
+		return
+	}
+	a.logger.Warn("⚠️ Task selectors found nothing", "task id:", task.ID, "task:", task.Name, "fields:", empty)
+	a.send(notify.NewTaskEvent(notify.EventSelectorsBroken, runID, notify.Task{
+		ID: task.ID, Name: task.Name, URL: task.URL, EmptyFields: empty,
+	}))
+}
+
+// compare сравнивает записи задачи с прошлым выполнением и сообщает
+// об изменениях полей из task.Watch. Задачи без Watch не сравниваются.
+func (a *alerts) compare(runID string, task taskconfig.Task, records []map[string]string) {
+	if a.changes == nil || len(task.Watch) == 0 {
+		return
+	}
+	var (
+		found []diff.Change
+		first bool
+	)
+	err := a.changes.use(func(store *changes.Store) error {
+		var err error
+		found, first, err = store.Compare(task, records)
+		return err
+	})
+	if err != nil {
+		a.logger.Warn("⭕ Failed to compare records", "task id:", task.ID, "task:", task.Name, "error:", err)
+		return
+	}
+	if first || len(found) == 0 {
+		return
+	}
+	a.logger.Info("🔔 Task changed", "task id:", task.ID, "task:", task.Name, "records:", len(found))
+	event := notify.Task{ID: task.ID, Name: task.Name, URL: task.URL}
+	for _, c := range found {
+		change := notify.Change{Kind: c.Kind.String(), Key: c.Key}
+		for _, f := range c.Fields {
+			change.Fields = append(change.Fields, notify.FieldChange{Name: f.Name, Old: f.Old, New: f.New})
+		}
+		event.Changes = append(event.Changes, change)
+	}
+	a.send(notify.NewTaskEvent(notify.EventChanged, runID, event))
+}
+
+// alertFields - поля, которые попадают в событие alert вместе с полями
+// условия, чтобы по записи было понятно, о чем она.
+var alertFields = []string{"Title", "Name", "Link", "URL"}
+
+// evaluate проверяет записи задачи условиями task.Alerts и сообщает
+// о записях, которые начали им удовлетворять. Без файла последних записей
+// сообщается о каждой подходящей записи при каждом выполнении.
+func (a *alerts) evaluate(runID string, task taskconfig.Task, records []map[string]string) {
+	if len(task.Alerts) == 0 {
+		return
+	}
+	keys := diff.Keys(records)
+	for _, expr := range task.Alerts {
+		rule, err := rules.Parse(expr)
+		if err != nil {
+			continue // проверено при загрузке задач
+		}
+		var matched []string
+		byKey := make(map[string]map[string]string)
+		for i, record := range records {
+			if rule.Match(record) {
+				matched = append(matched, keys[i])
+				byKey[keys[i]] = record
+			}
+		}
+		fresh := matched
+		if a.changes != nil {
+			err := a.changes.use(func(store *changes.Store) error {
+				var err error
+				fresh, err = store.Matches(task, rule.String(), matched)
+				return err
+			})
+			if err != nil {
+				a.logger.Warn("⭕ Failed to check alert", "task id:", task.ID, "rule:", rule, "error:", err)
+				continue
+			}
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+
+		fields := append(rule.Fields(), alertFields...)
+		event := notify.Task{ID: task.ID, Name: task.Name, URL: task.URL, Rule: rule.String()}
+		for _, key := range fresh {
+			match := make(map[string]string)
+			for _, field := range fields {
+				if value, ok := byKey[key][field]; ok {
+					match[field] = value
+				}
+			}
+			event.Matches = append(event.Matches, match)
+		}
+		a.logger.Info("🎯 Alert rule matched", "task id:", task.ID, "task:", task.Name, "rule:", rule, "records:", len(fresh))
+		a.send(notify.NewTaskEvent(notify.EventAlert, runID, event))
+	}
+}
+
+// emptyFields возвращает поля задачи, которые пусты во всех записях.
+// Поля сценариев из шагов не проверяются.
+func emptyFields(task taskconfig.Task, records []map[string]string) []string {
+	if len(task.Steps) > 0 {
+		return nil
+	}
+	keys := slices.Concat(slices.Collect(maps.Keys(task.Selectors)), slices.Collect(maps.Keys(task.Structured)))
+	var empty []string
+	for _, key := range keys {
+		if !slices.ContainsFunc(records, func(record map[string]string) bool { return record[key] != "" }) {
+			empty = append(empty, key)
+		}
+	}
+	slices.Sort(empty)
+	return empty
+}
+
+// runDone отправляет событие завершения запуска, не дожидаясь доставки.
+func (a *alerts) runDone(runID string, summary notify.Summary) {
+	a.send(notify.NewRunEvent(runID, summary))
+}
+
+// send доставляет событие в фоне, close дожидается доставки.
+func (a *alerts) send(e notify.Event) {
+	if !a.dispatcher.Enabled() {
+		return
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		a.dispatcher.Send(ctx, e)
+	}()
+}
+
+// close ждет отправки событий и сохраняет счетчики неудач.
+func (a *alerts) close() {
+	a.wg.Wait()
+	if a.streaks == nil {
+		return
+	}
+	if err := a.streaks.Save(); err != nil {
+		a.logger.Warn("⭕ Failed to save failure streaks", "error:", err)
+	}
+}
+
+// notification возвращает итоги запуска для уведомления.
+func (s *runSummary) notification() notify.Summary {
+	n := notify.Summary{
+		Total:       s.Total,
+		Succeeded:   s.Succeeded,
+		Failed:      s.Failed,
+		Skipped:     s.Skipped,
+		Interrupted: s.Interrupted,
+		Records:     s.Records,
+		Duration:    time.Duration(s.Duration).Round(time.Millisecond).String(),
+		Output:      s.Output,
+	}
+	for _, f := range s.Failures {
+		n.Failures = append(n.Failures, notify.Failure(f))
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/export"
+)
+
+// printPlan выводит план запуска для --dry-run: число задач по движкам
+// и хостам, куда попадут результаты, ожидаемую параллельность и сами задачи.
+func printPlan(w io.Writer, cfg *appconfig.AppConfig, tasks, disabled []taskconfig.Task) error {
+	engines := make(map[string]int)
+	hosts := make(map[string]int)
+	for _, task := range tasks {
+		engines[task.EngineName()]++
+		hosts[taskHost(task)]++
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if cfg.File != "" {
+		settings := cfg.File
+		if cfg.Profile != "" {
+			settings += " (profile " + cfg.Profile + ")"
+		}
+		fmt.Fprintf(tw, "Settings:\t%s\n", settings)
+	}
+	fmt.Fprintf(tw, "Tasks:\t%d (%d disabled)\n", len(tasks), len(disabled))
+	fmt.Fprintf(tw, "Engines:\t%s\n", formatCounts(engines))
+	fmt.Fprintf(tw, "Hosts:\t%s\n", formatCounts(hosts))
+	fmt.Fprintf(tw, "Destination:\t%s\n", destination(cfg))
+	fmt.Fprintf(tw, "Concurrency:\t%s\n", concurrency(cfg, hosts))
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "ID\tENGINE\tNAME\tURL")
+	for _, task := range tasks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", task.ID, task.EngineName(), task.Name, task.URL)
+	}
+	return tw.Flush()
+}
+
+// destination описывает, куда попадут результаты запуска.
+func destination(cfg *appconfig.AppConfig) string {
+	switch {
+	case cfg.Produce && cfg.Collect:
+		// Координатор выгружает итоги исполнителей сам
+		if cfg.Output.Path == "" {
+			return "queue " + cfg.QueueURL
+		}
+		return fmt.Sprintf("queue %s, collected to %s", cfg.QueueURL, cfg.Output.Path)
+	case cfg.Produce:
+		return "queue " + cfg.QueueURL
+	case cfg.Output.Path == "":
+		return "none"
+	}
+	format := cfg.Output.Format
+	if format == "" {
+		format = export.FormatFor(cfg.Output.Path)
+	}
+	return fmt.Sprintf("%s (%s)", cfg.Output.Path, format)
+}
+
+// concurrency оценивает, сколько задач будет выполняться одновременно
+// с учетом числа воркеров и лимита на хост.
+func concurrency(cfg *appconfig.AppConfig, hosts map[string]int) string {
+	total := 0
+	for _, n := range hosts {
+		if cfg.PerHost > 0 && n > cfg.PerHost {
+			n = cfg.PerHost
+		}
+		total += n
+	}
+	if total > cfg.Workers {
+		total = cfg.Workers
+	}
+
+	parts := []string{fmt.Sprintf("up to %d of %d workers", total, cfg.Workers)}
+	if cfg.PerHost > 0 {
+		parts = append(parts, fmt.Sprintf("%d per host", cfg.PerHost))
+	}
+	if cfg.Rate > 0 {
+		parts = append(parts, fmt.Sprintf("%g tasks/s", cfg.Rate))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatCounts выводит счетчики вида "a=2, b=1" по убыванию.
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, counts[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func taskHost(task taskconfig.Task) string {
+	u, err := url.Parse(task.URL)
+	if err != nil || u.Host == "" {
+		return task.URL
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/queue"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+const (
+	// queueRetryDelay - пауза после ошибки очереди перед новой попыткой.
+	queueRetryDelay = time.Second
+	// settleTimeout ограничивает подтверждение задачи в очереди.
+	settleTimeout = 10 * time.Second
+)
+
+// checkQueue проверяет сочетание флагов очереди, не подключаясь к ней.
+func checkQueue(cfg *appconfig.AppConfig) error {
+	if cfg.QueueURL == "" {
+		if cfg.Produce || cfg.Consume || cfg.Collect || cfg.Reply {
+			return errors.New("--produce, --consume, --collect and --reply require a queue set with --queue")
+		}
+		return nil
+	}
+	// Очередь в памяти живет в одном процессе, а производитель
+	// и исполнители - разные процессы
+	if strings.HasPrefix(cfg.QueueURL, "memory:") {
+		return errors.New("--queue memory:// is not shared between processes, use redis:// or sqs://")
+	}
+	if cfg.Produce && cfg.Consume {
+		return errors.New("--produce and --consume cannot be used together")
+	}
+	if cfg.Collect && !cfg.Produce {
+		return errors.New("--collect requires --produce")
+	}
+	if cfg.Reply && !cfg.Consume {
+		return errors.New("--reply requires --consume")
+	}
+	if cfg.Consume && cfg.StatePath != "" {
+		return errors.New("--state cannot be used with --consume, the queue keeps the run state")
+	}
+	if cfg.Consume && cfg.FreshFor > 0 {
+		return errors.New("--fresh-for cannot be used with --consume, tasks taken from the queue are always run")
+	}
+	return nil
+}
+
+// openQueue подключается к очереди из --queue. Без --queue возвращает nil.
+// Флаги очереди проверяет checkQueue.
+func openQueue(ctx context.Context, cfg *appconfig.AppConfig) (queue.Queue, error) {
+	if cfg.QueueURL == "" {
+		return nil, nil
+	}
+	return queue.Open(ctx, cfg.QueueURL)
+}
+
+// produce кладет задачи в очередь. Секреты в очередь не попадают:
+// задачи кладутся со ссылками на них, которые раскрывает исполнитель.
+func produce(ctx context.Context, q queue.Queue, tasks []taskconfig.Task, logger *slog.Logger) error {
+	for _, task := range tasks {
+		if err := q.Push(ctx, task.Unresolved()); err != nil {
+			return err
+		}
+		logger.Debug("Task queued", "task id:", task.ID, "url:", task.URL)
+	}
+	logger.Info("📤 Tasks queued", "count:", len(tasks))
+	return nil
+}
+
+// consume берет задачи из очереди и добавляет их в пул, пока не отменен ctx,
+// после чего закрывает пул. Задачи, которые не удалось добавить, возвращаются в очередь.
+// Задачи, ссылки которых не раскрываются на этом исполнителе, удаляются
+// из очереди, а их итог с ошибкой уходит в replies, если он задан.
+func consume(ctx context.Context, q queue.Queue, replies queue.Results, pool *workerpool.Pool[[]map[string]string], entries *runEntries,
+	newTask func(taskconfig.Task) workerpool.Task[[]map[string]string], logger *slog.Logger) {
+	defer pool.Close()
+
+	for {
+		d, err := q.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, queue.ErrClosed) {
+				return
+			}
+			logger.Error("Failed to take task from queue", "error:", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(queueRetryDelay):
+			}
+			continue
+		}
+
+		// Ссылки на секреты раскрываются окружением исполнителя. Недостающий
+		// секрет сам не появится, поэтому задача не возвращается в очередь.
+		task, err := taskconfig.Resolve(d.Task)
+		if err != nil {
+			logger.Error("⭕ Dropping queued task with unresolved references", "task id:", d.Task.ID, "url:", d.Task.URL, "error:", err)
+			done := replies == nil || reply(replies, queue.Result{TaskID: d.Task.ID, URL: d.Task.URL, Error: err.Error()}, logger)
+			settle(d, done, logger)
+			continue
+		}
+		// Задачи-зависимости могут выполнять другие экземпляры, дождаться их здесь нельзя.
+		if len(task.DependsOn) > 0 {
+			logger.Warn("Ignoring dependencies of a queued task", "task id:", task.ID, "depends on:", task.DependsOn)
+			task.DependsOn = nil
+		}
+
+		_, err = entries.add(func() (int, error) {
+			return pool.AddTask(ctx, newTask(task))
+		}, runEntry{task: task, delivery: d})
+		if err != nil {
+			logger.Error("Failed to add task", "task id:", task.ID, "url:", task.URL, "error:", err)
+			settle(d, false, logger)
+		}
+	}
+}
+
+// settle подтверждает задачу в очереди или возвращает ее обратно.
+func settle(d *queue.Delivery, done bool, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	var err error
+	if done {
+		err = d.Ack(ctx)
+	} else {
+		err = d.Nack(ctx)
+	}
+	if err != nil {
+		logger.Warn("⭕ Failed to settle queued task", "task id:", d.Task.ID, "done:", done, "error:", err)
+	}
+}
+
+// maxRedeliveries - сколько раз исполнитель возвращает упавшую задачу
+// в очередь. Потом задача удаляется, чтобы задача, которая падает всегда,
+// не ходила по кругу.
+const maxRedeliveries = 3
+
+// redeliveries считает упавшие задачи из очереди, которые исполнитель
+// вернул в очередь.
+type redeliveries map[string]int
+
+func newRedeliveries() redeliveries {
+	return make(redeliveries)
+}
+
+// retry сообщает, что упавшую задачу нужно вернуть в очередь.
+func (r redeliveries) retry(task taskconfig.Task, logger *slog.Logger) bool {
+	key := task.ID + "\x00" + task.URL
+	r[key]++
+	if r[key] > maxRedeliveries {
+		logger.Warn("⭕ Dropping queued task after repeated failures", "task id:", task.ID, "url:", task.URL, "failures:", r[key])
+		delete(r, key)
+		return false
+	}
+	return true
+}
+
+// reply отправляет итог задачи из очереди координатору и сообщает,
+// удалось ли это.
+func reply(results queue.Results, res queue.Result, logger *slog.Logger) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer cancel()
+
+	res.Worker = workerName
+	if err := results.Publish(ctx, res); err != nil {
+		logger.Warn("⭕ Failed to send task result", "task id:", res.TaskID, "error:", err)
+		return false
+	}
+	return true
+}
+
+// workerName называет исполнитель в итогах задач: хост и процесс.
+var workerName = func() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}()
+
+// errorText возвращает текст ошибки, пустой для nil.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// runEntries связывает номера задач в пуле с задачами конфига. Безопасна
+// для одновременного использования: в режиме --consume задачи добавляются
+// во время работы пула.
+type runEntries struct {
+	mu sync.RWMutex
+	m  map[int]runEntry
+}
+
+func newRunEntries() *runEntries {
+	return &runEntries{m: make(map[int]runEntry)}
+}
+
+// add добавляет задачу в пул и запоминает ее под выданным номером.
+// Блокировка держится на время добавления, чтобы результат задачи
+// не обогнал запись о ней.
+func (e *runEntries) add(add func() (int, error), entry runEntry) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	id, err := add()
+	if err != nil {
+		return 0, err
+	}
+	e.m[id] = entry
+	return id, nil
+}
+
+// get возвращает задачу по номеру в пуле.
+func (e *runEntries) get(id int) runEntry {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.m[id]
+}
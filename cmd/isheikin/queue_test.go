@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/queue"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+func TestCheckQueue(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  appconfig.AppConfig
+		want string
+	}{
+		{"no queue", appconfig.AppConfig{}, ""},
+		{"consume without queue", appconfig.AppConfig{Consume: true}, "require a queue"},
+		{"memory queue", appconfig.AppConfig{QueueURL: "memory://", Consume: true}, "not shared between processes"},
+		{"produce and consume", appconfig.AppConfig{QueueURL: "redis://localhost", Produce: true, Consume: true}, "cannot be used together"},
+		{"reply without consume", appconfig.AppConfig{QueueURL: "redis://localhost", Reply: true}, "--reply requires --consume"},
+		{"consumer", appconfig.AppConfig{QueueURL: "redis://localhost", Consume: true, Reply: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkQueue(&tt.cfg)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("checkQueue = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("checkQueue = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// replyStub собирает итоги, отправленные исполнителем.
+type replyStub struct {
+	mu      sync.Mutex
+	results []queue.Result
+}
+
+func (r *replyStub) Publish(_ context.Context, res queue.Result) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, res)
+	return nil
+}
+
+func (r *replyStub) Next(ctx context.Context) (queue.Result, error) {
+	<-ctx.Done()
+	return queue.Result{}, ctx.Err()
+}
+
+func (r *replyStub) Close() error { return nil }
+
+// urlTask возвращает запись с URL задачи.
+type urlTask struct {
+	task taskconfig.Task
+}
+
+func (t urlTask) Name() string  { return t.task.Name }
+func (t urlTask) OnError(error) {}
+func (t urlTask) Execute(context.Context) ([]map[string]string, error) {
+	return []map[string]string{{"URL": t.task.URL}}, nil
+}
+
+func TestConsumeDropsUnresolvedTasks(t *testing.T) {
+	t.Setenv("ISH3IKIN_TEST_HOST", "example.com")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	q := queue.NewMemory()
+	for _, task := range []taskconfig.Task{
+		{ID: "bad", Name: "bad", URL: "https://${ISH3IKIN_TEST_UNSET}/"},
+		{ID: "good", Name: "good", URL: "https://${ISH3IKIN_TEST_HOST}/"},
+	} {
+		if err := q.Push(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool, err := workerpool.NewPool[[]map[string]string](1, 0, workerpool.WithUnboundedQueue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	replies := &replyStub{}
+	consumeCtx, stopConsume := context.WithCancel(ctx)
+	go consume(consumeCtx, q, replies, pool, newRunEntries(),
+		func(task taskconfig.Task) workerpool.Task[[]map[string]string] { return urlTask{task} }, logger)
+	go pool.Run(ctx)
+
+	select {
+	case res := <-pool.Results():
+		if res.Err != nil || res.Value[0]["URL"] != "https://example.com/" {
+			t.Errorf("pool result = %+v, want the good task with its URL resolved", res)
+		}
+	case <-ctx.Done():
+		t.Fatal("the good task did not run")
+	}
+	stopConsume()
+
+	replies.mu.Lock()
+	defer replies.mu.Unlock()
+	if len(replies.results) != 1 || replies.results[0].TaskID != "bad" || replies.results[0].Error == "" {
+		t.Fatalf("replies = %+v, want one failed result of the bad task", replies.results)
+	}
+	// Задача с нераскрытой ссылкой подтверждена и в очередь не вернулась
+	short, cancelShort := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelShort()
+	if d, err := q.Pop(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pop = %+v, %v, want an empty queue", d, err)
+	}
+}
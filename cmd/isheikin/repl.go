@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// replMatches - сколько совпадений селектора показывает repl.
+	replMatches = 10
+	// replTextWidth - длина, до которой обрезается текст совпадения.
+	replTextWidth = 120
+	// replNavTimeout - время на загрузку страницы, если в настройках не задан NavigationTimeout.
+	replNavTimeout = 30 * time.Second
+)
+
+// newReplCmd создает команду "repl": открывает страницу один раз и выполняет
+// вводимые селекторы, показывая число совпадений и их текст.
+func newReplCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+
+	cmd := &cobra.Command{
+		Use:   "repl <url>",
+		Short: "Try CSS and XPath selectors on a page interactively",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+			return runRepl(cfg, args[0], cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+	cfg.RegisterBrowserFlags(cmd.Flags())
+	return cmd
+}
+
+// runRepl открывает url и читает команды из in до :quit или конца ввода.
+func runRepl(cfg *appconfig.AppConfig, url string, in io.Reader, out io.Writer) error {
+	browser, closeBrowser, err := openBrowser(cfg)
+	defer closeBrowser()
+	if err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	navTimeout := replNavTimeout
+	if cfg.NavigationTimeout > 0 {
+		navTimeout = time.Duration(cfg.NavigationTimeout) * time.Second
+	}
+	if err := openPage(page, url, navTimeout); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Opened %s\nType a CSS or XPath selector, :help for commands.\n", url)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		command, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		switch command {
+		case "":
+		case ":quit", ":q", ":exit":
+			return nil
+		case ":help", ":h":
+			fmt.Fprint(out, replHelp)
+		case ":open":
+			if arg == "" {
+				fmt.Fprintln(out, "usage: :open <url>")
+				continue
+			}
+			if err := openPage(page, arg, navTimeout); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			url = arg
+			fmt.Fprintf(out, "Opened %s\n", url)
+		case ":reload":
+			if err := openPage(page, url, navTimeout); err != nil {
+				fmt.Fprintln(out, err)
+			}
+		default:
+			if strings.HasPrefix(command, ":") {
+				fmt.Fprintf(out, "unknown command %s, see :help\n", command)
+				continue
+			}
+			printMatches(out, page, line)
+		}
+	}
+}
+
+const replHelp = `Selectors:
+  h1.title             CSS selector
+  //a[@href]           XPath, also (//a)[1] and xpath:<expr>
+  css:<selector>       force CSS
+Commands:
+  :open <url>          open another page
+  :reload              reload the current page
+  :quit                exit
+`
+
+// openPage переходит на url и ждет загрузки страницы.
+func openPage(page *rod.Page, url string, timeout time.Duration) error {
+	p := page.Timeout(timeout)
+	if err := p.Navigate(url); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	if err := p.WaitLoad(); err != nil {
+		return fmt.Errorf("failed to load %s: %w", url, err)
+	}
+	return nil
+}
+
+// printMatches выполняет селектор и выводит число совпадений и их текст.
+func printMatches(out io.Writer, page *rod.Page, selector string) {
+	elements, err := queryElements(page, selector)
+	if err != nil {
+		fmt.Fprintf(out, "error: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "%d matches\n", len(elements))
+	for i, element := range elements {
+		if i == replMatches {
+			fmt.Fprintf(out, "  ... and %d more\n", len(elements)-replMatches)
+			break
+		}
+		text, err := element.Text()
+		if err != nil {
+			fmt.Fprintf(out, "  %d: error: %v\n", i+1, err)
+			continue
+		}
+		fmt.Fprintf(out, "  %d: %s\n", i+1, shortText(text))
+	}
+}
+
+// queryElements ищет элементы по CSS или XPath. XPath узнается по началу
+// выражения или по префиксу xpath:.
+func queryElements(page *rod.Page, selector string) (rod.Elements, error) {
+	if css, ok := strings.CutPrefix(selector, "css:"); ok {
+		return page.Elements(strings.TrimSpace(css))
+	}
+	if xpath, ok := strings.CutPrefix(selector, "xpath:"); ok {
+		return page.ElementsX(strings.TrimSpace(xpath))
+	}
+	if strings.HasPrefix(selector, "/") || strings.HasPrefix(selector, "(") || strings.HasPrefix(selector, "./") {
+		return page.ElementsX(selector)
+	}
+	return page.Elements(selector)
+}
+
+// shortText схлопывает пробелы и обрезает текст до replTextWidth символов.
+func shortText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "(empty)"
+	}
+	if utf8.RuneCountInString(text) > replTextWidth {
+		runes := []rune(text)
+		text = string(runes[:replTextWidth-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/state"
+)
+
+// runState - состояние запуска на диске.
+type runState struct {
+	// id - идентификатор запуска, по которому его продолжает --resume.
+	id    string
+	store *state.Store
+	// tasks - задачи, которые нужно выполнить, keys - их ключи в состоянии.
+	tasks []taskconfig.Task
+	keys  []string
+	// done - ключи задач, выполненных в прошлом запуске, doneTasks - сами задачи.
+	done      []string
+	doneTasks []taskconfig.Task
+}
+
+// openState открывает состояние запуска и выбирает задачи, которые нужно
+// выполнить. Состояние хранится в файле --state или в каталоге запусков
+// под идентификатором запуска. При --resume пропускаются задачи, выполненные
+// в прошлом запуске, иначе состояние начинается заново. Без файла
+// состояния и каталога запусков возвращает nil store и все задачи.
+func openState(cfg *appconfig.AppConfig, tasks []taskconfig.Task, logger *slog.Logger) (*runState, error) {
+	id, path, err := statePath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return &runState{tasks: tasks}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create runs directory: %w", err)
+	}
+
+	store, err := state.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Resume == "" {
+		if err := store.Reset(); err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to reset state: %w", err)
+		}
+		if id != "" {
+			pruneRuns(cfg.RunsDir, cfg.KeepRuns, logger)
+		}
+	}
+
+	rs := &runState{
+		id:    id,
+		store: store,
+		tasks: make([]taskconfig.Task, 0, len(tasks)),
+		keys:  make([]string, 0, len(tasks)),
+	}
+	pending := make(map[string]state.TaskInfo, len(tasks))
+	completed := make(map[string]bool)
+	for _, task := range tasks {
+		key := state.TaskKey(task)
+		done, err := store.Done(key)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to read state: %w", err)
+		}
+		if done {
+			completed[task.Name] = true
+			rs.done = append(rs.done, key)
+			rs.doneTasks = append(rs.doneTasks, task)
+			continue
+		}
+		rs.tasks = append(rs.tasks, task)
+		rs.keys = append(rs.keys, key)
+		pending[key] = state.TaskInfo{ID: task.ID, Name: task.Name, URL: task.URL}
+	}
+
+	// Зависимости, выполненные в прошлом запуске, уже удовлетворены.
+	// Ключи посчитаны до этого, поэтому от правки задач не меняются.
+	for i, task := range rs.tasks {
+		var deps []string
+		for _, dep := range task.DependsOn {
+			if !completed[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		rs.tasks[i].DependsOn = deps
+	}
+
+	if err := store.AddPending(pending); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	if len(rs.done) > 0 {
+		logger.Info("⏭️ Resuming run", "run id:", id, "skipped:", len(rs.done), "outstanding:", len(rs.tasks))
+	} else if id != "" {
+		logger.Info("🆔 Run started", "run id:", id, "resume with:", "--resume="+id)
+	}
+	return rs, nil
+}
+
+// statePath выбирает файл состояния запуска. С --state это указанный файл,
+// иначе - файл нового или продолжаемого запуска в каталоге запусков.
+func statePath(cfg *appconfig.AppConfig) (id, path string, err error) {
+	if cfg.Consume {
+		// Состояние запуска хранит очередь
+		if cfg.Resume != "" {
+			return "", "", errors.New("--resume cannot be used with --consume, the queue keeps the run state")
+		}
+		return "", "", nil
+	}
+	if cfg.StatePath != "" {
+		if cfg.Resume != "" && cfg.Resume != appconfig.ResumeLast {
+			return "", "", errors.New("--resume with a run id cannot be used with --state, the state file is the run")
+		}
+		return "", cfg.StatePath, nil
+	}
+	if cfg.RunsDir == "" {
+		if cfg.Resume != "" {
+			return "", "", errors.New("--resume requires a state file set with --state or a runs directory set with --runs-dir")
+		}
+		return "", "", nil
+	}
+
+	switch cfg.Resume {
+	case "":
+		id = newRunID()
+	case appconfig.ResumeLast:
+		if id, err = lastRun(cfg.RunsDir); err != nil {
+			return "", "", err
+		}
+	default:
+		id = cfg.Resume
+		if strings.ContainsAny(id, `/\`) {
+			return "", "", fmt.Errorf("invalid run id %q", id)
+		}
+	}
+
+	path = filepath.Join(cfg.RunsDir, id+stateExt)
+	if cfg.Resume != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", "", fmt.Errorf("run %s not found in %s", id, cfg.RunsDir)
+		}
+	}
+	return id, path, nil
+}
+
+// stateExt - расширение файлов состояния в каталоге запусков.
+const stateExt = ".db"
+
+// newRunID возвращает идентификатор запуска: время начала и случайный суффикс,
+// чтобы одновременные запуски не совпали.
+func newRunID() string {
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// lastRun находит последний запуск в каталоге запусков.
+func lastRun(dir string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+stateExt))
+	if err != nil {
+		return "", err
+	}
+	var (
+		last     string
+		lastTime time.Time
+	)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if last == "" || info.ModTime().After(lastTime) {
+			last, lastTime = file, info.ModTime()
+		}
+	}
+	if last == "" {
+		return "", fmt.Errorf("no runs to resume in %s", dir)
+	}
+	return strings.TrimSuffix(filepath.Base(last), stateExt), nil
+}
+
+// listRuns возвращает запуски из каталога запусков в порядке их начала:
+// идентификатор запуска начинается со времени старта.
+func listRuns(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+stateExt))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]string, 0, len(files))
+	for _, file := range files {
+		runs = append(runs, strings.TrimSuffix(filepath.Base(file), stateExt))
+	}
+	sort.Strings(runs)
+	return runs, nil
+}
+
+// pruneRuns удаляет файлы самых старых запусков, чтобы в каталоге
+// осталось не больше keep запусков, включая только что начатый.
+func pruneRuns(dir string, keep int, logger *slog.Logger) {
+	if keep <= 0 {
+		return
+	}
+	runs, err := listRuns(dir)
+	if err != nil {
+		logger.Warn("⭕ Failed to list runs", "dir:", dir, "error:", err)
+		return
+	}
+	if len(runs) <= keep {
+		return
+	}
+	for _, id := range runs[:len(runs)-keep] {
+		files, _ := filepath.Glob(filepath.Join(dir, id+".*"))
+		for _, file := range files {
+			if err := os.Remove(file); err != nil {
+				logger.Warn("⭕ Failed to remove old run", "run id:", id, "error:", err)
+			}
+		}
+		logger.Debug("Removed old run", "run id:", id)
+	}
+}
+
+// replayRecords выгружает записи задач, выполненных в прошлом запуске,
+// чтобы файл результатов продолженного запуска был полным.
+func replayRecords(rs *runState, export func([]map[string]string) error) (int, error) {
+	count := 0
+	for _, key := range rs.done {
+		records, err := rs.store.Records(key)
+		if err != nil {
+			return count, err
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if err := export(records); err != nil {
+			return count, err
+		}
+		count += len(records)
+	}
+	return count, nil
+}
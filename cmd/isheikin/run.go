@@ -0,0 +1,589 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/export"
+	applog "github.com/rx3lixir/ish3ikin/internal/lib/logger"
+	"github.com/rx3lixir/ish3ikin/internal/queue"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+	"github.com/rx3lixir/ish3ikin/internal/tui"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+	"github.com/spf13/cobra"
+)
+
+const progressInterval = 5 * time.Second
+
+// newRunCmd создает команду "run", которая выполняет задачи из конфига.
+func newRunCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Scrape the tasks from the config",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+			if cfg.Watch {
+				return watchTasks(cfg)
+			}
+			return runTasks(cfg)
+		},
+	}
+	cfg.RegisterFlags(cmd.Flags())
+	registerTaskCompletion(cmd, "only", "skip")
+	return cmd
+}
+
+// runTasks выполняет задачи запуска по настройкам cfg.
+func runTasks(cfg *appconfig.AppConfig) error {
+	// Инициализация логгера
+	logger, logOut, err := newRunLogger(cfg)
+	if err != nil {
+		return usageError{err}
+	}
+	defer logOut.Close()
+	logOut.SetConsole(console(cfg))
+
+	summary := newRunSummary()
+	manifest := newRunManifest(cfg)
+
+	// Создаем контекст. Потребитель очереди и координатор работают, пока
+	// их не остановят сигналом, поэтому общего лимита времени у них нет.
+	ctx, cancel := context.WithCancel(context.Background())
+	if !cfg.Consume && !cfg.Collect {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(time.Second*time.Duration(cfg.Timeout)))
+	}
+	defer cancel()
+
+	if err := checkQueue(cfg); err != nil {
+		return fmt.Errorf("failed to open task queue: %w", err)
+	}
+
+	// Загружаем задачи. Потребитель очереди берет их из очереди.
+	var tasks, disabled []taskconfig.Task
+	if !cfg.Consume {
+		if err := setupFetcher(cfg.ConfigHeader, cfg.ConfigCache, cfg.ConfigPath); err != nil {
+			return configError{fmt.Errorf("failed to load tasks: %w", err)}
+		}
+		tasks, err = loadTasks(cfg.ConfigPath)
+		if err != nil {
+			return configError{fmt.Errorf("failed to load tasks: %w", err)}
+		}
+		manifest.ConfigHash = hashJSON(tasks)
+
+		if tasks, disabled, err = selectTasks(cfg, tasks, logger); err != nil {
+			return err
+		}
+	}
+
+	// План запуска без браузера и без записи состояния
+	if cfg.DryRun {
+		if cfg.Consume {
+			return errors.New("--dry-run cannot be used with --consume")
+		}
+		if err := printPlan(os.Stdout, cfg, tasks, disabled); err != nil {
+			return fmt.Errorf("failed to print plan: %w", err)
+		}
+		return nil
+	}
+
+	// Общая очередь задач для запуска несколькими экземплярами
+	q, err := openQueue(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open task queue: %w", err)
+	}
+	if q != nil {
+		defer q.Close()
+	}
+	// Итоги задач из очереди, которые собирает координатор
+	var replies queue.Results
+	if cfg.Collect || cfg.Reply {
+		if replies, err = queue.OpenResults(cfg.QueueURL, logger); err != nil {
+			return fmt.Errorf("failed to open task results: %w", err)
+		}
+		defer replies.Close()
+	}
+
+	if cfg.Produce {
+		if cfg.Collect {
+			return coordinate(ctx, cfg, q, replies, tasks, disabled, summary, manifest, logger)
+		}
+		if err := produce(ctx, q, tasks, logger); err != nil {
+			return fmt.Errorf("failed to queue tasks: %w", err)
+		}
+		return nil
+	}
+
+	// Состояние запуска для продолжения после сбоя
+	rs, err := openState(cfg, tasks, logger)
+	if err != nil {
+		return fmt.Errorf("failed to open run state: %w", err)
+	}
+
+	// Задачи, URL которых выполнялись недавно, пропускаются
+	seenURLs, err := openSeen(cfg, logger)
+	if err != nil {
+		if rs.store != nil {
+			rs.store.Close()
+		}
+		return fmt.Errorf("failed to open seen URLs: %w", err)
+	}
+	var fresh []taskconfig.Task
+	if seenURLs != nil {
+		defer seenURLs.Close()
+		rs.tasks, rs.keys, fresh = seenURLs.skip(ctx, rs.tasks, rs.keys)
+	}
+	store, tasks := rs.store, rs.tasks
+	if store != nil {
+		defer store.Close()
+	}
+	manifest.RunID = rs.id
+
+	// Уведомления о завершении запуска и задачах, которые падают подряд
+	alerts, err := newAlerts(cfg, true, logger)
+	if err != nil {
+		return configError{err}
+	}
+
+	// Файл результатов
+	var exporter export.Exporter
+	if cfg.Output.Path != "" {
+		if exporter, err = export.Open(cfg.Output.Path, cfg.Output.Format); err != nil {
+			return err
+		}
+		// Продолженный запуск выгружает и записи прошлого запуска
+		n, err := replayRecords(rs, exporter.Export)
+		if err != nil {
+			exporter.Close()
+			return fmt.Errorf("failed to export records of the resumed run: %w", err)
+		}
+		if n > 0 {
+			logger.Info("📼 Exported records of the resumed run", "records:", n)
+		}
+		summary.Records += n
+	}
+	if len(tasks) == 0 && !cfg.Consume {
+		if len(fresh) > 0 {
+			logger.Info("Nothing to do: all tasks are completed or fresh", "fresh:", len(fresh))
+		} else {
+			logger.Info("Nothing to do: all tasks are completed")
+		}
+		if exporter != nil {
+			return exporter.Close()
+		}
+		return nil
+	}
+
+	// Живая панель прогресса вместо логов, если вывод - терминал,
+	// а логи не пишутся в JSON для сборщика логов. Файл лога
+	// продолжает получать все записи.
+	// SIGINT и SIGTERM, как и выход из панели, останавливают запуск
+	// без потери собранных результатов.
+	stop := newShutdown(time.Duration(cfg.GracePeriod)*time.Second, logger)
+	var dash *tui.Dashboard
+	if !cfg.NoTUI && cfg.Log.Format != applog.FormatJSON && cfg.Output.Path != export.Stdout && isatty.IsTerminal(os.Stdout.Fd()) {
+		dash = tui.New(stop.interrupt)
+		dash.Start()
+		logOut.SetConsole(dash.Writer())
+		defer stopDashboard(dash, logOut)
+	}
+
+	// Создаем инстанс браузера
+	browser, closeBrowser, err := openBrowser(cfg)
+	defer closeBrowser()
+	if err != nil {
+		logger.Error("Error connecting to browser", "error:", err)
+	}
+
+	// Создаем скраперы движков
+	scraper, err := newEngines(cfg, browser, logger)
+	if err != nil {
+		return err
+	}
+
+	// Инициализируем воркерпул
+	// По номеру из пула находим задачу конфига для логов и состояния.
+	entries := newRunEntries()
+	taskID := func(id int) string { return entries.get(id).task.ID }
+
+	poolOpts := []workerpool.Option{
+		workerpool.WithTaskTimeout(time.Duration(cfg.TaskTimeout) * time.Second),
+		workerpool.WithRetries(cfg.Retries),
+		workerpool.WithScaling(1, cfg.Workers),
+		workerpool.WithRateLimit(cfg.Rate, 1),
+		workerpool.WithKeyLimit(cfg.PerHost),
+		workerpool.WithHooks(dashboardHooks(poolHooks(logger, taskID), dash, entries)),
+	}
+	if cfg.Dedup {
+		poolOpts = append(poolOpts, workerpool.WithDedup())
+	}
+	if cfg.Fair {
+		poolOpts = append(poolOpts, workerpool.WithFairScheduling())
+	}
+	if cfg.Consume {
+		poolOpts = append(poolOpts, workerpool.WithUnboundedQueue())
+	}
+	pool, err := workerpool.NewPool[[]map[string]string](cfg.Workers, len(tasks), poolOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create worker pool: %w", err)
+	}
+
+	// Лимиты, заданные при запуске, перекрывают значения из задач
+	if cfg.ForceTaskTimeout || cfg.ForceRetries {
+		logger.Info("⚙️ Overriding task limits", "task timeout:", time.Duration(cfg.TaskTimeout)*time.Second, "retries:", cfg.Retries)
+	}
+	newTask := func(task taskconfig.Task) workerpool.Task[[]map[string]string] {
+		return scrp.NewScraperTask(cfg.Override(task), scraper, logger)
+	}
+
+	// После сигнала новые задачи не добавляются
+	intake, stopIntake := context.WithCancel(ctx)
+	defer stopIntake()
+	if cfg.Consume {
+		// Берем задачи из очереди, пока не истечет время запуска.
+		go consume(intake, q, replies, pool, entries, newTask, logger)
+	} else {
+		// Добавляем задачи. Очередь вмещает все задачи, поэтому AddTask не блокируется.
+		for i, task := range tasks {
+			entry := runEntry{task: task}
+			if store != nil {
+				entry.stateKey = rs.keys[i]
+			}
+			_, err := entries.add(func() (int, error) {
+				return pool.AddTask(ctx, newTask(task))
+			}, entry)
+			if err != nil {
+				logger.Error("Failed to add task", "task id:", task.ID, "url:", task.URL, "error:", err)
+			}
+		}
+		pool.Close()
+	}
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- pool.Run(ctx)
+	}()
+	stop.watch(pool, stopIntake)
+	defer stop.release()
+
+	// Периодически сообщаем о прогрессе
+	if dash != nil {
+		dash.Watch(func() tui.Stats {
+			pr := pool.Progress()
+			return tui.Stats{
+				Queued:  pr.Queued,
+				Running: pr.Running,
+				Done:    pr.Done,
+				Failed:  pr.Failed,
+				Retried: int(pool.Metrics().Retried),
+				Elapsed: pr.Elapsed,
+				ETA:     pr.ETA,
+			}
+		})
+	} else {
+		go reportProgress(ctx, pool, logger)
+	}
+
+	// Выводим результаты
+	redelivery := newRedeliveries()
+	for res := range pool.Results() {
+		entry := entries.get(res.TaskID)
+		if dash != nil {
+			dash.TaskFinished(entry.task.ID, len(res.Value), res.Duration, res.Err)
+		}
+		if entry.delivery != nil {
+			// Упавшая задача возвращается в очередь для другого исполнителя
+			done := res.Err == nil || !redelivery.retry(entry.task, logger)
+			if replies != nil {
+				// Итог, и упавший тоже, получает координатор. Неотправленный итог
+				// не потерян: задачу выполнит другой исполнитель.
+				done = reply(replies, queue.Result{
+					TaskID:   entry.delivery.Task.ID,
+					URL:      entry.delivery.Task.URL,
+					Records:  res.Value,
+					Error:    errorText(res.Err),
+					Attempts: res.Attempts,
+					Duration: res.Duration,
+				}, logger)
+			}
+			settle(entry.delivery, done, logger)
+		}
+		summary.observe(res.Duration, len(res.Value), res.Err)
+		alerts.taskDone(rs.id, entry.task, res.Value, res.Err)
+		if res.Err != nil {
+			manifest.task(entry.task, taskFailed, 0, res.Attempts, res.Duration, res.Err)
+			logger.Error("Task failed", "task id:", entry.task.ID, "task:", res.Name, "attempts:", res.Attempts, "duration:", res.Duration, "error:", res.Err)
+			continue
+		}
+		logger.Info("Got results", "task id:", entry.task.ID, "task:", res.Name, "duration:", res.Duration, "records:", len(res.Value))
+		manifest.task(entry.task, taskSucceeded, len(res.Value), res.Attempts, res.Duration, nil)
+		if exporter != nil {
+			if err := exporter.Export(res.Value); err != nil {
+				logger.Warn("⭕ Failed to write results", "task id:", entry.task.ID, "error:", err)
+			}
+		}
+		if store != nil {
+			if err := store.MarkDone(entry.stateKey, res.Value); err != nil {
+				logger.Warn("⭕ Failed to save run state", "task:", res.Name, "error:", err)
+			}
+		}
+		if seenURLs != nil {
+			seenURLs.mark(ctx, entry.task)
+		}
+	}
+	err = <-runErr
+	// Итоги выводим обычным логом
+	if dash != nil {
+		stopDashboard(dash, logOut)
+	}
+
+	if err != nil {
+		logger.Warn("Run interrupted", "error:", err)
+	} else if stop.received() {
+		logger.Warn("Run stopped by signal")
+	}
+	for _, t := range pool.Abandoned() {
+		entry := entries.get(t.TaskID)
+		logger.Warn("⭕ Abandoned task", "task id:", entry.task.ID, "task:", t.Name)
+		summary.interrupt(entry.task.ID, entry.task.URL)
+		manifest.task(entry.task, taskAbandoned, 0, 0, 0, nil)
+		if entry.delivery != nil {
+			settle(entry.delivery, false, logger)
+		}
+	}
+	if stop.received() && rs.id != "" {
+		logger.Info("⏯️ Continue the run with", "resume with:", "--resume="+rs.id)
+	}
+
+	for _, f := range pool.Failures() {
+		e := entries.get(f.TaskID)
+		summary.fail(e.task.ID, e.task.URL, f.Err)
+	}
+	duplicates := pool.Duplicates()
+	if len(duplicates) > 0 {
+		logger.Info("Skipped duplicate tasks", "count:", len(duplicates))
+		for _, d := range duplicates {
+			entry := entries.get(d.TaskID)
+			logger.Info("🔁 Duplicate task", "task id:", entry.task.ID, "url:", d.Key, "same as:", entries.get(d.FirstID).task.ID)
+			manifest.task(entry.task, taskDuplicate, 0, 0, 0, nil)
+			// Дубликат не выполняется и не дает результата, подтверждаем его здесь.
+			if entry.delivery != nil {
+				done := replies == nil || reply(replies, queue.Result{TaskID: entry.delivery.Task.ID, URL: entry.delivery.Task.URL, Duplicate: true}, logger)
+				settle(entry.delivery, done, logger)
+			}
+		}
+	}
+
+	if len(disabled) > 0 {
+		logger.Info("Skipped disabled tasks", "count:", len(disabled))
+		for _, t := range disabled {
+			logger.Info("⏸️ Disabled task", "task id:", t.ID, "task:", t.Name, "url:", t.URL)
+			manifest.task(t, taskDisabled, 0, 0, 0, nil)
+		}
+	}
+	if len(fresh) > 0 {
+		logger.Info("Skipped fresh tasks", "count:", len(fresh), "fresh for:", time.Duration(cfg.FreshFor)*time.Second)
+		for _, t := range fresh {
+			logger.Info("🕒 Fresh task", "task id:", t.ID, "task:", t.Name, "url:", t.URL)
+			manifest.task(t, taskFresh, 0, 0, 0, nil)
+		}
+	}
+
+	metrics := pool.Metrics()
+	logger.Info("📊 Pool metrics", "started:", metrics.Started, "succeeded:", metrics.Succeeded, "failed:", metrics.Failed, "retried:", metrics.Retried,
+		"avg wait:", metrics.QueueWait.Mean().Round(time.Millisecond), "avg execution:", metrics.Execution.Mean().Round(time.Millisecond))
+	if cfg.MetricsPath != "" {
+		if err := writeMetrics(cfg.MetricsPath, metrics); err != nil {
+			logger.Warn("⭕ Failed to write metrics", "path:", cfg.MetricsPath, "error:", err)
+		}
+	}
+	var output string
+	if exporter != nil {
+		if err := exporter.Close(); err != nil {
+			logger.Error("Failed to write results", "path:", cfg.Output.Path, "error:", err)
+		} else {
+			logger.Info("💾 Results saved", "path:", cfg.Output.Path)
+			output = cfg.Output.Path
+		}
+	}
+
+	summary.finish(len(disabled)+len(duplicates)+len(fresh)+len(rs.done), output)
+	reportSummary(cfg, summary, logger)
+	if path := manifestPath(cfg, rs.id); path != "" {
+		if err := writeManifest(path, manifest, rs, output, cfg.Output.Format, summary.Records); err != nil {
+			logger.Warn("⭕ Failed to write run manifest", "path:", path, "error:", err)
+		} else {
+			logger.Info("🧾 Run manifest saved", "path:", path)
+		}
+	}
+	if cfg.Diff {
+		if err := printRunDiff(cfg, rs, logger); err != nil {
+			logger.Warn("⭕ Failed to compare with the previous run", "error:", err)
+		}
+	}
+	alerts.runDone(rs.id, summary.notification())
+	alerts.close()
+	return runOutcome(cfg, summary, logger)
+}
+
+// reportSummary выводит итоги запуска и пишет их в файл --summary.
+func reportSummary(cfg *appconfig.AppConfig, summary *runSummary, logger *slog.Logger) {
+	// Таблица итогов сломала бы JSON-логи, поэтому там итоги пишутся записью лога.
+	if cfg.Log.Format == applog.FormatJSON {
+		logSummary(logger, summary)
+	} else if err := printSummary(console(cfg), summary); err != nil {
+		logger.Warn("⭕ Failed to print summary", "error:", err)
+	}
+	if cfg.SummaryPath != "" {
+		if err := writeSummary(cfg.SummaryPath, summary); err != nil {
+			logger.Warn("⭕ Failed to write summary", "path:", cfg.SummaryPath, "error:", err)
+		}
+	}
+}
+
+// runOutcome возвращает ошибку, если упавших задач больше порога
+// --fail-threshold или упали все задачи.
+func runOutcome(cfg *appconfig.AppConfig, summary *runSummary, logger *slog.Logger) error {
+	if summary.Failed > 0 {
+		failed := tasksFailedError{failed: summary.Failed, total: summary.Succeeded + summary.Failed}
+		if failed.all() || float64(failed.failed)*100 > cfg.FailThreshold*float64(failed.total) {
+			return failed
+		}
+		logger.Warn("Run finished with failures within the threshold", "failed:", summary.Failed, "threshold:", fmt.Sprintf("%g%%", cfg.FailThreshold))
+		return nil
+	}
+	logger.Info("All tasks completed!")
+	return nil
+}
+
+// console возвращает вывод для логов и итогов запуска: стандартный вывод
+// или, если в него пишутся результаты, stderr.
+func console(cfg *appconfig.AppConfig) io.Writer {
+	if cfg.Output.Path == export.Stdout {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// newRunLogger создает логгер запуска по настройкам лога.
+func newRunLogger(cfg *appconfig.AppConfig) (*slog.Logger, *applog.Output, error) {
+	return applog.NewLogger(applog.Options{
+		Level:      cfg.Log.Level,
+		Quiet:      cfg.Log.Quiet,
+		Verbose:    cfg.Log.Verbose,
+		Format:     cfg.Log.Format,
+		File:       cfg.Log.File,
+		FileLevel:  cfg.Log.FileLevel,
+		MaxSize:    cfg.Log.MaxSize,
+		MaxAge:     cfg.Log.MaxAge,
+		MaxBackups: cfg.Log.MaxBackups,
+	})
+}
+
+// selectTasks отбирает задачи запуска: откладывает выключенные и применяет
+// фильтры по тегам и именам. Выключенные задачи возвращаются отдельно,
+// чтобы перечислить их в итогах.
+func selectTasks(cfg *appconfig.AppConfig, tasks []taskconfig.Task, logger *slog.Logger) ([]taskconfig.Task, []taskconfig.Task, error) {
+	// Выключенные задачи не запускаем, но перечисляем в итогах
+	tasks, disabled := taskconfig.SplitEnabled(tasks)
+
+	// Выбираем задачи запуска по тегам
+	filtered := len(cfg.Tags) > 0 || len(cfg.ExcludeTags) > 0
+	if filtered {
+		selected := taskconfig.FilterByTags(tasks, cfg.Tags, cfg.ExcludeTags)
+		logger.Info("🏷️ Selected tasks by tags", "selected:", len(selected), "skipped:", len(tasks)-len(selected))
+		tasks = selected
+	}
+
+	// Выбираем задачи запуска по именам
+	if len(cfg.Only) > 0 || len(cfg.Skip) > 0 {
+		selected, err := taskconfig.FilterByName(tasks, cfg.Only, cfg.Skip)
+		if err != nil {
+			return nil, nil, usageError{err}
+		}
+		logger.Info("🎯 Selected tasks by name", "selected:", len(selected), "skipped:", len(tasks)-len(selected))
+		if len(selected) == 0 {
+			return nil, nil, usageError{errors.New("no tasks match --only and --skip")}
+		}
+		tasks = selected
+		filtered = true
+	}
+	if filtered || len(disabled) > 0 {
+		if err := taskconfig.CheckDependencies(tasks); err != nil {
+			return nil, nil, configError{fmt.Errorf("invalid task dependencies after skipping tasks: %w", err)}
+		}
+	}
+	return tasks, disabled, nil
+}
+
+// runEntry связывает номер задачи в пуле с задачей конфига.
+type runEntry struct {
+	task     taskconfig.Task
+	stateKey string
+	// delivery - задача из общей очереди, которую нужно подтвердить.
+	delivery *queue.Delivery
+	// done закрывается, когда задача, запущенная по расписанию, завершена.
+	done chan struct{}
+}
+
+// writeMetrics записывает метрики пула в файл в формате Prometheus.
+func writeMetrics(path string, metrics workerpool.Metrics) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	if err := metrics.WritePrometheus(f, "ish3ikin_pool"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return f.Close()
+}
+
+// reportProgress периодически пишет прогресс пула в лог, пока не отменен ctx.
+func reportProgress(ctx context.Context, pool *workerpool.Pool[[]map[string]string], logger *slog.Logger) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pr := pool.Progress()
+			logger.Info("⏳ Progress", "done:", pr.Done, "failed:", pr.Failed, "running:", pr.Running, "queued:", pr.Queued, "eta:", pr.ETA.Round(time.Second))
+		}
+	}
+}
+
+// poolHooks направляет события пула в логгер приложения. taskID переводит
+// номер задачи в пуле в ID задачи конфига.
+func poolHooks(logger *slog.Logger, taskID func(int) string) workerpool.Hooks {
+	return workerpool.Hooks{
+		OnWorkerStart: func(worker int) {
+			logger.Debug("Worker started", "worker:", worker)
+		},
+		OnWorkerStop: func(worker int) {
+			logger.Debug("Worker stopped", "worker:", worker)
+		},
+		OnTaskStart: func(e workerpool.TaskEvent) {
+			logger.Debug("Task started", "task id:", taskID(e.TaskID), "task:", e.Name, "worker:", e.Worker, "attempt:", e.Attempt)
+		},
+		OnTaskRetry: func(e workerpool.TaskEvent) {
+			logger.Warn("🔁 Retrying task", "task id:", taskID(e.TaskID), "task:", e.Name, "attempt:", e.Attempt, "error:", e.Err)
+		},
+		OnTaskDone: func(e workerpool.TaskEvent) {
+			logger.Debug("Task finished", "task id:", taskID(e.TaskID), "task:", e.Name, "worker:", e.Worker, "duration:", e.Duration)
+		},
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/export"
+	"github.com/rx3lixir/ish3ikin/internal/notify"
+	"github.com/rx3lixir/ish3ikin/internal/scheduler"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// newScheduleCmd создает команду "schedule": долгоживущий процесс, который
+// выполняет задачи по их расписаниям (поле Schedule) вместо записей в crontab.
+func newScheduleCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+	var (
+		output  = "results/{task}_{time}.csv"
+		overlap = taskconfig.OverlapSkip
+	)
+
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Run tasks on their Schedule until stopped",
+		Long: `Run tasks on their Schedule until stopped.
+
+Only enabled tasks with a Schedule are run. The task config is reloaded when it
+changes. When a task is due while its previous run is still going, it is
+skipped or queued, see --overlap and the Overlap field of tasks. Runs missed
+while the process was not running or asleep are logged and run once.`,
+		Example: `  isheikin schedule -c tasks.yaml
+  isheikin schedule -c tasks.yaml --overlap queue -o 'results/{task}/{time}.jsonl'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+			if err := taskconfig.CheckOverlap(overlap); err != nil {
+				return usageError{err}
+			}
+			if output == export.Stdout {
+				return usageError{errors.New("results of scheduled runs cannot be written to stdout, set a file with --output")}
+			}
+			return runSchedule(cfg, output, overlap)
+		},
+	}
+	cfg.RegisterTaskFlags(cmd.Flags())
+	cfg.RegisterPoolFlags(cmd.Flags())
+	cfg.RegisterLogFlags(cmd.Flags())
+	cfg.RegisterNotifyFlags(cmd.Flags())
+	fs := cmd.Flags()
+	// Общий лимит времени запуска планировщику не нужен, задачи ограничивает --task-timeout
+	_ = fs.MarkHidden("timeout")
+	fs.StringVarP(&output, "output", "o", output, "Results file of every scheduled run, {task} and {time} are replaced with the task name and finish time; empty disables writing results")
+	fs.StringVar(&cfg.Output.Format, "output-format", cfg.Output.Format, "Output format: csv, json or jsonl; chosen by the output file extension by default")
+	fs.StringVar(&overlap, "overlap", overlap, "What to do when a task is due while its previous run is still going: skip or queue; Overlap of the task takes precedence")
+	fs.IntVar(&cfg.GracePeriod, "grace-period", cfg.GracePeriod, "Seconds running tasks may finish after SIGINT or SIGTERM before they are canceled")
+	return cmd
+}
+
+// runSchedule выполняет задачи по расписанию до SIGINT или SIGTERM.
+func runSchedule(cfg *appconfig.AppConfig, output, overlap string) error {
+	logger, logOut, err := newRunLogger(cfg)
+	if err != nil {
+		return usageError{err}
+	}
+	defer logOut.Close()
+
+	// Один пул на все запуски, чтобы лимиты воркеров, частоты и хостов
+	// действовали на процесс целиком.
+	entries := newRunEntries()
+	taskID := func(id int) string { return entries.get(id).task.ID }
+	pool, err := workerpool.NewPool[[]map[string]string](cfg.Workers, cfg.Workers,
+		workerpool.WithTaskTimeout(time.Duration(cfg.TaskTimeout)*time.Second),
+		workerpool.WithRetries(cfg.Retries),
+		workerpool.WithScaling(1, cfg.Workers),
+		workerpool.WithRateLimit(cfg.Rate, 1),
+		workerpool.WithKeyLimit(cfg.PerHost),
+		workerpool.WithUnboundedQueue(),
+		workerpool.WithHooks(poolHooks(logger, taskID)))
+	if err != nil {
+		return fmt.Errorf("failed to create worker pool: %w", err)
+	}
+	if cfg.ForceTaskTimeout || cfg.ForceRetries {
+		logger.Info("⚙️ Overriding task limits", "task timeout:", time.Duration(cfg.TaskTimeout)*time.Second, "retries:", cfg.Retries)
+	}
+
+	// После сигнала запуски по расписанию прекращаются
+	stop := newShutdown(time.Duration(cfg.GracePeriod)*time.Second, logger)
+	intake, stopIntake := context.WithCancel(context.Background())
+	defer stopIntake()
+
+	alerts, err := newAlerts(cfg, false, logger)
+	if err != nil {
+		return configError{err}
+	}
+	defer alerts.close()
+
+	// Движки нужны только запускам, поэтому браузер открывается после
+	// проверки конфига.
+	var engines scrp.Engines
+	sched, reloader, err := newTaskScheduler(cfg, overlap, logger, func(ctx context.Context, task taskconfig.Task) {
+		done := make(chan struct{})
+		_, err := entries.add(func() (int, error) {
+			return pool.AddTask(ctx, scrp.NewScraperTask(cfg.Override(task), engines, logger))
+		}, runEntry{task: task, done: done})
+		if err != nil {
+			logger.Error("Failed to add task", "task id:", task.ID, "url:", task.URL, "error:", err)
+			return
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return err
+	}
+	browser, closeBrowser, err := openBrowser(cfg)
+	defer closeBrowser()
+	if err != nil {
+		logger.Error("Error connecting to browser", "error:", err)
+	}
+	if engines, err = newEngines(cfg, browser, logger); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := reloader.Watch(intake); err != nil {
+			logger.Error("⭕ Stopped watching tasks", "error:", err)
+		}
+	}()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- pool.Run(context.Background())
+	}()
+	stop.watch(pool, stopIntake)
+	defer stop.release()
+
+	schedDone := make(chan struct{})
+	go func() {
+		defer close(schedDone)
+		sched.Run(intake)
+	}()
+	logger.Info("📅 Scheduler started", "workers:", cfg.Workers, "overlap:", overlap, "stop:", "Ctrl+C")
+
+	for res := range pool.Results() {
+		entry := entries.get(res.TaskID)
+		var saved string
+		if res.Err != nil {
+			logger.Error("Task failed", "task id:", entry.task.ID, "task:", res.Name, "attempts:", res.Attempts, "duration:", res.Duration, "error:", res.Err)
+		} else {
+			logger.Info("Got results", "task id:", entry.task.ID, "task:", res.Name, "duration:", res.Duration, "records:", len(res.Value))
+			if output != "" {
+				path := scheduledOutput(output, entry.task, time.Now())
+				if err := writeResults(path, cfg.Output.Format, res.Value); err != nil {
+					logger.Warn("⭕ Failed to write results", "task id:", entry.task.ID, "error:", err)
+				} else {
+					logger.Info("💾 Results saved", "path:", path)
+					saved = path
+				}
+			}
+		}
+		alerts.taskDone("", entry.task, res.Value, res.Err)
+		alerts.runDone("", scheduledSummary(entry.task, res, saved))
+		close(entry.done)
+	}
+	err = <-runErr
+	<-schedDone
+
+	if err != nil {
+		logger.Warn("Scheduler interrupted", "error:", err)
+	}
+	for _, t := range pool.Abandoned() {
+		entry := entries.get(t.TaskID)
+		logger.Warn("⭕ Abandoned task", "task id:", entry.task.ID, "task:", t.Name)
+	}
+	logger.Info("📅 Scheduler stopped")
+	return nil
+}
+
+// newTaskScheduler загружает задачи из cfg.ConfigPath и создает планировщик
+// задач с Schedule, который выполняет их функцией run. Планировщик получает
+// новый набор задач, когда конфиг меняется, если запущен reloader.Watch.
+func newTaskScheduler(cfg *appconfig.AppConfig, overlap string, logger *slog.Logger,
+	run func(ctx context.Context, task taskconfig.Task)) (*scheduler.Scheduler, *taskconfig.Reloader, error) {
+	if err := setupFetcher(cfg.ConfigHeader, cfg.ConfigCache, cfg.ConfigPath); err != nil {
+		return nil, nil, configError{fmt.Errorf("failed to load tasks: %w", err)}
+	}
+	reloader, err := taskconfig.NewReloader(cfg.ConfigPath, loadTasks)
+	if err != nil {
+		return nil, nil, configError{fmt.Errorf("failed to load tasks: %w", err)}
+	}
+
+	sched := scheduler.New(scheduler.Options{
+		Run: func(ctx context.Context, task taskconfig.Task) {
+			// Задачи по расписанию выполняются независимо друг от друга
+			task.DependsOn = nil
+			run(ctx, task)
+		},
+		Overlap: overlap,
+		Logger:  logger,
+	})
+	if sched.Update(reloader.Tasks()) == 0 {
+		return nil, nil, configError{errors.New("no enabled tasks with a Schedule in the config")}
+	}
+	reloader.OnReload = func(tasks []taskconfig.Task) {
+		logger.Info("🔄 Tasks reloaded", "scheduled:", sched.Update(tasks))
+	}
+	reloader.OnError = func(err error) {
+		logger.Error("⭕ Failed to reload tasks", "error:", err)
+	}
+	return sched, reloader, nil
+}
+
+// scheduledSummary возвращает итоги запуска задачи по расписанию для
+// уведомления. output - файл результатов, если он записан.
+func scheduledSummary(task taskconfig.Task, res workerpool.Result[[]map[string]string], output string) notify.Summary {
+	s := notify.Summary{Total: 1, Duration: res.Duration.Round(time.Millisecond).String(), Output: output}
+	if res.Err != nil {
+		s.Failed = 1
+		s.Failures = []notify.Failure{{TaskID: task.ID, URL: task.URL, Error: res.Err.Error()}}
+	} else {
+		s.Succeeded, s.Records = 1, len(res.Value)
+	}
+	return s
+}
+
+// scheduledOutput возвращает файл результатов запуска задачи по шаблону
+// --output: {task} заменяется на имя задачи, {time} - на время t.
+func scheduledOutput(pattern string, task taskconfig.Task, t time.Time) string {
+	return strings.NewReplacer(
+		"{task}", unsafeFileChars.ReplaceAllString(task.Name, "_"),
+		"{time}", t.Format("20060102-150405"),
+	).Replace(pattern)
+}
+
+// writeResults записывает записи одного запуска в новый файл path.
+func writeResults(path, format string, records []map[string]string) error {
+	if format == "" {
+		format = export.FormatFor(path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	exporter, err := export.Open(path, format)
+	if err != nil {
+		return err
+	}
+	if err := exporter.Export(records); err != nil {
+		exporter.Close()
+		return err
+	}
+	return exporter.Close()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/export"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+	"github.com/spf13/cobra"
+)
+
+// newScrapeCmd создает команду "scrape": извлекает поля одной страницы
+// по селекторам из командной строки, без файла задач.
+func newScrapeCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+	var (
+		task      = taskconfig.Task{Name: "scrape"}
+		selectors []string
+		output    = export.Stdout
+		format    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   `scrape <url> -s "Name=selector"...`,
+		Short: "Scrape one URL with selectors given on the command line",
+		Example: `  isheikin scrape https://example.com/item -s "Title=.headline" -s "Price=.price"
+  isheikin scrape https://example.com/list -s "Items=count(.item)" -o items.csv
+  isheikin scrape https://example.com/feed.xml --engine feed`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return usageError{err}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+
+			task.URL = args[0]
+			if err := parseSelectors(&task, selectors); err != nil {
+				return usageError{err}
+			}
+			tasks := []taskconfig.Task{task}
+			if err := taskconfig.Validate("command line", tasks); err != nil {
+				return usageError{err}
+			}
+			if err := taskconfig.AssignIDs(tasks); err != nil {
+				return usageError{err}
+			}
+			// В стандартный вывод по умолчанию пишем JSON: CSV по расширению тут не выбрать
+			if output == export.Stdout && format == "" {
+				format = export.FormatJSON
+			}
+			return scrapeURL(cfg, tasks[0], output, format)
+		},
+	}
+	cfg.RegisterBrowserFlags(cmd.Flags())
+	fs := cmd.Flags()
+	fs.StringArrayVarP(&selectors, "selector", "s", nil, `Field to extract as "Name=selector", repeatable; count(...) and exists(...) are supported`)
+	fs.StringVar(&task.Engine, "engine", taskconfig.EngineBrowser, "Scraping engine: browser or feed")
+	fs.StringVarP(&output, "output", "o", output, `Output file, "-" for standard output`)
+	fs.StringVar(&format, "output-format", "", "Output format: csv, json or jsonl; chosen by the output file extension by default")
+	fs.IntVarP(&cfg.Timeout, "timeout", "t", cfg.Timeout, "Timeout for scraping in seconds")
+	fs.IntVar(&cfg.NavigationTimeout, "nav-timeout", cfg.NavigationTimeout, "Page navigation timeout in seconds, 0 disables it")
+	return cmd
+}
+
+// parseSelectors заполняет поля задачи из значений "Name=selector".
+func parseSelectors(task *taskconfig.Task, values []string) error {
+	if len(values) == 0 {
+		if task.EngineName() == taskconfig.EngineFeed {
+			return nil
+		}
+		return errors.New(`at least one selector is required, use -s "Name=selector"`)
+	}
+	task.Selectors = make(map[string]taskconfig.Selector, len(values))
+	for _, value := range values {
+		name, selector, ok := strings.Cut(value, "=")
+		name, selector = strings.TrimSpace(name), strings.TrimSpace(selector)
+		if !ok || name == "" || selector == "" {
+			return fmt.Errorf(`invalid selector %q, expected "Name=selector"`, value)
+		}
+		if _, dup := task.Selectors[name]; dup {
+			return fmt.Errorf("field %s is given twice", name)
+		}
+		task.Selectors[name] = taskconfig.ParseSelector(selector)
+	}
+	return nil
+}
+
+// scrapeURL выполняет задачу и записывает ее записи в output.
+func scrapeURL(cfg *appconfig.AppConfig, task taskconfig.Task, output, format string) error {
+	logger, logOut, err := newRunLogger(cfg)
+	if err != nil {
+		return usageError{err}
+	}
+	defer logOut.Close()
+	// Стандартный вывод занят результатами
+	if output == export.Stdout {
+		logOut.SetConsole(os.Stderr)
+	}
+
+	engines, err := newEngines(cfg, nil, logger)
+	if err != nil {
+		return err
+	}
+	if task.EngineName() == taskconfig.EngineBrowser {
+		browser, closeBrowser, err := openBrowser(cfg)
+		defer closeBrowser()
+		if err != nil {
+			return fmt.Errorf("failed to open browser: %w", err)
+		}
+		if engines, err = newEngines(cfg, browser, logger); err != nil {
+			return err
+		}
+	}
+
+	exporter, err := export.Open(output, format)
+	if err != nil {
+		return usageError{err}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+
+	records, err := scrp.NewScraperTask(task, engines, logger).Execute(ctx)
+	if err != nil {
+		exporter.Close()
+		return fmt.Errorf("failed to scrape %s: %w", task.URL, err)
+	}
+	if err := exporter.Export(records); err != nil {
+		exporter.Close()
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	if err := exporter.Close(); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	if output != export.Stdout {
+		logger.Info("💾 Results saved", "path:", output, "records:", len(records))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/changes"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/seen"
+)
+
+// seenFile - хранилище выполненных URL в каталоге запусков.
+// Расширение не stateExt, чтобы файл не считался запуском.
+const seenFile = "seen.bolt"
+
+// seenURLs пропускает задачи, которые недавно выполнялись, и запоминает
+// успешно выполненные задачи. Задачи узнаются по changes.Key: разные
+// задачи с одним URL извлекают разные поля и не заменяют друг друга.
+type seenURLs struct {
+	store  seen.Store
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// openSeen открывает хранилище выполненных URL, если задан --fresh-for.
+// Без --seen и каталога запусков URL запоминать негде, пропуск выключается.
+func openSeen(cfg *appconfig.AppConfig, logger *slog.Logger) (*seenURLs, error) {
+	if cfg.FreshFor == 0 {
+		return nil, nil
+	}
+	addr := cfg.SeenURL
+	if addr == "" {
+		if cfg.RunsDir == "" {
+			logger.Warn("⭕ Fresh URLs are not skipped: set --seen or --runs-dir")
+			return nil, nil
+		}
+		addr = filepath.Join(cfg.RunsDir, seenFile)
+	}
+	store, err := seen.Open(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &seenURLs{store: store, ttl: time.Duration(cfg.FreshFor) * time.Second, logger: logger}, nil
+}
+
+// skip откладывает свежие задачи. keys - ключи задач
+// в состоянии запуска (nil без состояния), они отбираются вместе с задачами.
+// Свежие задачи, как и выполненные в прошлом запуске, удовлетворяют
+// зависимости. Если хранилище недоступно, задача выполняется.
+func (s *seenURLs) skip(ctx context.Context, tasks []taskconfig.Task, keys []string) (run []taskconfig.Task, runKeys []string, fresh []taskconfig.Task) {
+	skipped := make(map[string]bool)
+	for i, task := range tasks {
+		ok, err := s.store.Fresh(ctx, changes.Key(task))
+		if err != nil {
+			s.logger.Warn("⭕ Failed to check seen task", "task id:", task.ID, "url:", task.URL, "error:", err)
+		}
+		if ok {
+			skipped[task.Name] = true
+			fresh = append(fresh, task)
+			continue
+		}
+		run = append(run, task)
+		if keys != nil {
+			runKeys = append(runKeys, keys[i])
+		}
+	}
+	for i, task := range run {
+		run[i].DependsOn = slices.DeleteFunc(slices.Clone(task.DependsOn), func(dep string) bool { return skipped[dep] })
+	}
+	return run, runKeys, fresh
+}
+
+// mark запоминает успешно выполненную задачу.
+func (s *seenURLs) mark(ctx context.Context, task taskconfig.Task) {
+	if err := s.store.Mark(ctx, changes.Key(task), s.ttl); err != nil {
+		s.logger.Warn("⭕ Failed to remember seen task", "task id:", task.ID, "url:", task.URL, "error:", err)
+	}
+}
+
+func (s *seenURLs) Close() error {
+	return s.store.Close()
+}
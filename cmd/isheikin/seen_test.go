@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/seen"
+)
+
+func TestSeenSkipsFreshTasks(t *testing.T) {
+	ctx := context.Background()
+	store, err := seen.Open(filepath.Join(t.TempDir(), seenFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &seenURLs{store: store, ttl: time.Hour, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	defer s.Close()
+
+	list := taskconfig.Task{ID: "list", Name: "list", URL: "https://example.com/"}
+	prices := taskconfig.Task{ID: "prices", Name: "prices", URL: "https://example.com/"}
+	item := taskconfig.Task{ID: "item", Name: "item", URL: "https://example.com/item", DependsOn: []string{"list", "prices"}}
+	s.mark(ctx, list)
+
+	run, keys, fresh := s.skip(ctx, []taskconfig.Task{list, prices, item}, []string{"k-list", "k-prices", "k-item"})
+	if len(fresh) != 1 || fresh[0].ID != "list" {
+		t.Errorf("fresh = %v, want only the marked task", fresh)
+	}
+	// Задача с тем же URL, но другим именем выполняется
+	if len(run) != 2 || run[0].ID != "prices" || run[1].ID != "item" {
+		t.Fatalf("run = %v, want prices and item", run)
+	}
+	if !slices.Equal(keys, []string{"k-prices", "k-item"}) {
+		t.Errorf("keys = %v, want the keys of the tasks to run", keys)
+	}
+	if !slices.Equal(run[1].DependsOn, []string{"prices"}) {
+		t.Errorf("item depends on %v, want only the task that still runs", run[1].DependsOn)
+	}
+	if !slices.Equal(item.DependsOn, []string{"list", "prices"}) {
+		t.Error("skip changed the dependencies of the original task")
+	}
+}
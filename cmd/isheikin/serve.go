@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/notify"
+	"github.com/rx3lixir/ish3ikin/internal/scheduler"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+	"github.com/rx3lixir/ish3ikin/internal/server"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// newServeCmd создает команду "serve": REST и gRPC API, которые принимают
+// задачи, выполняют их и отдают статус и результаты запусков, и веб-панель
+// для наблюдения за ними. С --schedule сервер сам запускает задачи по расписанию.
+func newServeCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+	var (
+		listen     = "127.0.0.1:8080"
+		grpcListen string
+		token      string
+		keepRuns   = 100
+		maxRuns    = 8
+		schedule   string
+		overlap    = taskconfig.OverlapSkip
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve REST and gRPC APIs and a web dashboard to submit and monitor tasks",
+		Example: `  isheikin serve --listen :8080 --token secret
+  isheikin serve --schedule tasks.yaml --debug-artifacts debug   # dashboard at http://localhost:8080/
+  curl -X POST localhost:8080/tasks -d '{"URL": "https://example.com", "Selectors": {"Title": "h1"}}'
+  curl localhost:8080/runs/<id>
+  curl localhost:8080/runs/<id>/results
+  curl -X POST localhost:8080/runs/<id>/cancel
+  isheikin serve --grpc-listen :9090
+  grpcurl -plaintext -d '{"run_id": "<id>"}' localhost:9090 ish3ikin.v1.Scraper/Results`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+			if keepRuns < 0 {
+				return usageError{fmt.Errorf("keep runs must not be negative, got %d", keepRuns)}
+			}
+			if maxRuns < 0 {
+				return usageError{fmt.Errorf("max runs must not be negative, got %d", maxRuns)}
+			}
+			if err := taskconfig.CheckOverlap(overlap); err != nil {
+				return usageError{err}
+			}
+			cfg.ConfigPath = schedule
+			return serve(cfg, listen, grpcListen, token, keepRuns, maxRuns, overlap)
+		},
+	}
+	cfg.RegisterEngineFlags(cmd.Flags())
+	cfg.RegisterPoolFlags(cmd.Flags())
+	cfg.RegisterLogFlags(cmd.Flags())
+	cfg.RegisterNotifyFlags(cmd.Flags())
+	fs := cmd.Flags()
+	fs.StringVar(&listen, "listen", listen, "Address to serve the API on")
+	fs.StringVar(&grpcListen, "grpc-listen", grpcListen, "Address to serve the gRPC API on, empty disables it")
+	fs.StringVar(&token, "token", token, `Require "Authorization: Bearer <token>" on every request and RPC`)
+	fs.IntVar(&keepRuns, "keep-runs", keepRuns, "Number of finished runs kept in memory, 0 keeps all")
+	fs.IntVar(&maxRuns, "max-runs", maxRuns, "Number of runs going at once; more submissions are rejected with 429, 0 disables the limit")
+	fs.StringVar(&schedule, "schedule", schedule, "Task config whose tasks with a Schedule the server runs on their schedule")
+	fs.StringVar(&overlap, "overlap", overlap, "What to do when a scheduled task is due while its previous run is still going: skip or queue")
+	fs.IntVar(&cfg.GracePeriod, "grace-period", cfg.GracePeriod, "Seconds in-flight requests may finish after SIGINT or SIGTERM")
+	return cmd
+}
+
+// serve запускает API и работает до SIGINT или SIGTERM. Задачи из
+// cfg.ConfigPath, если он задан, запускаются по расписанию как запуски API.
+func serve(cfg *appconfig.AppConfig, listen, grpcListen, token string, keepRuns, maxRuns int, overlap string) error {
+	logger, logOut, err := newRunLogger(cfg)
+	if err != nil {
+		return usageError{err}
+	}
+	defer logOut.Close()
+
+	alerts, err := newAlerts(cfg, false, logger)
+	if err != nil {
+		return configError{err}
+	}
+	defer alerts.close()
+
+	var (
+		api      *server.Server
+		sched    *scheduler.Scheduler
+		reloader *taskconfig.Reloader
+	)
+	if cfg.ConfigPath != "" {
+		sched, reloader, err = newTaskScheduler(cfg, overlap, logger, func(ctx context.Context, task taskconfig.Task) {
+			run, err := api.Submit([]taskconfig.Task{task})
+			if err != nil {
+				logger.Error("Failed to start scheduled run", "task id:", task.ID, "task:", task.Name, "error:", err)
+				return
+			}
+			logger.Info("📥 Run submitted", "run id:", run.ID, "tasks:", 1, "via:", "schedule")
+			_, _ = api.Wait(ctx, run.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	browser, closeBrowser, err := openBrowser(cfg)
+	defer closeBrowser()
+	if err != nil {
+		logger.Error("Error connecting to browser", "error:", err)
+	}
+	engines, err := newEngines(cfg, browser, logger)
+	if err != nil {
+		return err
+	}
+
+	if cfg.ForceTaskTimeout || cfg.ForceRetries {
+		logger.Info("⚙️ Overriding task limits", "task timeout:", time.Duration(cfg.TaskTimeout)*time.Second, "retries:", cfg.Retries)
+	}
+	api = server.New(server.Options{
+		NewTask: func(task taskconfig.Task) workerpool.Task[[]map[string]string] {
+			return scrp.NewScraperTask(cfg.Override(task), engines, logger)
+		},
+		Workers: cfg.Workers,
+		PoolOptions: []workerpool.Option{
+			workerpool.WithTaskTimeout(time.Duration(cfg.TaskTimeout) * time.Second),
+			workerpool.WithRetries(cfg.Retries),
+			workerpool.WithScaling(1, cfg.Workers),
+			workerpool.WithRateLimit(cfg.Rate, 1),
+			workerpool.WithKeyLimit(cfg.PerHost),
+		},
+		KeepRuns:     keepRuns,
+		MaxRuns:      maxRuns,
+		Token:        token,
+		Scheduler:    sched,
+		ArtifactsDir: cfg.DebugArtifacts,
+		OnTaskDone:   alerts.taskDone,
+		OnFinish: func(run server.RunStatus) {
+			alerts.runDone(run.ID, runNotification(run))
+		},
+		Logger: logger,
+	})
+	defer api.Close()
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if sched != nil {
+		schedDone := make(chan struct{})
+		go func() {
+			defer close(schedDone)
+			sched.Run(ctx)
+		}()
+		defer func() {
+			stop()
+			<-schedDone
+		}()
+		go func() {
+			if err := reloader.Watch(ctx); err != nil {
+				logger.Error("⭕ Stopped watching tasks", "error:", err)
+			}
+		}()
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	logger.Info("🌐 Serving API", "address:", listen, "workers per run:", cfg.Workers, "token:", token != "")
+
+	var gs *grpc.Server
+	if grpcListen != "" {
+		lis, err := net.Listen("tcp", grpcListen)
+		if err != nil {
+			return fmt.Errorf("failed to listen for gRPC: %w", err)
+		}
+		gs = grpc.NewServer(api.GRPCOptions()...)
+		api.RegisterGRPC(gs)
+		go func() {
+			if err := gs.Serve(lis); err != nil {
+				serveErr <- fmt.Errorf("gRPC: %w", err)
+			}
+		}()
+		logger.Info("🌐 Serving gRPC API", "address:", lis.Addr().String())
+	}
+
+	select {
+	case err := <-serveErr:
+		if gs != nil {
+			gs.Stop()
+		}
+		return fmt.Errorf("failed to serve API: %w", err)
+	case <-ctx.Done():
+	}
+	logger.Info("🛑 Shutting down API")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.GracePeriod)*time.Second)
+	defer cancel()
+	if gs != nil {
+		go func() {
+			// Потоки Results не закончатся сами, пока идут запуски
+			<-shutdownCtx.Done()
+			gs.Stop()
+		}()
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("failed to shut down API: %w", err)
+	}
+	if gs != nil {
+		gs.GracefulStop()
+	}
+	return nil
+}
+
+// runNotification возвращает итоги запуска API для уведомления.
+func runNotification(run server.RunStatus) notify.Summary {
+	n := notify.Summary{
+		Total:       run.Total,
+		Succeeded:   run.Succeeded,
+		Failed:      run.Failed,
+		Interrupted: run.Canceled,
+		Records:     run.Records,
+	}
+	if run.Finished != nil {
+		n.Duration = run.Finished.Sub(run.Created).Round(time.Millisecond).String()
+	}
+	for _, task := range run.Tasks {
+		if task.Status == server.TaskFailed {
+			n.Failures = append(n.Failures, notify.Failure{TaskID: task.ID, URL: task.URL, Error: task.Error})
+		}
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+// shutdown останавливает запуск по SIGINT или SIGTERM. Первый сигнал
+// перестает брать новые задачи и дает выполняемым доделаться за grace,
+// второй отменяет их сразу. Собранные результаты после этого выгружаются
+// как обычно.
+type shutdown struct {
+	grace   time.Duration
+	logger  *slog.Logger
+	signals chan os.Signal
+	done    chan struct{}
+	stopped atomic.Bool
+}
+
+func newShutdown(grace time.Duration, logger *slog.Logger) *shutdown {
+	return &shutdown{
+		grace:   grace,
+		logger:  logger,
+		signals: make(chan os.Signal, 2),
+		done:    make(chan struct{}),
+	}
+}
+
+// watch перехватывает сигналы и останавливает пул. stopIntake
+// перестает добавлять задачи в пул.
+func (s *shutdown) watch(pool *workerpool.Pool[[]map[string]string], stopIntake func()) {
+	signal.Notify(s.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		graceCtx, cancelGrace := context.WithCancel(context.Background())
+		defer cancelGrace()
+		for {
+			select {
+			case <-s.done:
+				return
+			case sig := <-s.signals:
+				if s.stopped.Swap(true) {
+					s.logger.Warn("🛑 Canceling running tasks", "signal:", sig)
+					cancelGrace()
+					continue
+				}
+				s.logger.Warn("🛑 Stopping, no new tasks are started", "signal:", sig, "grace period:", s.grace, "cancel now:", "repeat the signal")
+				stopIntake()
+				go func() {
+					ctx, cancel := context.WithTimeout(graceCtx, s.grace)
+					defer cancel()
+					if abandoned, err := pool.Stop(ctx); err != nil {
+						s.logger.Warn("⭕ Running tasks canceled", "unfinished:", len(abandoned), "error:", err)
+					}
+				}()
+			}
+		}
+	}()
+}
+
+// interrupt останавливает запуск так же, как сигнал.
+func (s *shutdown) interrupt() {
+	select {
+	case s.signals <- os.Interrupt:
+	default:
+	}
+}
+
+// received сообщает, что запуск остановлен сигналом.
+func (s *shutdown) received() bool {
+	return s.stopped.Load()
+}
+
+// release возвращает сигналам обработку по умолчанию.
+func (s *shutdown) release() {
+	signal.Stop(s.signals)
+	close(s.done)
+}
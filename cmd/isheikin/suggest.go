@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/spf13/cobra"
+)
+
+// suggestFields - поля, для которых suggest ищет селекторы, в порядке вывода.
+// Title, Price и Date извлекаются как текст, у Images и Links значение -
+// атрибут src или href.
+var suggestFields = []string{"Title", "Price", "Date", "Images", "Links"}
+
+// newSuggestCmd создает команду "suggest": открывает страницу и предлагает
+// селекторы для типичных полей с примерами значений, чтобы быстрее
+// написать задачу для нового сайта.
+func newSuggestCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+	limit := 3
+
+	cmd := &cobra.Command{
+		Use:   "suggest <url>",
+		Short: "Propose selectors for title, price, date, images and links of a page",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return usageError{err}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+			if limit < 1 {
+				return usageError{fmt.Errorf("limit must be positive, got %d", limit)}
+			}
+			return suggest(cfg, args[0], limit, cmd.OutOrStdout())
+		},
+	}
+	cfg.RegisterBrowserFlags(cmd.Flags())
+	cmd.Flags().IntVarP(&limit, "limit", "n", limit, "Number of candidate selectors shown per field")
+	return cmd
+}
+
+// suggestion - селектор-кандидат для поля.
+type suggestion struct {
+	Field    string
+	Selector string
+	// Matches - сколько элементов страницы выбирает селектор.
+	Matches int
+	Sample  string
+	Score   float64
+}
+
+// suggest открывает url и выводит селекторы-кандидаты и заготовку задачи.
+func suggest(cfg *appconfig.AppConfig, pageURL string, limit int, out io.Writer) error {
+	browser, closeBrowser, err := openBrowser(cfg)
+	defer closeBrowser()
+	if err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	navTimeout := replNavTimeout
+	if cfg.NavigationTimeout > 0 {
+		navTimeout = time.Duration(cfg.NavigationTimeout) * time.Second
+	}
+	if err := openPage(page, pageURL, navTimeout); err != nil {
+		return err
+	}
+
+	suggestions, err := suggestSelectors(page)
+	if err != nil {
+		return err
+	}
+	printSuggestions(out, pageURL, suggestions, limit)
+	return nil
+}
+
+// suggestSelectors находит на странице кандидатов для полей suggestFields.
+// Кандидаты каждого поля отсортированы по убыванию оценки.
+func suggestSelectors(page *rod.Page) (map[string][]suggestion, error) {
+	res, err := page.Eval(suggestJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze page: %w", err)
+	}
+	var found []suggestion
+	if err := json.Unmarshal([]byte(res.Value.Str()), &found); err != nil {
+		return nil, fmt.Errorf("failed to decode suggestions: %w", err)
+	}
+	byField := make(map[string][]suggestion)
+	for _, s := range found {
+		byField[s.Field] = append(byField[s.Field], s)
+	}
+	return byField, nil
+}
+
+// printSuggestions выводит кандидатов по полям и заготовку задачи
+// с лучшими селекторами текстовых полей.
+func printSuggestions(out io.Writer, pageURL string, byField map[string][]suggestion, limit int) {
+	r := lipgloss.NewRenderer(out)
+	var (
+		title = r.NewStyle().Bold(true)
+		key   = r.NewStyle().Foreground(lipgloss.Color("12"))
+		dim   = r.NewStyle().Faint(true)
+	)
+
+	task := taskconfig.Task{Name: pageURL, URL: pageURL, Selectors: make(map[string]taskconfig.Selector)}
+	if u, err := url.Parse(pageURL); err == nil && u.Host != "" {
+		task.Name = u.Host
+	}
+	var attrFields []string
+	for _, field := range suggestFields {
+		candidates := byField[field]
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, title.Render(field))
+		if len(candidates) == 0 {
+			fmt.Fprintln(out, dim.Render("  no candidates"))
+			continue
+		}
+		for i, s := range candidates {
+			if i == limit {
+				break
+			}
+			fmt.Fprintf(out, "  %s %s\n", key.Render(s.Selector), dim.Render(fmt.Sprintf("(%d matches)", s.Matches)))
+			fmt.Fprintf(out, "    %s\n", shortText(s.Sample))
+		}
+		if field == "Images" || field == "Links" {
+			attrFields = append(attrFields, field)
+			continue
+		}
+		task.Selectors[field] = taskconfig.ParseSelector(candidates[0].Selector)
+	}
+
+	if len(task.Selectors) > 0 {
+		data, err := json.MarshalIndent(task, "", "  ")
+		if err == nil {
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, title.Render("Task"))
+			fmt.Fprintln(out, string(data))
+		}
+	}
+	if len(attrFields) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, dim.Render(`Selectors extract text; to follow links or collect images use them in a "foreach" step with Attribute "href" or "src".`))
+	}
+}
+
+// suggestJS перебирает элементы страницы, подходящие под каждое поле,
+// строит для них CSS-селекторы и оценивает их: за признаки поля в теге,
+// классах, itemprop и тексте, за видимость и положение выше на странице.
+// Возвращает JSON-массив кандидатов, отсортированный по полю и оценке.
+const suggestJS = `() => {
+	const validIdent = (s) => /^[A-Za-z_][\w-]*$/.test(s) && !/\d{4,}/.test(s);
+	const esc = (s) => s.replace(/"/g, '\\"');
+
+	const part = (el) => {
+		if (el.id && validIdent(el.id) && document.querySelectorAll("#" + el.id).length === 1) return "#" + el.id;
+		const tag = el.tagName.toLowerCase();
+		const prop = el.getAttribute("itemprop");
+		if (prop) return tag + '[itemprop="' + esc(prop) + '"]';
+		const classes = [...el.classList].filter(validIdent).slice(0, 2);
+		return classes.length ? tag + "." + classes.join(".") : tag;
+	};
+
+	// Добавляем предков, пока селектор выбирает слишком много элементов
+	const selectorFor = (el) => {
+		let sel = part(el);
+		let node = el.parentElement;
+		for (let depth = 0; node && node !== document.body && node !== document.documentElement && depth < 3; depth++) {
+			if (sel.startsWith("#") || document.querySelectorAll(sel).length <= 50) break;
+			sel = part(node) + " " + sel;
+			node = node.parentElement;
+		}
+		return sel;
+	};
+
+	const text = (el) => (el.textContent || "").replace(/\s+/g, " ").trim();
+	const visible = (el) => {
+		const r = el.getBoundingClientRect();
+		return r.width > 0 && r.height > 0;
+	};
+	const hint = (el, re) => re.test((el.getAttribute("class") || "") + " " + (el.getAttribute("itemprop") || "") + " " + el.id);
+	const top = (el) => el.getBoundingClientRect().top + window.scrollY;
+
+	const priceRe = /([$€£¥₽]\s?\d[\d\s.,]*|\d[\d\s.,]*\s?([$€£¥₽]|руб|rub|usd|eur|грн|zł))/i;
+	const dateRe = /(\d{4}-\d{2}-\d{2}|\d{1,2}[./]\d{1,2}[./]\d{2,4}|\d{1,2}\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec|янв|фев|мар|апр|мая|июн|июл|авг|сен|окт|ноя|дек)\w*\.?(\s+\d{4})?)/i;
+
+	const fields = {
+		Title: {
+			elements: document.querySelectorAll("h1, h2, h3, [itemprop=name], [itemprop=headline], [class*=title], [class*=Title], [class*=heading]"),
+			value: text,
+			score: (el, value) => {
+				if (value.length < 3 || value.length > 200 || el.children.length > 3) return 0;
+				let s = 1;
+				if (el.tagName === "H1") s += 3;
+				if (el.tagName === "H2") s += 1.5;
+				if (hint(el, /title|headline|name|heading/i)) s += 2;
+				return s;
+			},
+		},
+		Price: {
+			elements: document.querySelectorAll("body *"),
+			value: (el) => el.getAttribute("content") || text(el),
+			score: (el, value) => {
+				if (el.children.length > 2 || value.length > 40) return 0;
+				const byText = priceRe.test(value);
+				const byHint = hint(el, /price|cost|amount/i) && /\d/.test(value);
+				if (!byText && !byHint) return 0;
+				return (byText ? 2 : 0) + (byHint ? 3 : 0) + (el.children.length === 0 ? 1 : 0);
+			},
+		},
+		Date: {
+			elements: document.querySelectorAll("body *"),
+			value: (el) => el.getAttribute("datetime") || el.getAttribute("content") || text(el),
+			score: (el, value) => {
+				if (el.children.length > 2 || value.length > 60) return 0;
+				let s = 0;
+				if (el.tagName === "TIME") s += 3;
+				if (el.hasAttribute("datetime")) s += 1;
+				if (hint(el, /date|time|published|updated|posted/i)) s += 2;
+				if (dateRe.test(value)) s += 2;
+				return s >= 2 ? s : 0;
+			},
+		},
+		Images: {
+			elements: document.querySelectorAll("img[src]"),
+			value: (el) => el.currentSrc || el.src,
+			score: (el) => {
+				const area = el.naturalWidth * el.naturalHeight;
+				if (area > 0 && area < 64 * 64) return 0;
+				return 1 + Math.min(area / 100000, 3) + (hint(el, /photo|image|img|gallery|product/i) ? 1 : 0);
+			},
+		},
+		Links: {
+			elements: document.querySelectorAll("a[href]"),
+			value: (el) => el.href,
+			score: (el, value) => {
+				if (!/^https?:/.test(value) || el.closest("nav, header, footer")) return 0;
+				return 1 + (text(el).length > 10 ? 1 : 0) + (el.closest("article, main, [class*=item], [class*=card]") ? 2 : 0);
+			},
+		},
+	};
+
+	const result = [];
+	for (const [field, spec] of Object.entries(fields)) {
+		const bySelector = new Map();
+		for (const el of spec.elements) {
+			if (!visible(el)) continue;
+			const value = spec.value(el);
+			if (!value) continue;
+			let score = spec.score(el, value);
+			if (score <= 0) continue;
+			// Ближе к началу страницы - вероятнее основное содержимое
+			score += Math.max(0, 1 - top(el) / 3000);
+			const sel = selectorFor(el);
+			const prev = bySelector.get(sel);
+			if (!prev || prev.Score < score) bySelector.set(sel, { Field: field, Selector: sel, Sample: value, Score: score });
+		}
+		for (const s of bySelector.values()) {
+			s.Matches = document.querySelectorAll(s.Selector).length;
+			// Поле из одного значения лучше выбирать одним элементом
+			if (field !== "Images" && field !== "Links" && s.Matches > 1) s.Score -= Math.min(s.Matches / 10, 1);
+			s.Score = Math.round(s.Score * 100) / 100;
+		}
+		result.push(...[...bySelector.values()].sort((a, b) => b.Score - a.Score).slice(0, 10));
+	}
+	return JSON.stringify(result);
+}`
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// runSummary - итоги запуска, которые выводятся после остановки пула
+// и пишутся в JSON с --summary.
+type runSummary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Skipped   int
+	// Interrupted - задачи, не завершенные из-за прерванного запуска.
+	// Они входят и в Failed.
+	Interrupted int `json:",omitempty"`
+	Duration    jsonDuration
+	// P50 и P95 - перцентили длительности выполнения задач.
+	P50     jsonDuration
+	P95     jsonDuration
+	Records int
+	// Bytes - размер файла результатов.
+	Bytes    int64
+	Output   string       `json:",omitempty"`
+	Failures []failedTask `json:",omitempty"`
+
+	started   time.Time
+	durations []time.Duration
+}
+
+// failedTask - задача, не давшая результата, и причина.
+type failedTask struct {
+	TaskID string
+	URL    string
+	Error  string
+}
+
+func newRunSummary() *runSummary {
+	return &runSummary{started: time.Now()}
+}
+
+// observe учитывает завершенную задачу.
+func (s *runSummary) observe(d time.Duration, records int, err error) {
+	s.durations = append(s.durations, d)
+	if err == nil {
+		s.Succeeded++
+		s.Records += records
+	}
+}
+
+// fail добавляет задачу в список неудачных.
+func (s *runSummary) fail(taskID, url string, err error) {
+	s.Failed++
+	s.Failures = append(s.Failures, failedTask{TaskID: taskID, URL: url, Error: err.Error()})
+}
+
+// interrupt добавляет задачу, которую не дал завершить прерванный запуск.
+func (s *runSummary) interrupt(taskID, url string) {
+	s.Interrupted++
+	s.fail(taskID, url, errors.New("not finished: run interrupted"))
+}
+
+// finish подсчитывает итоги. output - файл результатов, если он записан.
+func (s *runSummary) finish(skipped int, output string) {
+	s.Skipped = skipped
+	s.Total = s.Succeeded + s.Failed + s.Skipped
+	s.Duration = jsonDuration(time.Since(s.started))
+
+	sort.Slice(s.durations, func(i, j int) bool { return s.durations[i] < s.durations[j] })
+	s.P50 = jsonDuration(percentile(s.durations, 0.50))
+	s.P95 = jsonDuration(percentile(s.durations, 0.95))
+
+	if output != "" {
+		s.Output = output
+		if info, err := os.Stat(output); err == nil {
+			s.Bytes = info.Size()
+		}
+	}
+}
+
+// percentile возвращает перцентиль p отсортированных длительностей
+// по ближайшему рангу.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// printSummary выводит итоги запуска таблицей.
+func printSummary(w io.Writer, s *runSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "Tasks:\t%d total, %d succeeded, %d failed, %d skipped\n", s.Total, s.Succeeded, s.Failed, s.Skipped)
+	fmt.Fprintf(tw, "Duration:\t%s (p50 %s, p95 %s per task)\n",
+		time.Duration(s.Duration).Round(time.Millisecond), time.Duration(s.P50).Round(time.Millisecond), time.Duration(s.P95).Round(time.Millisecond))
+	exported := fmt.Sprintf("%d records", s.Records)
+	if s.Output != "" {
+		exported += fmt.Sprintf(", %s to %s", formatBytes(s.Bytes), s.Output)
+	}
+	fmt.Fprintf(tw, "Exported:\t%s\n", exported)
+
+	if len(s.Failures) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "FAILED\tURL\tERROR")
+		for _, f := range s.Failures {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", f.TaskID, f.URL, f.Error)
+		}
+	}
+	return tw.Flush()
+}
+
+// logSummary пишет итоги запуска в лог, по записи на каждую упавшую задачу.
+func logSummary(logger *slog.Logger, s *runSummary) {
+	for _, f := range s.Failures {
+		logger.Error("⭕ Failed task", "task id:", f.TaskID, "url:", f.URL, "error:", f.Error)
+	}
+	logger.Info("📋 Run summary", "total:", s.Total, "succeeded:", s.Succeeded, "failed:", s.Failed, "skipped:", s.Skipped,
+		"duration:", time.Duration(s.Duration), "p50:", time.Duration(s.P50), "p95:", time.Duration(s.P95),
+		"records:", s.Records, "bytes:", s.Bytes)
+}
+
+// writeSummary записывает итоги запуска в файл в формате JSON.
+func writeSummary(path string, s *runSummary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	return nil
+}
+
+// formatBytes выводит размер в удобных единицах: 512 B, 1.5 KiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// jsonDuration записывается в JSON строкой вида "1.5s".
+type jsonDuration time.Duration
+
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// loadTasks загружает файл задач и готовит задачи к запуску: подставляет
+// переменные окружения и секреты, проверяет конфиг, выдает ID и проверяет зависимости.
+// Исходные ссылки на секреты остаются в Task.Refs для очереди задач.
+// Используется и при старте, и при перезагрузке конфига.
+func loadTasks(path string) ([]taskconfig.Task, error) {
+	// В зависимости от расширения файла конфигурации создаем лоадер
+	loader, err := taskconfig.NewLoaderFor(path)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := loader.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	taskconfig.KeepRefs(tasks)
+	if err := taskconfig.ExpandEnv(tasks); err != nil {
+		return nil, fmt.Errorf("failed to expand environment variables: %w", err)
+	}
+	if err := taskconfig.ResolveSecrets(tasks); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	if err := taskconfig.Validate(path, tasks); err != nil {
+		return nil, fmt.Errorf("invalid task config:\n%w", err)
+	}
+	if err := taskconfig.AssignIDs(tasks); err != nil {
+		return nil, fmt.Errorf("invalid task IDs: %w", err)
+	}
+	if err := taskconfig.CheckDependencies(tasks); err != nil {
+		return nil, fmt.Errorf("invalid task dependencies: %w", err)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	applog "github.com/rx3lixir/ish3ikin/internal/lib/logger"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+	"github.com/spf13/cobra"
+)
+
+// newTestCmd создает команду "test": выполняет одну задачу из файла
+// с отладочным логом и выводит извлеченные поля, не записывая результаты.
+func newTestCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "test --task <name>",
+		Short: "Scrape a single task with debug logs and print its fields",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prepare(cmd); err != nil {
+				return err
+			}
+			if name == "" {
+				return usageError{errors.New("task name is required, use --task")}
+			}
+			return testTask(cfg, name, cmd.OutOrStdout())
+		},
+	}
+	cfg.RegisterTaskFlags(cmd.Flags())
+	cmd.Flags().StringVar(&name, "task", "", "Name or ID of the task to scrape")
+	registerTaskCompletion(cmd, "task")
+	return cmd
+}
+
+// testTask выполняет задачу name и выводит ее записи в out.
+func testTask(cfg *appconfig.AppConfig, name string, out io.Writer) error {
+	if err := setupFetcher(cfg.ConfigHeader, cfg.ConfigCache, cfg.ConfigPath); err != nil {
+		return configError{fmt.Errorf("failed to load tasks: %w", err)}
+	}
+	tasks, err := loadTasks(cfg.ConfigPath)
+	if err != nil {
+		return configError{fmt.Errorf("failed to load tasks: %w", err)}
+	}
+	task, err := findTask(tasks, name)
+	if err != nil {
+		return usageError{err}
+	}
+
+	logger, _, err := applog.NewLogger(applog.Options{Verbose: true})
+	if err != nil {
+		return err
+	}
+	if len(task.DependsOn) > 0 {
+		logger.Warn("⭕ Dependencies are not scraped by test", "depends on:", strings.Join(task.DependsOn, ", "))
+	}
+
+	engines, err := newEngines(cfg, nil, logger)
+	if err != nil {
+		return err
+	}
+	if task.EngineName() == taskconfig.EngineBrowser {
+		browser, closeBrowser, err := openBrowser(cfg)
+		defer closeBrowser()
+		if err != nil {
+			return fmt.Errorf("failed to open browser: %w", err)
+		}
+		if engines, err = newEngines(cfg, browser, logger); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+
+	started := time.Now()
+	records, err := scrp.NewScraperTask(task, engines, logger).Execute(ctx)
+	if err != nil {
+		return fmt.Errorf("task %s failed: %w", task.ID, err)
+	}
+	printRecords(out, task, records, time.Since(started))
+	return nil
+}
+
+// findTask находит задачу по имени, ID или шаблону. Шаблон должен выбрать
+// ровно одну задачу.
+func findTask(tasks []taskconfig.Task, name string) (taskconfig.Task, error) {
+	found, err := taskconfig.FilterByName(tasks, []string{name}, nil)
+	if err != nil {
+		return taskconfig.Task{}, err
+	}
+	switch len(found) {
+	case 0:
+		return taskconfig.Task{}, fmt.Errorf("no task %q in the config", name)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, 0, len(found))
+	for _, task := range found {
+		names = append(names, fmt.Sprintf("%s (%s)", task.ID, task.Name))
+	}
+	return taskconfig.Task{}, fmt.Errorf("%q matches %d tasks, pick one: %s", name, len(found), strings.Join(names, ", "))
+}
+
+// printRecords выводит поля записей, выделяя пустые.
+func printRecords(out io.Writer, task taskconfig.Task, records []map[string]string, took time.Duration) {
+	r := lipgloss.NewRenderer(out)
+	var (
+		title = r.NewStyle().Bold(true)
+		key   = r.NewStyle().Foreground(lipgloss.Color("12"))
+		empty = r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+		dim   = r.NewStyle().Faint(true)
+	)
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, title.Render(fmt.Sprintf("%s %s", task.ID, task.Name)), dim.Render(task.URL))
+	fmt.Fprintf(out, "%d records in %s\n", len(records), took.Round(time.Millisecond))
+
+	emptyFields := 0
+	for i, record := range records {
+		keys := make([]string, 0, len(record))
+		width := 0
+		for k := range record {
+			if k == "TaskID" {
+				continue
+			}
+			keys = append(keys, k)
+			width = max(width, len(k))
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, title.Render(fmt.Sprintf("Record %d", i+1)))
+		for _, k := range keys {
+			label := key.Render(fmt.Sprintf("  %-*s", width, k))
+			value := record[k]
+			if strings.TrimSpace(value) == "" {
+				emptyFields++
+				fmt.Fprintln(out, label, empty.Render("(empty)"))
+				continue
+			}
+			// Многострочные значения выравниваем под первой строкой
+			value = strings.ReplaceAll(value, "\n", "\n"+strings.Repeat(" ", width+3))
+			fmt.Fprintln(out, label, value)
+		}
+	}
+
+	if emptyFields > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, empty.Render(fmt.Sprintf("%d empty fields", emptyFields)))
+	}
+}
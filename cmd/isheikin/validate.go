@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/spf13/cobra"
+)
+
+// newValidateCmd создает команду "validate": проверяет файл задач по JSON Schema
+// и правилам конфига, не запуская браузер. Команду можно использовать в CI:
+// при ошибках в конфиге она завершается с ненулевым кодом.
+func newValidateCmd() *cobra.Command {
+	var (
+		configPath   string
+		printSchema  bool
+		configHeader string
+		configCache  string
+	)
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check task config files without scraping",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setupFetcher(configHeader, configCache, configPath); err != nil {
+				return usageError{err}
+			}
+
+			if printSchema {
+				schema, err := taskconfig.Schema()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(schema))
+				return nil
+			}
+
+			if configPath == "" {
+				return usageError{errors.New("config file is required, use -c")}
+			}
+			if err := validateConfig(configPath); err != nil {
+				return configError{err}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: OK\n", configPath)
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&configPath, "tasks", "c", "", "Path, directory or glob of config files to validate")
+	fs.BoolVar(&printSchema, "schema", false, "Print the JSON Schema of task files and exit")
+	fs.StringVar(&configHeader, "config-header", os.Getenv("ISH3IKIN_CONFIG_HEADER"), "Header sent when fetching a remote config")
+	fs.StringVar(&configCache, "config-cache", appconfig.DefaultConfigCache(), "Directory for caching remote configs by ETag")
+	return cmd
+}
+
+// validateConfig выполняет все проверки файла задач, кроме подстановки
+// переменных окружения: в CI их обычно нет.
+func validateConfig(path string) error {
+	loader, err := taskconfig.NewLoaderFor(path)
+	if err != nil {
+		return err
+	}
+	files, err := taskconfig.ConfigFiles(path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := taskconfig.CheckSchema(file); err != nil {
+			return err
+		}
+	}
+	tasks, err := loader.Load(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := taskconfig.Validate(path, tasks); err != nil {
+		return err
+	}
+	if err := taskconfig.AssignIDs(tasks); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := taskconfig.CheckDependencies(tasks); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// setupFetcher настраивает загрузку конфигов по URL. Заголовок header
+// отправляется только на хост конфига configPath.
+func setupFetcher(header, cacheDir, configPath string) error {
+	if header != "" {
+		h, err := taskconfig.ParseHeader(header)
+		if err != nil {
+			return fmt.Errorf("invalid config header: %w", err)
+		}
+		taskconfig.DefaultFetcher.Header = h
+		taskconfig.DefaultFetcher.HeaderOrigin = taskconfig.Origin(configPath)
+	}
+	taskconfig.DefaultFetcher.CacheDir = cacheDir
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/spf13/cobra"
+)
+
+// Версия сборки задается при сборке, см. Makefile:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%FT%TZ)"
+//
+// Без ldflags версия берется из информации, которую go build записывает в бинарник.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// buildInfo - версия сборки приложения.
+type buildInfo struct {
+	Version string
+	// Commit - ревизия git, из которой собрано приложение, Modified -
+	// были ли в рабочем каталоге незакоммиченные изменения.
+	Commit   string `json:",omitempty"`
+	Modified bool   `json:",omitempty"`
+	// CommitDate - время коммита, Date - время сборки.
+	CommitDate string `json:",omitempty"`
+	Date       string `json:",omitempty"`
+	GoVersion  string
+	// Rod - версия библиотеки управления браузером, Chromium - ревизия
+	// браузера, которую она скачивает, если браузер не задан.
+	Rod      string `json:",omitempty"`
+	Chromium string
+	// Browser - версия запущенного браузера, если ее запросили.
+	Browser string `json:",omitempty"`
+}
+
+// readBuildInfo возвращает версию сборки из ldflags, а то, что в них
+// не задано, - из информации, которую go build записывает в бинарник.
+func readBuildInfo() buildInfo {
+	b := buildInfo{
+		Version:   "(devel)",
+		GoVersion: runtime.Version(),
+		Chromium:  "r" + strconv.Itoa(launcher.RevisionDefault),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Version != "" {
+			b.Version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				b.Commit = s.Value
+			case "vcs.modified":
+				b.Modified = s.Value == "true"
+			case "vcs.time":
+				b.CommitDate = s.Value
+			}
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/go-rod/rod" {
+				b.Rod = dep.Version
+			}
+		}
+	}
+
+	if version != "" {
+		b.Version = version
+	}
+	if commit != "" {
+		b.Commit, b.Modified, b.CommitDate = commit, false, ""
+	}
+	if date != "" {
+		b.Date = date
+	}
+	return b
+}
+
+// newVersionCmd создает команду "version": выводит версию сборки, ревизию,
+// дату сборки и версии rod и браузера для отчетов об ошибках.
+func newVersionCmd() *cobra.Command {
+	cfg, prepare := loadAppConfig()
+	var (
+		withBrowser bool
+		asJSON      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version and build information",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b := readBuildInfo()
+			if withBrowser {
+				if err := prepare(cmd); err != nil {
+					return err
+				}
+				v, err := browserVersion(cfg)
+				if err != nil {
+					return err
+				}
+				b.Browser = v
+			}
+
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(b)
+			}
+			return printVersion(out, b)
+		},
+	}
+	cfg.RegisterBrowserFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&withBrowser, "browser", false, "Start or connect to the browser and print its version")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print build information as JSON")
+	return cmd
+}
+
+// browserVersion запускает браузер по настройкам cfg и возвращает его версию.
+func browserVersion(cfg *appconfig.AppConfig) (string, error) {
+	browser, closeBrowser, err := openBrowser(cfg)
+	defer closeBrowser()
+	if err != nil {
+		return "", fmt.Errorf("failed to open browser: %w", err)
+	}
+	v, err := proto.BrowserGetVersion{}.Call(browser)
+	if err != nil {
+		return "", fmt.Errorf("failed to get browser version: %w", err)
+	}
+	return v.Product, nil
+}
+
+// printVersion выводит информацию о сборке.
+func printVersion(w io.Writer, b buildInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "isheikin %s\n", b.Version)
+	if b.Commit != "" {
+		c := b.Commit
+		if b.Modified {
+			c += " (modified)"
+		}
+		fmt.Fprintf(tw, "  commit:\t%s\n", c)
+	}
+	if b.CommitDate != "" {
+		fmt.Fprintf(tw, "  committed:\t%s\n", b.CommitDate)
+	}
+	if b.Date != "" {
+		fmt.Fprintf(tw, "  built:\t%s\n", b.Date)
+	}
+	fmt.Fprintf(tw, "  go:\t%s %s/%s\n", b.GoVersion, runtime.GOOS, runtime.GOARCH)
+	if b.Rod != "" {
+		fmt.Fprintf(tw, "  rod:\t%s\n", b.Rod)
+	}
+	fmt.Fprintf(tw, "  chromium:\t%s (downloaded when no browser is set)\n", b.Chromium)
+	if b.Browser != "" {
+		fmt.Fprintf(tw, "  browser:\t%s\n", b.Browser)
+	}
+	return tw.Flush()
+}
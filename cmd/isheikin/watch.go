@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rx3lixir/ish3ikin/internal/config/appconfig"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	scrp "github.com/rx3lixir/ish3ikin/internal/scraper"
+	"github.com/rx3lixir/ish3ikin/internal/state"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+// watchTasks выполняет задачи и после каждого изменения файла задач
+// заново выполняет те, что изменились или появились, выводя их записи.
+// Результаты и состояние запуска в этом режиме не записываются.
+func watchTasks(cfg *appconfig.AppConfig) error {
+	if cfg.Consume || cfg.Produce {
+		return usageError{errors.New("--watch cannot be used with the shared task queue")}
+	}
+
+	logger, logOut, err := newRunLogger(cfg)
+	if err != nil {
+		return usageError{err}
+	}
+	defer logOut.Close()
+
+	if err := setupFetcher(cfg.ConfigHeader, cfg.ConfigCache, cfg.ConfigPath); err != nil {
+		return configError{fmt.Errorf("failed to load tasks: %w", err)}
+	}
+	reloader, err := taskconfig.NewReloader(cfg.ConfigPath, loadTasks)
+	if err != nil {
+		return configError{fmt.Errorf("failed to load tasks: %w", err)}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Обрабатываем только последний набор задач: пока задачи выполняются,
+	// файл могли сохранить несколько раз.
+	reloads := make(chan []taskconfig.Task, 1)
+	reloader.OnReload = func(tasks []taskconfig.Task) {
+		select {
+		case <-reloads:
+		default:
+		}
+		reloads <- tasks
+	}
+	reloader.OnError = func(err error) {
+		logger.Error("⭕ Failed to reload tasks", "error:", err)
+	}
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- reloader.Watch(ctx)
+	}()
+
+	browser, closeBrowser, err := openBrowser(cfg)
+	defer closeBrowser()
+	if err != nil {
+		logger.Error("Error connecting to browser", "error:", err)
+	}
+	engines, err := newEngines(cfg, browser, logger)
+	if err != nil {
+		return err
+	}
+
+	// Ключи задач, уже выполненных с текущим конфигом. Упавшие задачи
+	// сюда не попадают и выполняются при каждом сохранении.
+	done := make(map[string]bool)
+	rerun := func(tasks []taskconfig.Task) {
+		selected, _, err := selectTasks(cfg, tasks, logger)
+		if err != nil {
+			logger.Error("⭕ Failed to select tasks", "error:", err)
+			return
+		}
+		var affected []taskconfig.Task
+		keys := make(map[string]bool, len(selected))
+		for _, task := range selected {
+			key := state.TaskKey(task)
+			keys[key] = true
+			if !done[key] {
+				affected = append(affected, task)
+			}
+		}
+		done = keys
+
+		if len(affected) == 0 {
+			logger.Info("👀 No tasks changed")
+		} else {
+			for _, task := range scrapeChanged(ctx, cfg, affected, engines, logger, os.Stdout) {
+				delete(done, state.TaskKey(task))
+			}
+		}
+		fmt.Fprintf(os.Stdout, "\nWatching %s for changes, press Ctrl+C to stop\n", cfg.ConfigPath)
+	}
+
+	rerun(reloader.Tasks())
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watchErr:
+			return err
+		case tasks := <-reloads:
+			rerun(tasks)
+		}
+	}
+}
+
+// scrapeChanged выполняет задачи и выводит их записи в out. Возвращает
+// задачи, которые упали или не успели выполниться.
+func scrapeChanged(ctx context.Context, cfg *appconfig.AppConfig, tasks []taskconfig.Task, engines scrp.Engines, logger *slog.Logger, out io.Writer) []taskconfig.Task {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+
+	pool, err := workerpool.NewPool[[]map[string]string](cfg.Workers, len(tasks),
+		workerpool.WithTaskTimeout(time.Duration(cfg.TaskTimeout)*time.Second),
+		workerpool.WithRetries(cfg.Retries))
+	if err != nil {
+		logger.Error("Failed to create worker pool", "error:", err)
+		return tasks
+	}
+
+	// Зависимости, которые не перезапускаются, выполнены раньше.
+	names := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		names[task.Name] = true
+	}
+	byID := make(map[int]taskconfig.Task, len(tasks))
+	for _, task := range tasks {
+		var deps []string
+		for _, dep := range task.DependsOn {
+			if names[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		task.DependsOn = deps
+
+		id, err := pool.AddTask(ctx, scrp.NewScraperTask(cfg.Override(task), engines, logger))
+		if err != nil {
+			logger.Error("Failed to add task", "task id:", task.ID, "url:", task.URL, "error:", err)
+			continue
+		}
+		byID[id] = task
+	}
+	pool.Close()
+
+	logger.Info("🔄 Scraping changed tasks", "tasks:", len(byID))
+	go pool.Run(ctx)
+
+	errStyle := lipgloss.NewRenderer(out).NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	for res := range pool.Results() {
+		task := byID[res.TaskID]
+		if res.Err != nil {
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, errStyle.Render(fmt.Sprintf("%s %s failed: %v", task.ID, task.Name, res.Err)))
+			continue
+		}
+		delete(byID, res.TaskID)
+		printRecords(out, task, res.Value, res.Duration)
+	}
+
+	failed := make([]taskconfig.Task, 0, len(byID))
+	for _, task := range byID {
+		failed = append(failed, task)
+	}
+	return failed
+}
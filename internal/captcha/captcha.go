@@ -0,0 +1,24 @@
+package captcha
+
+import (
+	"context"
+)
+
+// Типы поддерживаемых капч.
+const (
+	KindReCaptcha = "recaptcha"
+	KindHCaptcha  = "hcaptcha"
+	KindTurnstile = "turnstile"
+)
+
+// Challenge описывает капчу, обнаруженную на странице.
+type Challenge struct {
+	Kind    string
+	SiteKey string
+	PageURL string
+}
+
+// Solver решает капчу и возвращает токен, который нужно подставить в форму.
+type Solver interface {
+	Solve(ctx context.Context, challenge Challenge) (string, error)
+}
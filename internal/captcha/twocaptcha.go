@@ -0,0 +1,145 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TwoCaptchaURL - адрес API 2captcha.
+	TwoCaptchaURL = "https://2captcha.com"
+	// AntiCaptchaURL - совместимый с 2captcha адрес API anti-captcha.
+	AntiCaptchaURL = "https://api.anti-captcha.com"
+
+	defaultPollInterval = 5 * time.Second
+	notReady            = "CAPCHA_NOT_READY"
+)
+
+// TwoCaptcha реализует Solver поверх HTTP API 2captcha (in.php/res.php).
+// Тот же протокол поддерживает anti-captcha, достаточно поменять BaseURL.
+type TwoCaptcha struct {
+	APIKey       string
+	BaseURL      string
+	PollInterval time.Duration
+	Client       *http.Client
+}
+
+func NewTwoCaptcha(apiKey, baseURL string) *TwoCaptcha {
+	if baseURL == "" {
+		baseURL = TwoCaptchaURL
+	}
+	return &TwoCaptcha{
+		APIKey:       apiKey,
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+		PollInterval: defaultPollInterval,
+		Client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type twoCaptchaResponse struct {
+	Status  int    `json:"status"`
+	Request string `json:"request"`
+}
+
+// Solve отправляет капчу на решение и опрашивает сервис, пока не получит токен.
+func (t *TwoCaptcha) Solve(ctx context.Context, challenge Challenge) (string, error) {
+	params := url.Values{
+		"key":     {t.APIKey},
+		"pageurl": {challenge.PageURL},
+		"json":    {"1"},
+	}
+
+	switch challenge.Kind {
+	case KindReCaptcha:
+		params.Set("method", "userrecaptcha")
+		params.Set("googlekey", challenge.SiteKey)
+	case KindHCaptcha:
+		params.Set("method", "hcaptcha")
+		params.Set("sitekey", challenge.SiteKey)
+	case KindTurnstile:
+		params.Set("method", "turnstile")
+		params.Set("sitekey", challenge.SiteKey)
+	default:
+		return "", fmt.Errorf("unsupported captcha kind %q", challenge.Kind)
+	}
+
+	submitted, err := t.call(ctx, http.MethodPost, t.BaseURL+"/in.php", params)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit captcha: %w", err)
+	}
+	if submitted.Status != 1 {
+		return "", fmt.Errorf("captcha service rejected task: %s", submitted.Request)
+	}
+
+	poll := url.Values{
+		"key":    {t.APIKey},
+		"action": {"get"},
+		"id":     {submitted.Request},
+		"json":   {"1"},
+	}
+
+	ticker := time.NewTicker(t.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("captcha solving canceled: %w", ctx.Err())
+		case <-ticker.C:
+		}
+
+		res, err := t.call(ctx, http.MethodGet, t.BaseURL+"/res.php", poll)
+		if err != nil {
+			return "", fmt.Errorf("failed to poll captcha result: %w", err)
+		}
+		if res.Status == 1 {
+			return res.Request, nil
+		}
+		if res.Request != notReady {
+			return "", fmt.Errorf("captcha service failed: %s", res.Request)
+		}
+	}
+}
+
+func (t *TwoCaptcha) call(ctx context.Context, method, endpoint string, params url.Values) (*twoCaptchaResponse, error) {
+	var (
+		req *http.Request
+		err error
+	)
+	if method == http.MethodPost {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint+"?"+params.Encode(), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var result twoCaptchaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Request == "" {
+		return nil, errors.New("empty response from captcha service")
+	}
+	return &result, nil
+}
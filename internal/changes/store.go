@@ -0,0 +1,141 @@
+// Package changes запоминает последние записи каждой задачи между
+// запусками и находит изменения полей, ради которых задачи мониторят,
+// а также записи, которые впервые подошли под условия задачи.
+package changes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/diff"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	valuesBucket = []byte("values")
+	alertsBucket = []byte("alerts")
+)
+
+// Store хранит на диске последние записи задач и ключи записей, подходящих
+// под условия задач. В отличие от состояния
+// запуска он не зависит от запуска и от настроек задачи: ключ - имя задачи
+// и ее URL.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open открывает или создает файл с записями задач.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create changes directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open changes file %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{valuesBucket, alertsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize changes file %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Compare сравнивает записи задачи с запомненными в прошлый раз и запоминает
+// новые. Для задачи, которой еще не было, first - true, а изменений нет.
+// Изменения записей отфильтрованы по task.Watch, см. Watched.
+func (s *Store) Compare(task taskconfig.Task, records []map[string]string) (changes []diff.Change, first bool, err error) {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode records: %w", err)
+	}
+	key := []byte(Key(task))
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(valuesBucket)
+		if prev := bucket.Get(key); prev == nil {
+			first = true
+		} else {
+			var before []map[string]string
+			if err := json.Unmarshal(prev, &before); err != nil {
+				return fmt.Errorf("failed to decode records of task %s: %w", task.Name, err)
+			}
+			changes = Watched(diff.Records(before, records), task.Watch)
+		}
+		return bucket.Put(key, data)
+	})
+	return changes, first, err
+}
+
+// Matches запоминает ключи записей задачи, которые удовлетворяют условию
+// rule, и возвращает те из них, которые в прошлый раз не удовлетворяли.
+func (s *Store) Matches(task taskconfig.Task, rule string, keys []string) (fresh []string, err error) {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode matches: %w", err)
+	}
+	key := []byte(Key(task) + "\x00" + rule)
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(alertsBucket)
+		var before []string
+		if prev := bucket.Get(key); prev != nil {
+			if err := json.Unmarshal(prev, &before); err != nil {
+				return fmt.Errorf("failed to decode matches of task %s: %w", task.Name, err)
+			}
+		}
+		for _, k := range keys {
+			if !slices.Contains(before, k) {
+				fresh = append(fresh, k)
+			}
+		}
+		return bucket.Put(key, data)
+	})
+	return fresh, err
+}
+
+// WatchAll в Watch задачи отслеживает изменения любых полей.
+const WatchAll = "*"
+
+// Key возвращает ключ задачи: имя и URL. Другие настройки задачи можно
+// менять, не теряя запомненных значений.
+func Key(task taskconfig.Task) string {
+	return task.Name + "\x00" + task.URL
+}
+
+// Watched оставляет изменения полей из watch. Добавленные и удаленные
+// записи остаются всегда, измененная запись - если изменилось хотя бы
+// одно поле из watch. Пустой watch или "*" оставляет все изменения.
+func Watched(changes []diff.Change, watch []string) []diff.Change {
+	if len(watch) == 0 || slices.Contains(watch, WatchAll) {
+		return changes
+	}
+	var watched []diff.Change
+	for _, c := range changes {
+		if c.Kind != diff.Changed {
+			watched = append(watched, c)
+			continue
+		}
+		c.Fields = slices.DeleteFunc(slices.Clone(c.Fields), func(f diff.Field) bool {
+			return !slices.Contains(watch, f.Name)
+		})
+		if len(c.Fields) > 0 {
+			watched = append(watched, c)
+		}
+	}
+	return watched
+}
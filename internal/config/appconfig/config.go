@@ -1,27 +1,638 @@
 package appconfig
 
 import (
-	"flag"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/rx3lixir/ish3ikin/internal/captcha"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/notify"
+	"github.com/spf13/pflag"
 )
 
-// AppConfig содержит параметры конфигурации приложения.
+// AppConfig содержит параметры конфигурации приложения. Значения берутся
+// по возрастанию приоритета: значения по умолчанию, файл настроек,
+// переменные окружения ISH3IKIN_*, флаги командной строки. Ключи файла
+// совпадают с именами полей.
 type AppConfig struct {
-	ConfigPath string
-	Timeout    int
-	OutputPath string
+	// File - файл настроек, из которого прочитана конфигурация, Profile -
+	// выбранный профиль файла. Профили задаются в разделе Profiles файла
+	// и перекрывают его основные настройки.
+	File    string `json:"-"`
+	Profile string `json:"-"`
+	// ConfigPath - файл задач на диске, каталог, glob-шаблон или http(s) URL.
+	ConfigPath string `json:"Tasks"`
+	// ConfigHeader - заголовок "Name: value" для загрузки конфига по URL.
+	// ConfigCache - каталог, где кешируются загруженные конфиги с их ETag.
+	ConfigHeader string
+	ConfigCache  string
+	Timeout      int
+	// Workers - сколько задач выполняется одновременно, по умолчанию DefaultWorkers.
+	Workers int
+	// CaptchaKey - ключ API сервиса решения капч или ссылка на него ("env:NAME",
+	// "file:/path"). Пустой ключ отключает решение.
+	CaptchaKey string
+	// CaptchaURL - адрес 2captcha-совместимого сервиса.
+	CaptchaURL string
+	// DebugArtifacts - каталог для скриншотов и HTML неудачных задач.
+	DebugArtifacts string
+	// Record - каталог, куда сохраняются ответы сайтов, Replay - каталог,
+	// из которого они отдаются вместо сети.
+	Record string
+	Replay string
+	// NavigationTimeout и ExtractionTimeout - лимиты по умолчанию для одной задачи в секундах.
+	NavigationTimeout int
+	ExtractionTimeout int
+	// TaskTimeout - общий лимит на выполнение одной задачи в секундах, 0 - без лимита.
+	TaskTimeout int
+	// GracePeriod - сколько секунд после SIGINT или SIGTERM выполняемые
+	// задачи могут доделываться, прежде чем их прервут.
+	GracePeriod int
+	// Retries - сколько раз повторять неудачную задачу.
+	Retries int
+	// ForceTaskTimeout и ForceRetries отмечают, что --task-timeout и --retries
+	// заданы при запуске флагом или переменной окружения. Тогда они перекрывают
+	// TimeoutSeconds и Retries задач, см. Override.
+	ForceTaskTimeout bool `json:"-"`
+	ForceRetries     bool `json:"-"`
+	// Rate - сколько задач можно запускать в секунду, 0 - без ограничения.
+	Rate float64
+	// PerHost - сколько задач одного хоста можно выполнять одновременно, 0 - без ограничения.
+	PerHost int
+	// Fair выдает задачи разных хостов по очереди.
+	Fair bool
+	// RunsDir - каталог, где хранится состояние каждого запуска под его
+	// идентификатором. StatePath задает файл состояния вместо каталога.
+	// Resume продолжает запуск с указанным идентификатором (или последний,
+	// ResumeLast), пропуская выполненные задачи и выгружая их записи заново.
+	// KeepRuns - сколько последних запусков хранить в каталоге запусков,
+	// 0 - без ограничения.
+	RunsDir   string `json:"Runs"`
+	StatePath string `json:"State"`
+	Resume    string
+	KeepRuns  int
+	// Dedup выполняет задачи с одинаковым URL только один раз.
+	Dedup bool
+	// FreshFor - сколько секунд успешно выполненная задача (имя и URL)
+	// считается свежей: свежие задачи пропускаются и в следующих запусках.
+	// 0 выключает пропуск. SeenURL - хранилище выполненных задач (файл bbolt
+	// или redis://), по умолчанию оно хранится в каталоге запусков.
+	FreshFor int
+	SeenURL  string `json:"Seen"`
+	// MetricsPath - файл, куда в конце запуска пишутся метрики пула
+	// в текстовом формате Prometheus.
+	MetricsPath string `json:"Metrics"`
+	// SummaryPath - файл, куда в конце запуска пишутся итоги в JSON.
+	SummaryPath string `json:"Summary"`
+	// ManifestPath - файл манифеста запуска: сборка, хеш конфига, итог
+	// каждой задачи и записанные файлы. По умолчанию манифест хранится
+	// в каталоге запусков рядом с состоянием.
+	ManifestPath string `json:"Manifest"`
+	// Watch после каждого изменения файла задач заново выполняет
+	// измененные задачи и выводит их записи.
+	Watch bool
+	// Diff выводит в конце запуска изменения записей по сравнению
+	// с предыдущим запуском из каталога запусков.
+	Diff bool
+	// FailThreshold - доля упавших задач в процентах, при которой запуск
+	// еще считается успешным. По умолчанию любая ошибка задачи дает
+	// ненулевой код выхода, как и падение всех задач при любом пороге.
+	FailThreshold float64
+	// QueueURL - адрес общей очереди задач (redis://, sqs://).
+	// С Produce задачи конфига только кладутся в очередь, с Consume
+	// задачи берутся из очереди вместо конфига.
+	QueueURL string `json:"Queue"`
+	Produce  bool
+	Consume  bool
+	// Collect с Produce ждет итоги задач от исполнителей, запущенных
+	// с Consume и Reply, и выгружает их записи, как обычный запуск.
+	// Reply отправляет итоги задач из очереди обратно координатору.
+	// Итоги передает только очередь Redis.
+	Collect bool
+	Reply   bool
+	// Tags и ExcludeTags выбирают задачи запуска по тегам: выполняются задачи
+	// с любым тегом из Tags (все, если Tags пуст) и без тегов из ExcludeTags.
+	Tags        []string
+	ExcludeTags []string
+	// Only и Skip выбирают задачи по имени или ID: точному значению или
+	// glob-шаблону.
+	Only []string
+	Skip []string
+	// DryRun загружает и проверяет задачи, выводит план запуска
+	// и завершается, не запуская браузер.
+	DryRun bool `json:"-"`
+	// NoTUI выводит обычный лог вместо панели прогресса. Панель
+	// показывается, только если вывод - терминал.
+	NoTUI bool
+
+	Browser BrowserConfig
+	Proxy   ProxyConfig
+	Output  OutputConfig
+	Log     LogConfig
+	Notify  NotifyConfig
+}
+
+// BrowserConfig - параметры запуска браузера.
+type BrowserConfig struct {
+	// ControlURL - адрес DevTools уже запущенного браузера. Если задан,
+	// браузер не запускается и остальные параметры не используются.
+	ControlURL string
+	// Bin - исполняемый файл Chrome/Chromium. Без него используется браузер,
+	// который находит или скачивает rod.
+	Bin         string
+	Headless    bool
+	NoSandbox   bool
+	UserDataDir string
+}
+
+// ProxyConfig - прокси для браузера и загрузки лент.
+type ProxyConfig struct {
+	// URL - адрес прокси, например "http://host:3128" или "socks5://host:1080".
+	URL string
+	// Username и Password - учетные данные прокси, можно задавать ссылками
+	// на секреты "env:NAME" и "file:/path".
+	Username string
+	Password string
+	// Bypass - хосты, которые открываются без прокси.
+	Bypass []string
+}
+
+// OutputConfig описывает, куда записываются результаты.
+type OutputConfig struct {
+	// Path - файл результатов, пустой путь отключает запись.
+	Path string
+	// Format - "csv", "json" или "jsonl". По умолчанию выбирается по расширению Path.
+	Format string
+}
+
+// NotifyConfig - уведомления о завершении запусков и задачах, которые
+// падают несколько раз подряд.
+type NotifyConfig struct {
+	// ChangesPath - файл, где запоминаются последние записи задач, чтобы
+	// сообщать об их изменениях. По умолчанию хранится в каталоге запусков.
+	ChangesPath string `json:"Changes"`
+	Webhooks    []WebhookConfig
+	Telegram    []TelegramConfig
+	Slack       []SlackConfig
+	Email       []EmailConfig
+	Discord     []DiscordConfig
+	// FailureStreak - после скольких неудач подряд задача вызывает событие
+	// task_failing, 0 отключает такие события.
+	FailureStreak int
+}
+
+// WebhookConfig - адрес, на который POST-запросом отправляется JSON события.
+type WebhookConfig struct {
+	URL string
+	// Headers добавляются к запросу. Значения можно задавать ссылками
+	// на секреты "env:NAME" и "file:/path".
+	Headers map[string]string
+	// Events - типы событий: run_succeeded, run_failed и task_failing.
+	// Пустой список - все события.
+	Events []string
+}
+
+// TelegramConfig - чат, в который бот отправляет уведомления.
+type TelegramConfig struct {
+	// Token - токен бота от @BotFather, можно задать ссылкой на секрет.
+	Token string
+	// ChatID - числовой идентификатор чата или "@channel" для каналов.
+	ChatID TelegramChat
+	// Events - типы событий, пустой список - все события.
+	Events []string
+}
+
+// TelegramChat - идентификатор чата. В файле настроек его можно записать
+// и числом, и строкой.
+type TelegramChat string
+
+func (c *TelegramChat) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*c = TelegramChat(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("chat id must be a number or a string")
+	}
+	*c = TelegramChat(s)
+	return nil
+}
+
+// SlackConfig - incoming webhook Slack.
+type SlackConfig struct {
+	// URL - адрес вебхука. В нем есть токен, поэтому его можно задать
+	// ссылкой на секрет.
+	URL string
+	// Templates - шаблоны text/template сообщений по типам событий, см.
+	// notify.Templates. Для событий без шаблона отправляется стандартное сообщение.
+	Templates map[string]string
+	// Events - типы событий, пустой список - все события.
+	Events []string
+}
+
+// EmailConfig - отправка уведомлений письмом через SMTP, например
+// ежедневной сводки запусков по расписанию.
+type EmailConfig struct {
+	Host string
+	// Port - порт SMTP-сервера, по умолчанию 587 (STARTTLS), 465 - TLS.
+	Port     int
+	Username string
+	// Password можно задать ссылкой на секрет.
+	Password string
+	From     string
+	To       []string
+	// Subject - шаблон text/template темы письма, по умолчанию - заголовок события.
+	Subject string
+	// Template - путь к HTML-шаблону письма (html/template). Шаблон получает
+	// событие и поля Title, Lines, Link и Attached.
+	Template string
+	// Attach прикладывает к письму файл результатов запуска.
+	Attach bool
+	// LinkBase - адрес, по которому доступен каталог файлов результатов,
+	// в письмо добавляется ссылка на файл.
+	LinkBase string
+	// Events - типы событий, пустой список - все события.
+	Events []string
 }
 
-// LoadConfig считывает флаги командной строки и возвращает структуру конфигурации.
-func NewAppConfig() *AppConfig {
-	configPath := flag.String("c", "", "Path to config file")
-	outputPath := flag.String("o", "output.csv", "Path to output file")
-	timeOut := flag.Int("t", 10, "Set up a timeot for scraping")
+// DiscordConfig - вебхук канала Discord.
+type DiscordConfig struct {
+	// URL - адрес вебхука. В нем есть токен, поэтому его можно задать
+	// ссылкой на секрет.
+	URL string
+	// Username, если задан, заменяет имя вебхука в сообщениях.
+	Username string
+	// Events - типы событий, пустой список - все события.
+	Events []string
+}
 
-	flag.Parse()
+// LogConfig - параметры логирования.
+type LogConfig struct {
+	// Level - минимальный уровень сообщений: debug, info, warn или error.
+	Level string
+	// Quiet оставляет только предупреждения и ошибки, Verbose включает
+	// отладочные сообщения.
+	Quiet   bool
+	Verbose bool
+	// Format - "text" или "json" для отправки логов в Loki или ELK.
+	Format string
+	// File - файл лога с ротацией. В него пишутся записи от уровня FileLevel,
+	// независимо от уровня консоли. MaxSize - размер файла в мегабайтах,
+	// MaxAge - срок хранения старых файлов в днях, MaxBackups - их число.
+	File       string
+	FileLevel  string
+	MaxSize    int
+	MaxAge     int
+	MaxBackups int
+}
 
+// Default возвращает конфигурацию со значениями по умолчанию.
+func Default() *AppConfig {
 	return &AppConfig{
-		ConfigPath: *configPath,
-		OutputPath: *outputPath,
-		Timeout:    *timeOut,
+		ConfigCache:       DefaultConfigCache(),
+		RunsDir:           DefaultRunsDir(),
+		KeepRuns:          50,
+		Timeout:           10,
+		Workers:           DefaultWorkers(),
+		CaptchaURL:        captcha.TwoCaptchaURL,
+		NavigationTimeout: 30,
+		ExtractionTimeout: 60,
+		GracePeriod:       30,
+		Browser:           BrowserConfig{Headless: true},
+		Output:            OutputConfig{Path: "output.csv"},
+		Log:               LogConfig{Level: "info", Format: "text", FileLevel: "debug", MaxSize: 100, MaxAge: 7, MaxBackups: 5},
+		Notify:            NotifyConfig{FailureStreak: 3},
+	}
+}
+
+// Load возвращает конфигурацию по умолчанию, поверх которой прочитан файл
+// настроек из args (--app-config), ISH3IKIN_APP_CONFIG или текущего каталога
+// и его профиль (--profile, ISH3IKIN_PROFILE). Переменные окружения и флаги
+// применяются позже, см. RegisterFlags и ApplyEnv.
+func Load(args []string) (*AppConfig, error) {
+	cfg := Default()
+
+	path, explicit := configFile(args)
+	profile, _ := lookupArg(args, profileFlag)
+	if path != "" {
+		if err := loadFile(path, profile, cfg); err != nil {
+			if explicit || !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			path = ""
+		}
+	}
+	if path == "" && profile != "" {
+		return nil, fmt.Errorf("profile %q requires an app config file, set it with --app-config", profile)
+	}
+	cfg.File = path
+	cfg.Profile = profile
+	return cfg, nil
+}
+
+// RegisterFlags объявляет флаги запуска, значения по умолчанию которых -
+// текущие значения cfg.
+func (cfg *AppConfig) RegisterFlags(fs *pflag.FlagSet) {
+	cfg.RegisterTaskFlags(fs)
+	cfg.RegisterPoolFlags(fs)
+	cfg.RegisterLogFlags(fs)
+	cfg.RegisterNotifyFlags(fs)
+	fs.StringVarP(&cfg.Output.Path, "output", "o", cfg.Output.Path, "Path to output file, empty disables writing results")
+	fs.StringVar(&cfg.Output.Format, "output-format", cfg.Output.Format, "Output format: csv, json or jsonl; chosen by the output file extension by default")
+	fs.IntVar(&cfg.GracePeriod, "grace-period", cfg.GracePeriod, "Seconds running tasks may finish after SIGINT or SIGTERM before they are canceled")
+	fs.StringVar(&cfg.RunsDir, "runs-dir", cfg.RunsDir, "Directory keeping the state of every run for --resume, empty disables it")
+	fs.StringVar(&cfg.StatePath, "state", cfg.StatePath, "Path to the run state file, used instead of the runs directory")
+	fs.StringVar(&cfg.Resume, "resume", cfg.Resume, `Resume the run with this id (--resume=<id>), or the last run when no id is given, skipping completed tasks`)
+	fs.Lookup("resume").NoOptDefVal = ResumeLast
+	fs.IntVar(&cfg.KeepRuns, "keep-runs", cfg.KeepRuns, "Number of latest runs kept in the runs directory, 0 keeps all")
+	fs.BoolVar(&cfg.Dedup, "dedup", cfg.Dedup, "Scrape each URL only once per run")
+	fs.IntVar(&cfg.FreshFor, "fresh-for", cfg.FreshFor, "Seconds a successfully scraped task stays fresh; fresh tasks with the same name and URL are skipped in later runs, 0 disables it")
+	fs.StringVar(&cfg.SeenURL, "seen", cfg.SeenURL, "Store of scraped tasks for --fresh-for: a file path or redis://host:port/db?key=prefix, by default it is kept in the runs directory")
+	fs.StringVar(&cfg.MetricsPath, "metrics", cfg.MetricsPath, "Write pool metrics in Prometheus text format to this file after the run")
+	fs.StringVar(&cfg.SummaryPath, "summary", cfg.SummaryPath, "Write the end-of-run summary as JSON to this file")
+	fs.BoolVar(&cfg.Watch, "watch", cfg.Watch, "Re-run changed tasks and print their fields whenever the task config changes")
+	fs.BoolVar(&cfg.Diff, "diff", cfg.Diff, "Print changed records compared to the previous run in the runs directory")
+	fs.StringVar(&cfg.ManifestPath, "manifest", cfg.ManifestPath, "Write the run manifest to this file, by default it is kept in the runs directory")
+	fs.Float64Var(&cfg.FailThreshold, "fail-threshold", cfg.FailThreshold, "Percentage of failed tasks tolerated before exiting with a non-zero code")
+	fs.BoolVar(&cfg.Fair, "fair", cfg.Fair, "Dispatch tasks round-robin across hosts")
+	fs.StringVar(&cfg.QueueURL, "queue", cfg.QueueURL, "Shared task queue URL: redis://host:port/db?key=name or sqs://<queue url>")
+	fs.BoolVar(&cfg.Produce, "produce", cfg.Produce, "Push tasks from the config file to the queue and exit")
+	fs.BoolVar(&cfg.Consume, "consume", cfg.Consume, "Scrape tasks taken from the queue instead of the config file")
+	fs.BoolVar(&cfg.Collect, "collect", cfg.Collect, "With --produce, wait for the results of workers started with --consume --reply and export them; only redis:// queues carry results")
+	fs.BoolVar(&cfg.Reply, "reply", cfg.Reply, "With --consume, send the results of queued tasks back to the --collect coordinator; only redis:// queues carry results")
+	fs.Var((*listValue)(&cfg.Tags), "tags", "Comma-separated tags; run only tasks having any of them")
+	fs.Var((*listValue)(&cfg.ExcludeTags), "exclude-tags", "Comma-separated tags; skip tasks having any of them")
+	fs.Var((*listValue)(&cfg.Only), "only", "Comma-separated task names, IDs or glob patterns; run only matching tasks")
+	fs.Var((*listValue)(&cfg.Skip), "skip", "Comma-separated task names, IDs or glob patterns; skip matching tasks")
+	fs.BoolVar(&cfg.DryRun, "dry-run", cfg.DryRun, "Load and validate tasks, print the run plan and exit without launching a browser")
+	fs.BoolVar(&cfg.NoTUI, "no-tui", cfg.NoTUI, "Print plain logs instead of the live progress dashboard")
+}
+
+// RegisterBrowserFlags добавляет в fs флаги файла настроек, браузера
+// и прокси - все, что нужно командам, которые только открывают страницы.
+func (cfg *AppConfig) RegisterBrowserFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&cfg.File, configFlag, cfg.File, "Path to the app config file (.json, .yaml, .yml or .toml)")
+	fs.StringVar(&cfg.Profile, profileFlag, cfg.Profile, "Profile of the app config file to apply, e.g. dev or prod")
+	fs.StringVar(&cfg.Browser.ControlURL, "browser-url", cfg.Browser.ControlURL, "DevTools URL of a running browser to connect to instead of launching one")
+	fs.StringVar(&cfg.Browser.Bin, "browser-bin", cfg.Browser.Bin, "Path to the Chrome/Chromium executable")
+	fs.BoolVar(&cfg.Browser.Headless, "headless", cfg.Browser.Headless, "Run the browser without a window")
+	fs.BoolVar(&cfg.Browser.NoSandbox, "no-sandbox", cfg.Browser.NoSandbox, "Disable the browser sandbox, needed when running as root in containers")
+	fs.StringVar(&cfg.Browser.UserDataDir, "user-data-dir", cfg.Browser.UserDataDir, "Browser profile directory, a temporary one by default")
+	fs.StringVar(&cfg.Proxy.URL, "proxy", cfg.Proxy.URL, "Proxy URL, e.g. http://host:3128 or socks5://host:1080")
+	fs.StringVar(&cfg.Proxy.Username, "proxy-user", cfg.Proxy.Username, "Proxy username")
+	fs.StringVar(&cfg.Proxy.Password, "proxy-password", cfg.Proxy.Password, "Proxy password")
+	fs.Var((*listValue)(&cfg.Proxy.Bypass), "proxy-bypass", "Comma-separated hosts opened without the proxy")
+}
+
+// RegisterTaskFlags добавляет в fs флаги, нужные для выполнения задач
+// из файла: флаги движков и файла задач.
+func (cfg *AppConfig) RegisterTaskFlags(fs *pflag.FlagSet) {
+	cfg.RegisterEngineFlags(fs)
+	fs.StringVarP(&cfg.ConfigPath, "tasks", "c", cfg.ConfigPath, "Path, directory, glob or http(s) URL of config files (.json, .yaml, .yml, .toml or .csv)")
+	fs.StringVar(&cfg.ConfigHeader, "config-header", cfg.ConfigHeader, `Header sent when fetching a remote config, e.g. "Authorization: Bearer <token>"`)
+	fs.StringVar(&cfg.ConfigCache, "config-cache", cfg.ConfigCache, "Directory for caching remote configs by ETag, empty disables caching")
+	fs.IntVarP(&cfg.Timeout, "timeout", "t", cfg.Timeout, "Timeout of the whole run in seconds; --consume and --collect run until stopped by a signal")
+}
+
+// RegisterEngineFlags добавляет в fs флаги браузера, лимитов времени
+// страницы, капчи и записи ответов - все, что нужно движкам скрапинга.
+func (cfg *AppConfig) RegisterEngineFlags(fs *pflag.FlagSet) {
+	cfg.RegisterBrowserFlags(fs)
+	fs.StringVar(&cfg.CaptchaKey, "captcha-key", cfg.CaptchaKey, "API key of the captcha solving service")
+	fs.StringVar(&cfg.CaptchaURL, "captcha-url", cfg.CaptchaURL, "Base URL of a 2captcha-compatible service")
+	fs.IntVar(&cfg.NavigationTimeout, "nav-timeout", cfg.NavigationTimeout, "Default page navigation timeout per task in seconds, 0 disables it")
+	fs.IntVar(&cfg.ExtractionTimeout, "extract-timeout", cfg.ExtractionTimeout, "Default extraction timeout per task in seconds, 0 disables it")
+	fs.StringVar(&cfg.DebugArtifacts, "debug-artifacts", cfg.DebugArtifacts, "Directory for screenshots and HTML dumps of failed tasks")
+	fs.StringVar(&cfg.Record, "record", cfg.Record, "Directory to save site responses to for later --replay")
+	fs.StringVar(&cfg.Replay, "replay", cfg.Replay, "Directory of responses saved with --record to serve instead of the network")
+}
+
+// RegisterPoolFlags добавляет в fs флаги пула: число воркеров, лимиты
+// задач и частоты запуска.
+func (cfg *AppConfig) RegisterPoolFlags(fs *pflag.FlagSet) {
+	fs.IntVarP(&cfg.Workers, "workers", "w", cfg.Workers, "Number of tasks scraped concurrently, defaults to the number of CPUs up to 16")
+	fs.IntVar(&cfg.TaskTimeout, "task-timeout", cfg.TaskTimeout, "Hard timeout for a single task in seconds, 0 disables it; when set, overrides TimeoutSeconds of tasks")
+	fs.IntVar(&cfg.Retries, "retries", cfg.Retries, "Number of retries for a failed task; when set, overrides Retries of tasks")
+	fs.Float64Var(&cfg.Rate, "rate-limit", cfg.Rate, "Maximum number of tasks started per second, 0 disables the limit")
+	// Прежнее имя флага оставлено для существующих cron-заданий
+	fs.Float64Var(&cfg.Rate, "rate", cfg.Rate, "Alias of --rate-limit")
+	_ = fs.MarkHidden("rate")
+	fs.IntVar(&cfg.PerHost, "per-host", cfg.PerHost, "Maximum number of concurrent tasks per host, 0 disables the limit")
+}
+
+// RegisterLogFlags добавляет в fs флаги уровня, формата и файла лога.
+func (cfg *AppConfig) RegisterLogFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&cfg.Log.Level, "log-level", cfg.Log.Level, "Minimum log level: debug, info, warn or error")
+	fs.StringVar(&cfg.Log.Format, "log-format", cfg.Log.Format, "Log format: text or json")
+	fs.StringVar(&cfg.Log.File, "log-file", cfg.Log.File, "Also write logs to this file, rotated by size and age")
+	fs.StringVar(&cfg.Log.FileLevel, "log-file-level", cfg.Log.FileLevel, "Minimum level of the log file: debug, info, warn or error")
+	fs.IntVar(&cfg.Log.MaxSize, "log-max-size", cfg.Log.MaxSize, "Rotate the log file when it reaches this size in megabytes")
+	fs.IntVar(&cfg.Log.MaxAge, "log-max-age", cfg.Log.MaxAge, "Delete rotated log files older than this many days, 0 keeps them")
+	fs.IntVar(&cfg.Log.MaxBackups, "log-max-backups", cfg.Log.MaxBackups, "Number of rotated log files to keep, 0 keeps all")
+	fs.BoolVarP(&cfg.Log.Quiet, "quiet", "q", cfg.Log.Quiet, "Log only warnings and errors")
+	fs.BoolVarP(&cfg.Log.Verbose, "verbose", "v", cfg.Log.Verbose, "Log debug details, including every extracted selector")
+}
+
+// RegisterNotifyFlags добавляет в fs флаги уведомлений.
+func (cfg *AppConfig) RegisterNotifyFlags(fs *pflag.FlagSet) {
+	fs.Var((*webhooksValue)(&cfg.Notify.Webhooks), "webhook", "Comma-separated URLs to POST a JSON event to on run results and task alerts")
+	fs.IntVar(&cfg.Notify.FailureStreak, "failure-streak", cfg.Notify.FailureStreak, "Notify when a task fails this many times in a row, 0 disables it")
+	fs.StringVar(&cfg.Notify.ChangesPath, "changes", cfg.Notify.ChangesPath, "File remembering the last records of every task to report changes, by default it is kept in the runs directory")
+}
+
+// MarkOverrides отмечает лимиты задач, заданные при запуске. Вызывается
+// после ApplyEnv, чтобы учесть и переменные окружения.
+func (cfg *AppConfig) MarkOverrides(fs *pflag.FlagSet) {
+	cfg.ForceTaskTimeout = fs.Changed("task-timeout")
+	cfg.ForceRetries = fs.Changed("retries")
+}
+
+// Override возвращает задачу, в которой лимиты, заданные при запуске,
+// заменяют значения из файла задач. Так можно притормозить запуск,
+// не меняя конфиг.
+func (cfg *AppConfig) Override(task taskconfig.Task) taskconfig.Task {
+	if cfg.ForceTaskTimeout {
+		task.TimeoutSeconds = 0
+	}
+	if cfg.ForceRetries {
+		task.Retries = nil
+	}
+	return task
+}
+
+// ExpandEnv подставляет переменные окружения ${VAR} в прокси, адреса
+// очереди и хранилища URL и в путь файла результатов. Ссылка на незаданную
+// переменную - ошибка.
+func (cfg *AppConfig) ExpandEnv() error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"proxy", &cfg.Proxy.URL},
+		{"proxy user", &cfg.Proxy.Username},
+		{"proxy password", &cfg.Proxy.Password},
+		{"queue", &cfg.QueueURL},
+		{"seen store", &cfg.SeenURL},
+		{"output", &cfg.Output.Path},
+	}
+	for _, f := range fields {
+		var err error
+		if *f.value, err = taskconfig.ExpandString(*f.value); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет значения, которые нельзя проверить при разборе.
+func (cfg *AppConfig) Validate() error {
+	if cfg.Workers < 1 {
+		return fmt.Errorf("workers must be at least 1, got %d", cfg.Workers)
+	}
+	if cfg.Proxy.URL != "" {
+		u, err := url.Parse(cfg.Proxy.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q, expected scheme://host:port", cfg.Proxy.URL)
+		}
+	}
+	if cfg.FailThreshold < 0 || cfg.FailThreshold > 100 {
+		return fmt.Errorf("fail threshold must be between 0 and 100, got %g", cfg.FailThreshold)
+	}
+	if cfg.KeepRuns < 0 {
+		return fmt.Errorf("keep-runs must not be negative, got %d", cfg.KeepRuns)
+	}
+	if cfg.GracePeriod < 0 {
+		return fmt.Errorf("grace period must not be negative, got %d", cfg.GracePeriod)
+	}
+	if cfg.FreshFor < 0 {
+		return fmt.Errorf("fresh-for must not be negative, got %d", cfg.FreshFor)
+	}
+	if cfg.Record != "" && cfg.Replay != "" {
+		return errors.New("record and replay cannot be used together")
+	}
+	return cfg.Notify.Validate()
+}
+
+// Validate проверяет адреса и события уведомлений.
+func (n NotifyConfig) Validate() error {
+	if n.FailureStreak < 0 {
+		return fmt.Errorf("failure streak must not be negative, got %d", n.FailureStreak)
+	}
+	for _, hook := range n.Webhooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q, expected http(s)://host/path", hook.URL)
+		}
+		if err := checkEvents(hook.Events); err != nil {
+			return fmt.Errorf("webhook %s: %w", u.Host, err)
+		}
+	}
+	for i, chat := range n.Telegram {
+		if chat.Token == "" || chat.ChatID == "" {
+			return fmt.Errorf("telegram notification %d: Token and ChatID are required", i+1)
+		}
+		if err := checkEvents(chat.Events); err != nil {
+			return fmt.Errorf("telegram chat %s: %w", chat.ChatID, err)
+		}
+	}
+	for i, slack := range n.Slack {
+		if !validSecretURL(slack.URL) {
+			return fmt.Errorf("slack notification %d: invalid webhook URL, expected https://hooks.slack.com/... or a secret reference", i+1)
+		}
+		if err := checkEvents(slack.Events); err != nil {
+			return fmt.Errorf("slack notification %d: %w", i+1, err)
+		}
+		if _, err := notify.ParseTemplates(slack.Templates); err != nil {
+			return fmt.Errorf("slack notification %d: %w", i+1, err)
+		}
+	}
+	for i, discord := range n.Discord {
+		if !validSecretURL(discord.URL) {
+			return fmt.Errorf("discord notification %d: invalid webhook URL, expected https://discord.com/api/webhooks/... or a secret reference", i+1)
+		}
+		if err := checkEvents(discord.Events); err != nil {
+			return fmt.Errorf("discord notification %d: %w", i+1, err)
+		}
+	}
+	for i, email := range n.Email {
+		if email.Host == "" || email.From == "" || len(email.To) == 0 {
+			return fmt.Errorf("email notification %d: Host, From and To are required", i+1)
+		}
+		if email.Port < 0 || email.Port > 65535 {
+			return fmt.Errorf("email notification %d: invalid port %d", i+1, email.Port)
+		}
+		if email.LinkBase != "" {
+			if u, err := url.Parse(email.LinkBase); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("email notification %d: invalid LinkBase %q, expected http(s)://host/path", i+1, email.LinkBase)
+			}
+		}
+		if err := checkEvents(email.Events); err != nil {
+			return fmt.Errorf("email notification %d: %w", i+1, err)
+		}
+		if _, _, err := notify.ParseEmailTemplates(email.Subject, email.Template); err != nil {
+			return fmt.Errorf("email notification %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validSecretURL сообщает, что value - адрес http(s) или ссылка на секрет,
+// который проверяется после раскрытия.
+func validSecretURL(value string) bool {
+	if strings.HasPrefix(value, "env:") || strings.HasPrefix(value, "file:") {
+		return true
+	}
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// checkEvents проверяет типы событий канала уведомлений.
+func checkEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(notify.Events, event) {
+			return fmt.Errorf("unknown event %q, expected one of %s", event, strings.Join(notify.Events, ", "))
+		}
+	}
+	return nil
+}
+
+// maxDefaultWorkers ограничивает число воркеров по умолчанию: каждая задача
+// держит открытую вкладку браузера, и на больших машинах память и сам
+// браузер заканчиваются раньше процессоров.
+const maxDefaultWorkers = 16
+
+// DefaultWorkers возвращает число воркеров по умолчанию: по одному
+// на процессор, но не больше maxDefaultWorkers.
+func DefaultWorkers() int {
+	return min(runtime.NumCPU(), maxDefaultWorkers)
+}
+
+// ResumeLast - значение Resume, продолжающее последний запуск.
+const ResumeLast = "last"
+
+// DefaultRunsDir возвращает каталог состояний запусков в кеше пользователя.
+func DefaultRunsDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ish3ikin", "runs")
+}
+
+// DefaultConfigCache возвращает каталог кеша удаленных конфигов по умолчанию.
+func DefaultConfigCache() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
 	}
+	return filepath.Join(dir, "ish3ikin", "configs")
 }
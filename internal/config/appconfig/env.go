@@ -0,0 +1,82 @@
+package appconfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix - префикс переменных окружения с настройками.
+const envPrefix = "ISH3IKIN_"
+
+// envName возвращает переменную окружения флага: --proxy-user читается
+// из ISH3IKIN_PROXY_USER.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// ApplyEnv устанавливает из переменных окружения флаги fs, не заданные
+// в командной строке.
+func ApplyEnv(fs *pflag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		name := envName(f.Name)
+		if value, ok := os.LookupEnv(name); ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value of %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// listValue - флаг со списком значений через запятую.
+type listValue []string
+
+func (l *listValue) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	*l = taskconfig.ParseTags(value)
+	return nil
+}
+
+func (l *listValue) Type() string {
+	return "strings"
+}
+
+// webhooksValue - флаг с адресами вебхуков через запятую. Адреса
+// добавляются к вебхукам из файла настроек.
+type webhooksValue []WebhookConfig
+
+func (w *webhooksValue) String() string {
+	if w == nil {
+		return ""
+	}
+	urls := make([]string, len(*w))
+	for i, hook := range *w {
+		urls[i] = hook.URL
+	}
+	return strings.Join(urls, ",")
+}
+
+func (w *webhooksValue) Set(value string) error {
+	for _, u := range taskconfig.ParseTags(value) {
+		*w = append(*w, WebhookConfig{URL: u})
+	}
+	return nil
+}
+
+func (w *webhooksValue) Type() string {
+	return "urls"
+}
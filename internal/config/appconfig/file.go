@@ -0,0 +1,149 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFlag - флаг с путем к файлу настроек.
+	configFlag = "app-config"
+	// profileFlag - флаг с именем профиля из файла настроек.
+	profileFlag = "profile"
+)
+
+// defaultConfigFiles ищутся в текущем каталоге, если файл настроек не указан.
+var defaultConfigFiles = []string{"ish3ikin.yaml", "ish3ikin.yml", "ish3ikin.toml", "ish3ikin.json"}
+
+// configFile находит файл настроек: флаг --app-config, переменная
+// ISH3IKIN_APP_CONFIG или первый существующий файл из defaultConfigFiles.
+// explicit сообщает, что файл указан явно и должен существовать.
+func configFile(args []string) (path string, explicit bool) {
+	if path, ok := lookupArg(args, configFlag); ok {
+		return path, true
+	}
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name, false
+		}
+	}
+	return "", false
+}
+
+// lookupArg ищет значение флага name в args до их разбора, а если флага
+// нет - в переменной окружения флага.
+func lookupArg(args []string, name string) (string, bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		flag := strings.TrimLeft(arg, "-")
+		if value, ok := strings.CutPrefix(flag, name+"="); ok {
+			return value, true
+		}
+		if flag == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	if value := os.Getenv(envName(name)); value != "" {
+		return value, true
+	}
+	return "", false
+}
+
+// loadFile читает файл настроек поверх значений cfg: ключи, которых нет
+// в файле, сохраняют прежние значения. Непустой profile выбирает профиль
+// из раздела Profiles, который перекрывает основные настройки файла.
+func loadFile(path, profile string, cfg *AppConfig) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read app config: %w", err)
+	}
+
+	var raw map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("unsupported app config type %q, expected .json, .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse app config %s: %w", path, err)
+	}
+
+	profiles, err := takeProfiles(raw)
+	if err != nil {
+		return fmt.Errorf("invalid app config %s: %w", path, err)
+	}
+	if err := decode(raw, cfg); err != nil {
+		return fmt.Errorf("invalid app config %s: %w", path, err)
+	}
+	if profile == "" {
+		return nil
+	}
+
+	overrides, ok := profiles[profile]
+	if !ok {
+		names := slices.Sorted(maps.Keys(profiles))
+		return fmt.Errorf("app config %s has no profile %q, available: %s", path, profile, strings.Join(names, ", "))
+	}
+	if err := decode(overrides, cfg); err != nil {
+		return fmt.Errorf("invalid profile %s in app config %s: %w", profile, path, err)
+	}
+	return nil
+}
+
+// takeProfiles извлекает из файла раздел Profiles: имя профиля -> настройки.
+func takeProfiles(raw map[string]interface{}) (map[string]map[string]interface{}, error) {
+	profiles := make(map[string]map[string]interface{})
+	for key, value := range raw {
+		if !strings.EqualFold(key, "Profiles") {
+			continue
+		}
+		delete(raw, key)
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Profiles must map profile names to settings")
+		}
+		for name, settings := range section {
+			m, ok := settings.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("profile %s must be an object", name)
+			}
+			profiles[name] = m
+		}
+	}
+	return profiles, nil
+}
+
+// decode переносит настройки в cfg. Все форматы разбираются через JSON,
+// чтобы ключи и типы проверялись одинаково, а опечатки в ключах не терялись.
+func decode(raw map[string]interface{}, cfg *AppConfig) error {
+	if raw == nil {
+		return nil
+	}
+	converted, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to convert settings: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(converted))
+	dec.DisallowUnknownFields()
+	return dec.Decode(cfg)
+}
@@ -0,0 +1,22 @@
+package taskconfig
+
+// Типы действий со страницей.
+const (
+	// ActionHover наводит курсор на элемент.
+	ActionHover = "hover"
+	// ActionMove перемещает курсор к элементу или в точку (X, Y).
+	ActionMove = "move"
+)
+
+// Action описывает шаг взаимодействия со страницей перед извлечением данных.
+type Action struct {
+	Type     string `json:"Type"`
+	Selector string `json:"Selector,omitempty"`
+	// X и Y - координаты точки для "move", если Selector не задан.
+	X float64 `json:"X,omitempty"`
+	Y float64 `json:"Y,omitempty"`
+	// Steps - число промежуточных движений мыши для "move", по умолчанию 1.
+	Steps int `json:"Steps,omitempty"`
+	// WaitMs - пауза после действия в миллисекундах, чтобы контент успел появиться.
+	WaitMs int `json:"WaitMs,omitempty"`
+}
@@ -0,0 +1,12 @@
+package taskconfig
+
+// Auth описывает учетные данные для страниц, закрытых HTTP-авторизацией.
+// Заголовок Authorization отправляется только на origin из URL задачи.
+// Значения можно задавать ссылками на секреты "env:NAME" и "file:/path".
+type Auth struct {
+	// Username и Password задают Basic-авторизацию.
+	Username string `json:"Username,omitempty"`
+	Password string `json:"Password,omitempty"`
+	// Token задает Bearer-авторизацию и имеет приоритет над Basic.
+	Token string `json:"Token,omitempty"`
+}
@@ -0,0 +1,12 @@
+package taskconfig
+
+// Captcha описывает, как распознать и пройти капчу на странице задачи.
+type Captcha struct {
+	// Detect - селекторы, наличие любого из которых означает капчу.
+	Detect []string `json:"Detect"`
+	// Kind - тип капчи: "recaptcha", "hcaptcha" или "turnstile".
+	// Если не задан, определяется по разметке страницы.
+	Kind string `json:"Kind,omitempty"`
+	// Submit - селектор кнопки, которую нужно нажать после подстановки токена.
+	Submit string `json:"Submit,omitempty"`
+}
@@ -3,15 +3,145 @@ package taskconfig
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+)
+
+// Движки скрапинга.
+const (
+	// EngineBrowser открывает страницу в браузере (по умолчанию).
+	EngineBrowser = "browser"
+	// EngineFeed загружает RSS/Atom ленту по HTTP без браузера.
+	EngineFeed = "feed"
 )
 
 // TaskConfig описывает конфигурацию для скрапинга.
 type Task struct {
-	URL       string            `json:"URL"`
-	Type      string            `json:"Type"`
-	Name      string            `json:"Name"`
-	Selectors map[string]string `json:"Selectors"`
+	// ID - идентификатор задачи в пределах конфига. Если не задан,
+	// выдается по номеру задачи (см. AssignIDs).
+	ID        string              `json:"ID,omitempty"`
+	URL       string              `json:"URL"`
+	Type      string              `json:"Type"`
+	Name      string              `json:"Name"`
+	Selectors map[string]Selector `json:"Selectors"`
+	// Engine - движок скрапинга: "browser" (по умолчанию) или "feed".
+	Engine string `json:"Engine,omitempty"`
+	// Structured сопоставляет поле результата с путем в структурированных
+	// данных schema.org (JSON-LD или microdata), например "Product.offers.price".
+	Structured map[string]string `json:"Structured,omitempty"`
+	// Emulation задает локаль, часовой пояс и геолокацию для страницы задачи.
+	Emulation *Emulation `json:"Emulation,omitempty"`
+	// Captcha включает обнаружение и решение капчи перед извлечением данных.
+	Captcha *Captcha `json:"Captcha,omitempty"`
+	// Headers - дополнительные заголовки для всех запросов страницы. Значения
+	// можно задавать ссылками на секреты "env:NAME" и "file:/path".
+	Headers map[string]string `json:"Headers,omitempty"`
+	// Auth задает HTTP-авторизацию (Basic или Bearer) для страницы задачи.
+	Auth *Auth `json:"Auth,omitempty"`
+	// Proxy - прокси задачи вместо --proxy, например "socks5://host:1080".
+	// Учетные данные в адресе не принимаются: на запрос авторизации прокси
+	// отвечают --proxy-user и --proxy-password, если задан и --proxy.
+	Proxy string `json:"Proxy,omitempty"`
+	// ResponseHeaders - заголовки ответа основного документа, которые попадут
+	// в результат как поля "Header.<имя>".
+	ResponseHeaders []string `json:"ResponseHeaders,omitempty"`
+	// FailOnHTTPError завершает задачу ошибкой, если документ ответил статусом 4xx/5xx.
+	FailOnHTTPError bool `json:"FailOnHTTPError,omitempty"`
+	// CaptureConsole записывает сообщения консоли и JS-исключения страницы
+	// в отладочный лог и в поле результата "Console".
+	CaptureConsole bool `json:"CaptureConsole,omitempty"`
+	// Dialogs настраивает обработку JS-диалогов и cookie-баннеров.
+	// Без настройки диалоги закрываются.
+	Dialogs *Dialogs `json:"Dialogs,omitempty"`
+	// Stealth включает или отключает маскировку и подменяет параметры отпечатка.
+	Stealth *Stealth `json:"Stealth,omitempty"`
+	// Actions - шаги взаимодействия, выполняемые после загрузки страницы
+	// перед извлечением данных.
+	Actions []Action `json:"Actions,omitempty"`
+	// Steps описывает многошаговый сценарий. Если шаги заданы, Selectors
+	// и Structured не используются - поля извлекаются шагами "extract".
+	Steps []Step `json:"Steps,omitempty"`
+	// HAR - путь к файлу или каталогу, куда записывается HAR всей сетевой
+	// активности страницы. Пустое значение отключает запись.
+	HAR string `json:"HAR,omitempty"`
+	// NavigationTimeout - лимит на открытие и загрузку страницы в секундах.
+	// ExtractionTimeout - лимит на все, что происходит после загрузки.
+	// Нулевые значения означают значения по умолчанию из настроек приложения.
+	NavigationTimeout int `json:"NavigationTimeout,omitempty"`
+	ExtractionTimeout int `json:"ExtractionTimeout,omitempty"`
+	// WaitUntil - когда страница считается загруженной: WaitLoad (по умолчанию),
+	// WaitIdle или WaitStable. WaitSelector - CSS-селектор, появления которого
+	// нужно дождаться после этого. Оба ожидания ограничены NavigationTimeout.
+	WaitUntil    string `json:"WaitUntil,omitempty"`
+	WaitSelector string `json:"WaitSelector,omitempty"`
+	// TimeoutSeconds - общий лимит на одну попытку задачи вместо --task-timeout.
+	// Retries - число повторов вместо --retries; 0 отключает повторы для задачи.
+	// Незаданные значения берутся из настроек приложения.
+	TimeoutSeconds int  `json:"TimeoutSeconds,omitempty"`
+	Retries        *int `json:"Retries,omitempty"`
+	// Priority - приоритет задачи в очереди: задачи с большим значением
+	// запускаются раньше. По умолчанию 0.
+	Priority int `json:"Priority,omitempty"`
+	// DependsOn - имена задач (Name), после успешного завершения которых
+	// запускается эта задача. Если зависимость завершилась ошибкой,
+	// задача не выполняется.
+	DependsOn []string `json:"DependsOn,omitempty"`
+	// Enabled выключает задачу, не удаляя ее из конфига. По умолчанию true.
+	Enabled *bool `json:"Enabled,omitempty"`
+	// Schedule - cron-выражение ("*/15 * * * *", "@daily", "@every 1h"), по которому
+	// задачу запускает планировщик. Разовый запуск выполняет задачу независимо от него.
+	Schedule string `json:"Schedule,omitempty"`
+	// Overlap - что делает планировщик, если подошло время запуска, а прошлый
+	// еще выполняется: OverlapSkip пропускает запуск, OverlapQueue выполняет
+	// его сразу после прошлого. По умолчанию - как задано при запуске планировщика.
+	Overlap string `json:"Overlap,omitempty"`
+	// Tags - метки задачи для выбора задач запуска флагами --tags и --exclude-tags.
+	Tags []string `json:"Tags,omitempty"`
+	// Watch - поля, изменение которых между запусками вызывает событие
+	// changed, "*" - любые поля. Без Watch изменения задачи не отслеживаются.
+	Watch []string `json:"Watch,omitempty"`
+	// Alerts - условия на записи задачи вроде "Price < 5000", см. пакет
+	// rules. Запись, которая начала удовлетворять условию, вызывает событие alert.
+	Alerts []string `json:"Alerts,omitempty"`
+	// Params - значения подстановок URL-шаблона: "URL": "https://site/{city}/"
+	// с "Params": {"city": ["msk", "spb"]} дает по задаче на город.
+	// Диапазоны вроде {1..50} задаются прямо в URL.
+	Params map[string][]string `json:"Params,omitempty"`
+	// Vars - значения подстановок, из которых получена задача. Попадают
+	// в результат как поля "Param.<имя>".
+	Vars map[string]string `json:"Vars,omitempty"`
+
+	// Source и Index - файл, из которого загружена задача, и ее номер
+	// в этом файле. Заполняются лоадером для сообщений об ошибках.
+	Source string `json:"-"`
+	Index  int    `json:"-"`
+	// Refs - значения задачи до подстановки переменных окружения
+	// и секретов, см. KeepRefs.
+	Refs *Refs `json:"-"`
+}
+
+// EngineName возвращает движок задачи с учетом значения по умолчанию.
+func (t Task) EngineName() string {
+	if t.Engine == "" {
+		return EngineBrowser
+	}
+	return t.Engine
+}
+
+// IsEnabled сообщает, включена ли задача.
+func (t Task) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+// SplitEnabled разделяет задачи на включенные и выключенные.
+func SplitEnabled(tasks []Task) (enabled, disabled []Task) {
+	enabled = make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.IsEnabled() {
+			enabled = append(enabled, task)
+		} else {
+			disabled = append(disabled, task)
+		}
+	}
+	return enabled, disabled
 }
 
 // Loader определяет интерфейс загрузки конфигурации.
@@ -27,14 +157,63 @@ func NewJSONLoader() *JSONTasksLoader {
 }
 
 func (j *JSONTasksLoader) Load(filePath string) ([]Task, error) {
-	data, err := os.ReadFile(filePath)
+	return loadFile(filePath, j)
+}
+
+func (j *JSONTasksLoader) parse(data []byte) (taskFile, error) {
+	return decodeFile(data)
+}
+
+// decodeFile разбирает JSON файла задач: массив задач или объект
+// с полями Include и Tasks.
+func decodeFile(data []byte) (taskFile, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return taskFile{}, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return decodeRaw(raw)
+}
+
+// decodeRaw переводит файл задач, прочитанный в общие структуры, в taskFile:
+// приводит его к текущей версии формата и разбирает через JSON, чтобы все
+// форматы использовали одни и те же имена полей и правила разбора.
+func decodeRaw(raw interface{}) (taskFile, error) {
+	doc, _, err := migrate(normalize(raw))
+	if err != nil {
+		return taskFile{}, err
+	}
+	data, err := json.Marshal(doc)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return taskFile{}, fmt.Errorf("failed to convert config: %w", err)
 	}
+	var file taskFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return taskFile{}, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return file, nil
+}
 
-	var configs []Task
-	if err := json.Unmarshal(data, &configs); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+// normalize заменяет map[interface{}]interface{} на map[string]interface{},
+// который понимает encoding/json.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalize(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+	default:
+		return v
 	}
-	return configs, nil
 }
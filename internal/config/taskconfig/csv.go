@@ -0,0 +1,77 @@
+package taskconfig
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CSVTasksLoader реализует загрузку из CSV для простых задач: одна строка -
+// одна задача. Первая строка - заголовок. Колонки URL, Name, Type, ID, Engine
+// и Priority заполняют одноименные поля, остальные колонки задают селекторы:
+// имя колонки - имя поля результата, значение - селектор. Пустые ячейки
+// селекторов пропускаются.
+type CSVTasksLoader struct{}
+
+func NewCSVLoader() *CSVTasksLoader {
+	return &CSVTasksLoader{}
+}
+
+func (c *CSVTasksLoader) Load(filePath string) ([]Task, error) {
+	return loadFile(filePath, c)
+}
+
+func (c *CSVTasksLoader) parse(data []byte) (taskFile, error) {
+	tasks, err := parseCSV(data)
+	return taskFile{Tasks: tasks}, err
+}
+
+func parseCSV(data []byte) ([]Task, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	tasks := make([]Task, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		task := Task{Selectors: make(map[string]Selector)}
+		for col, value := range row {
+			value = strings.TrimSpace(value)
+			switch name := strings.TrimSpace(header[col]); name {
+			case "URL":
+				task.URL = value
+			case "Name":
+				task.Name = value
+			case "Type":
+				task.Type = value
+			case "ID":
+				task.ID = value
+			case "Engine":
+				task.Engine = value
+			case "Priority":
+				if value == "" {
+					continue
+				}
+				p, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: invalid Priority %q", i+2, value)
+				}
+				task.Priority = p
+			default:
+				if value != "" {
+					task.Selectors[name] = ParseSelector(value)
+				}
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,65 @@
+package taskconfig
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// applyDefaults дополняет задачу значениями из блока Defaults файла.
+// Поля задачи имеют приоритет, вложенные объекты (Headers, Selectors,
+// Emulation и т.п.) объединяются по ключам. Логические поля без указателя
+// нельзя выключить в задаче, если они включены в Defaults.
+func applyDefaults(defaults, task Task) (Task, error) {
+	base, err := toMap(defaults)
+	if err != nil {
+		return Task{}, err
+	}
+	own, err := toMap(task)
+	if err != nil {
+		return Task{}, err
+	}
+
+	data, err := json.Marshal(mergeMaps(base, own))
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to apply defaults: %w", err)
+	}
+	var merged Task
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return Task{}, fmt.Errorf("failed to apply defaults: %w", err)
+	}
+	merged.Source, merged.Index = task.Source, task.Index
+	return merged, nil
+}
+
+func toMap(task Task) (map[string]interface{}, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply defaults: %w", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to apply defaults: %w", err)
+	}
+	// Поля без omitempty (Type, Selectors и др.) приходят пустыми
+	// и не должны затирать значения по умолчанию.
+	for k, v := range m {
+		if v == nil || v == "" {
+			delete(m, k)
+		}
+	}
+	return m, nil
+}
+
+// mergeMaps накладывает override на base, объединяя вложенные объекты.
+func mergeMaps(base, override map[string]interface{}) map[string]interface{} {
+	for k, v := range override {
+		baseObj, ok1 := base[k].(map[string]interface{})
+		obj, ok2 := v.(map[string]interface{})
+		if ok1 && ok2 {
+			base[k] = mergeMaps(baseObj, obj)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
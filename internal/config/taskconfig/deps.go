@@ -0,0 +1,56 @@
+package taskconfig
+
+import "fmt"
+
+// CheckDependencies проверяет, что все задачи из DependsOn существуют
+// и зависимости не образуют цикл.
+func CheckDependencies(tasks []Task) error {
+	graph := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		if task.Name != "" {
+			graph[task.Name] = append(graph[task.Name], task.DependsOn...)
+		}
+	}
+
+	for _, task := range tasks {
+		if len(task.DependsOn) > 0 && task.Name == "" {
+			return fmt.Errorf("task %s has dependencies but no Name", task.URL)
+		}
+		for _, dep := range task.DependsOn {
+			if _, ok := graph[dep]; !ok {
+				return fmt.Errorf("task %s depends on unknown task %s", task.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(graph))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v", append(path, name))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range graph[name] {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for name := range graph {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
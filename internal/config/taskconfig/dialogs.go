@@ -0,0 +1,22 @@
+package taskconfig
+
+// Реакции на JS-диалоги.
+const (
+	DialogDismiss = "dismiss"
+	DialogAccept  = "accept"
+)
+
+// Dialogs описывает автоматическую обработку диалогов и cookie-баннеров.
+type Dialogs struct {
+	// Action - реакция на alert/confirm/prompt: "dismiss" (по умолчанию) или "accept".
+	Action string `json:"Action,omitempty"`
+	// PromptText - ответ, который вводится в prompt при Action "accept".
+	PromptText string `json:"PromptText,omitempty"`
+	// Consent - селекторы кнопок cookie-баннеров, которые нажимаются после загрузки страницы.
+	Consent []string `json:"Consent,omitempty"`
+}
+
+// Accept сообщает, нужно ли принимать диалоги.
+func (d *Dialogs) Accept() bool {
+	return d != nil && d.Action == DialogAccept
+}
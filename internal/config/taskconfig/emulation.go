@@ -0,0 +1,36 @@
+package taskconfig
+
+// Emulation описывает окружение, которое браузер изображает для конкретной задачи.
+type Emulation struct {
+	// Locale - локаль в формате BCP 47 ("ru-RU"), задает Accept-Language и Intl.
+	Locale string `json:"Locale,omitempty"`
+	// Timezone - идентификатор часового пояса IANA ("Asia/Barnaul").
+	Timezone string `json:"Timezone,omitempty"`
+	// Geolocation подменяет координаты, которые видит navigator.geolocation.
+	Geolocation *Geolocation `json:"Geolocation,omitempty"`
+	// Device - профиль устройства: "mobile", "tablet" или "desktop".
+	// Задает размер экрана, плотность пикселей, touch и User-Agent.
+	Device string `json:"Device,omitempty"`
+	// Landscape поворачивает экран профиля Device в альбомную ориентацию.
+	Landscape bool `json:"Landscape,omitempty"`
+	// Viewport явно задает параметры экрана и применяется поверх профиля Device.
+	Viewport *Viewport `json:"Viewport,omitempty"`
+}
+
+// Viewport описывает параметры экрана эмулируемого устройства.
+type Viewport struct {
+	Width  int `json:"Width"`
+	Height int `json:"Height"`
+	// DeviceScaleFactor - плотность пикселей (DPR), по умолчанию 1.
+	DeviceScaleFactor float64 `json:"DeviceScaleFactor,omitempty"`
+	Mobile            bool    `json:"Mobile,omitempty"`
+	Touch             bool    `json:"Touch,omitempty"`
+}
+
+// Geolocation задает координаты для эмуляции.
+type Geolocation struct {
+	Latitude  float64 `json:"Latitude"`
+	Longitude float64 `json:"Longitude"`
+	// Accuracy - точность в метрах, по умолчанию 100.
+	Accuracy float64 `json:"Accuracy,omitempty"`
+}
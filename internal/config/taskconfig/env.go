@@ -0,0 +1,71 @@
+package taskconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envRef - ссылка на переменную окружения вида ${VAR}. Запись $VAR без скобок
+// не раскрывается, чтобы не задеть знак доллара в URL и заголовках.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnv подставляет значения переменных окружения ${VAR} в URL,
+// заголовки и учетные данные задач. Ссылка на незаданную переменную - ошибка,
+// как и любая ссылка в задаче из файла, загруженного по HTTP.
+func ExpandEnv(tasks []Task) error {
+	for i := range tasks {
+		t := &tasks[i]
+		expand := ExpandString
+		if isRemote(t.Source) {
+			expand = refuseEnv
+		}
+		var err error
+		if t.URL, err = expand(t.URL); err != nil {
+			return fmt.Errorf("task %d: URL: %w", i+1, err)
+		}
+		for name, value := range t.Headers {
+			if t.Headers[name], err = expand(value); err != nil {
+				return fmt.Errorf("task %d: header %s: %w", i+1, name, err)
+			}
+		}
+		if t.Auth != nil {
+			for _, field := range []*string{&t.Auth.Username, &t.Auth.Password, &t.Auth.Token} {
+				if *field, err = expand(*field); err != nil {
+					return fmt.Errorf("task %d: auth: %w", i+1, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandString подставляет переменные окружения ${VAR} в строку.
+func ExpandString(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var missing []string
+	out := envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// refuseEnv возвращает строку как есть, если в ней нет ссылок ${VAR}.
+func refuseEnv(s string) (string, error) {
+	if envRef.MatchString(s) {
+		return "", errors.New("environment variables are not allowed in a remote config")
+	}
+	return s, nil
+}
@@ -0,0 +1,35 @@
+package taskconfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// AssignIDs проверяет, что заданные в конфиге ID уникальны, и выдает
+// остальным задачам ID по их номеру в конфиге: "t1", "t2" и так далее.
+// По ID задача находится в логах, результатах и отчетах об ошибках.
+func AssignIDs(tasks []Task) error {
+	used := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if task.ID == "" {
+			continue
+		}
+		if used[task.ID] {
+			return fmt.Errorf("duplicate task ID %q", task.ID)
+		}
+		used[task.ID] = true
+	}
+
+	for i := range tasks {
+		if tasks[i].ID != "" {
+			continue
+		}
+		id := "t" + strconv.Itoa(i+1)
+		for suffix := 2; used[id]; suffix++ {
+			id = "t" + strconv.Itoa(i+1) + "-" + strconv.Itoa(suffix)
+		}
+		tasks[i].ID = id
+		used[id] = true
+	}
+	return nil
+}
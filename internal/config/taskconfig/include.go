@@ -0,0 +1,221 @@
+package taskconfig
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// taskFile - содержимое файла задач. Файл может быть просто списком задач
+// или объектом, который подключает другие файлы:
+//
+//	{"Include": ["common/news.json"], "Tasks": [...]}
+//
+// Пути в Include считаются от каталога подключающего файла, в том числе
+// для файлов, загруженных по HTTP: такие файлы подключают только файлы
+// по HTTP и не могут задавать HAR и Proxy. Задачи подключенных файлов
+// идут перед задачами самого файла. URL-шаблоны задач разворачиваются
+// при загрузке.
+type taskFile struct {
+	// Version - версия формата файла, см. CurrentVersion.
+	Version int      `json:"Version,omitempty"`
+	Include []string `json:"Include,omitempty"`
+	Tasks   []Task   `json:"Tasks,omitempty"`
+	// URLs и Template строят задачи из списка ссылок, см. URLList.
+	URLs     *URLList `json:"URLs,omitempty"`
+	Template *Task    `json:"Template,omitempty"`
+	// Defaults - значения полей по умолчанию для задач этого файла
+	// и шаблона URLs, см. applyDefaults. На подключенные файлы не влияет.
+	Defaults *Task `json:"Defaults,omitempty"`
+}
+
+// fileParser разбирает содержимое файла задач одного формата.
+type fileParser interface {
+	parse(data []byte) (taskFile, error)
+}
+
+// loadFile загружает файл задач вместе со всеми подключенными файлами.
+func loadFile(filePath string, p fileParser) ([]Task, error) {
+	return loadIncludes(filePath, p, nil, make(map[string]bool))
+}
+
+// loadIncludes загружает файл и рекурсивно его Include. stack - цепочка
+// подключающих файлов, по ней обнаруживаются циклы. loaded - уже
+// загруженные файлы: файл, подключенный по нескольким путям, дает
+// свои задачи один раз.
+func loadIncludes(filePath string, p fileParser, stack []string, loaded map[string]bool) ([]Task, error) {
+	abs, err := canonicalPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(stack, abs) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, abs), " -> "))
+	}
+	if loaded[abs] {
+		return nil, nil
+	}
+	loaded[abs] = true
+	stack = append(stack, abs)
+
+	data, err := readConfig(filePath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := p.parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	if isRemote(filePath) {
+		if err := checkRemote(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+	}
+
+	var tasks []Task
+	for _, include := range file.Include {
+		include, err := resolvePath(filePath, include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		loader, err := NewLoaderFor(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: include %s: %w", filePath, include, err)
+		}
+		parser, ok := loader.(fileParser)
+		if !ok {
+			return nil, fmt.Errorf("%s: include %s: format cannot be included", filePath, include)
+		}
+		included, err := loadIncludes(include, parser, stack, loaded)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, included...)
+	}
+
+	if file.Template != nil && file.URLs == nil {
+		return nil, fmt.Errorf("%s: Template requires URLs", filePath)
+	}
+	if file.Defaults != nil && file.Template != nil {
+		template, err := applyDefaults(*file.Defaults, *file.Template)
+		if err != nil {
+			return nil, fmt.Errorf("%s: Template: %w", filePath, err)
+		}
+		file.Template = &template
+	}
+	if file.URLs != nil {
+		listed, err := loadURLList(filePath, *file.URLs, file.Template)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		tasks = append(tasks, listed...)
+	}
+
+	for i, task := range file.Tasks {
+		if file.Defaults != nil {
+			if task, err = applyDefaults(*file.Defaults, task); err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", filePath, i, err)
+			}
+		}
+		task.Source, task.Index = filePath, i
+		expanded, err := expandTemplate(task)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", filePath, i, err)
+		}
+		tasks = append(tasks, expanded...)
+	}
+	return tasks, nil
+}
+
+// checkRemote запрещает в удаленном файле задач поля с путями на диске
+// и прокси: такой файл не должен писать в локальные файлы и направлять
+// запросы задач вместе с учетными данными прокси на чужой сервер.
+func checkRemote(file taskFile) error {
+	if file.Defaults != nil {
+		if err := checkRemoteTask(*file.Defaults); err != nil {
+			return fmt.Errorf("Defaults: %w", err)
+		}
+	}
+	if file.Template != nil {
+		if err := checkRemoteTask(*file.Template); err != nil {
+			return fmt.Errorf("Template: %w", err)
+		}
+	}
+	for i, task := range file.Tasks {
+		if err := checkRemoteTask(task); err != nil {
+			return fmt.Errorf("task %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func checkRemoteTask(task Task) error {
+	switch {
+	case task.HAR != "":
+		return errors.New("HAR is not allowed in a remote config")
+	case task.Proxy != "":
+		return errors.New("Proxy is not allowed in a remote config")
+	}
+	return nil
+}
+
+// IncludedFiles возвращает все локальные файлы конфига path: сами файлы
+// задач, подключенные через Include, в том числе файлы без задач,
+// и списки URL. Файл, который не удалось прочитать или разобрать,
+// попадает в список, но его Include не обходятся.
+func IncludedFiles(path string) ([]string, error) {
+	files, err := ConfigFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var included []string
+	for _, file := range files {
+		included = walkIncludes(file, seen, included)
+	}
+	return included, nil
+}
+
+// walkIncludes добавляет к files файл filePath и файлы, которые он подключает.
+func walkIncludes(filePath string, seen map[string]bool, files []string) []string {
+	abs, err := canonicalPath(filePath)
+	if err != nil || seen[abs] {
+		return files
+	}
+	seen[abs] = true
+	if !isRemote(filePath) {
+		files = append(files, abs)
+	}
+
+	loader, err := NewLoaderFor(filePath)
+	if err != nil {
+		return files
+	}
+	parser, ok := loader.(fileParser)
+	if !ok {
+		return files
+	}
+	data, err := readConfig(filePath)
+	if err != nil {
+		return files
+	}
+	file, err := parser.parse(data)
+	if err != nil {
+		return files
+	}
+	for _, include := range file.Include {
+		if include, err := resolvePath(filePath, include); err == nil {
+			files = walkIncludes(include, seen, files)
+		}
+	}
+	if file.URLs != nil {
+		if list, err := resolvePath(filePath, file.URLs.File); err == nil && !isRemote(list) {
+			if abs, err := canonicalPath(list); err == nil && !seen[abs] {
+				seen[abs] = true
+				files = append(files, abs)
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,27 @@
+package taskconfig
+
+import "fmt"
+
+// NewLoaderFor выбирает лоадер по расширению файла конфигурации:
+// .json, .yaml/.yml, .toml или .csv. Путь может быть http(s) URL,
+// тогда файл загружается через DefaultFetcher, а также каталогом
+// или glob-шаблоном, см. MultiLoader.
+func NewLoaderFor(filePath string) (ConfigLoader, error) {
+	if isPattern(filePath) || isDir(filePath) {
+		return NewMultiLoader(), nil
+	}
+	switch ext := configExt(filePath); ext {
+	case ".json":
+		return NewJSONLoader(), nil
+	case ".yaml", ".yml":
+		return NewYAMLLoader(), nil
+	case ".toml":
+		return NewTOMLLoader(), nil
+	case ".csv":
+		return NewCSVLoader(), nil
+	case "":
+		return nil, fmt.Errorf("config file %s has no extension, expected .json, .yaml, .yml, .toml or .csv", filePath)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %s, expected .json, .yaml, .yml, .toml or .csv", ext)
+	}
+}
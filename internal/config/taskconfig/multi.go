@@ -0,0 +1,100 @@
+package taskconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MultiLoader загружает все файлы задач из каталога или по glob-шаблону
+// ("configs/*.json") и объединяет их задачи. Из каталога берутся файлы
+// .json, .yaml, .yml и .toml без обхода подкаталогов, чтобы подключаемые
+// файлы и списки URL можно было держать рядом в подкаталогах. Ошибки
+// загрузки собираются по всем файлам сразу.
+type MultiLoader struct{}
+
+func NewMultiLoader() *MultiLoader {
+	return &MultiLoader{}
+}
+
+func (m *MultiLoader) Load(pattern string) ([]Task, error) {
+	files, err := ConfigFiles(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tasks []Task
+		errs  []error
+	)
+	for _, file := range files {
+		loader, err := NewLoaderFor(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		loaded, err := loader.Load(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		tasks = append(tasks, loaded...)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// isPattern сообщает, что путь к конфигу - glob-шаблон.
+func isPattern(path string) bool {
+	return !isRemote(path) && strings.ContainsAny(path, "*?[")
+}
+
+// isDir сообщает, что путь к конфигу - каталог.
+func isDir(path string) bool {
+	if isRemote(path) {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// dirExts - расширения файлов, которые загружаются из каталога.
+var dirExts = []string{".json", ".yaml", ".yml", ".toml"}
+
+// ConfigFiles возвращает файлы задач, на которые указывает путь: все файлы
+// каталога, совпадения glob-шаблона или сам путь.
+func ConfigFiles(path string) ([]string, error) {
+	switch {
+	case isPattern(path):
+		files, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config pattern %s: %w", path, err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no config files match %s", path)
+		}
+		return files, nil
+	case isDir(path):
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config directory: %w", err)
+		}
+		var files []string
+		for _, e := range entries {
+			if !e.IsDir() && slices.Contains(dirExts, configExt(e.Name())) {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no config files in %s", path)
+		}
+		return files, nil
+	default:
+		return []string{path}, nil
+	}
+}
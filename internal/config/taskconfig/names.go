@@ -0,0 +1,52 @@
+package taskconfig
+
+import (
+	"fmt"
+	"path"
+)
+
+// FilterByName оставляет задачи, имя или ID которых подходит под один
+// из шаблонов only (пустой only пропускает все задачи) и не подходит
+// ни под один из skip. Шаблон - точное имя или glob: "news-*", "t?".
+func FilterByName(tasks []Task, only, skip []string) ([]Task, error) {
+	for _, pattern := range append(append([]string(nil), only...), skip...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid task name pattern %q: %w", pattern, err)
+		}
+	}
+	if len(only) == 0 && len(skip) == 0 {
+		return tasks, nil
+	}
+
+	filtered := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if len(only) > 0 && !matchesName(task, only) {
+			continue
+		}
+		if matchesName(task, skip) {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+	return filtered, nil
+}
+
+// matchesName сообщает, подходит ли задача под один из шаблонов.
+// Имена с квадратными скобками сравниваются и как есть, чтобы их
+// не приходилось экранировать.
+func matchesName(task Task, patterns []string) bool {
+	for _, pattern := range patterns {
+		for _, name := range []string{task.Name, task.ID} {
+			if name == "" {
+				continue
+			}
+			if name == pattern {
+				return true
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package taskconfig
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Parse разбирает задачи, переданные не файлом, а данными, например
+// в запросе к API. data - JSON одной задачи, массива задач или объекта
+// с Tasks и Defaults, как в файле задач. Include и URLs не поддерживаются,
+// потому что читают другие файлы. source указывается в ошибках и в Source задач.
+func Parse(source string, data []byte) ([]Task, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tasks: %w", err)
+	}
+	// Одна задача - это объект с URL, а не с Tasks
+	if m, ok := raw.(map[string]interface{}); ok {
+		if _, ok := m["URL"]; ok {
+			raw = []interface{}{m}
+		}
+	}
+	file, err := decodeRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Include) > 0 || file.URLs != nil {
+		return nil, fmt.Errorf("%s: Include and URLs are only supported in task files", source)
+	}
+
+	var tasks []Task
+	for i, task := range file.Tasks {
+		if file.Defaults != nil {
+			if task, err = applyDefaults(*file.Defaults, task); err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", source, i, err)
+			}
+		}
+		task.Source, task.Index = source, i
+		expanded, err := expandTemplate(task)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", source, i, err)
+		}
+		tasks = append(tasks, expanded...)
+	}
+	return tasks, nil
+}
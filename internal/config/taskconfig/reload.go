@@ -0,0 +1,195 @@
+package taskconfig
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce - пауза после последнего изменения файла перед
+// перезагрузкой: редакторы сохраняют файл в несколько операций.
+const reloadDebounce = 200 * time.Millisecond
+
+// Reloader держит актуальный набор задач для долгоживущих режимов и целиком
+// заменяет его, когда меняются файлы конфига. Набор, уже полученный через
+// Tasks, не меняется, поэтому перезагрузка не затрагивает выполняющиеся задачи.
+type Reloader struct {
+	path  string
+	load  func(path string) ([]Task, error)
+	tasks atomic.Pointer[[]Task]
+
+	// OnReload вызывается после замены набора задач.
+	OnReload func(tasks []Task)
+	// OnError вызывается, если конфиг не удалось перечитать. Прежний набор
+	// задач при этом остается.
+	OnError func(err error)
+	// PollInterval - как часто перечитывать конфиг, загруженный по URL.
+	PollInterval time.Duration
+}
+
+// NewReloader загружает конфиг функцией load. Та же функция используется
+// при перезагрузке, поэтому в ней должны быть все проверки конфига.
+func NewReloader(path string, load func(path string) ([]Task, error)) (*Reloader, error) {
+	r := &Reloader{path: path, load: load, PollInterval: time.Minute}
+	tasks, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	r.tasks.Store(&tasks)
+	return r, nil
+}
+
+// Tasks возвращает текущий набор задач.
+func (r *Reloader) Tasks() []Task {
+	return *r.tasks.Load()
+}
+
+// Watch следит за изменениями конфига, пока не отменен ctx. Локальные
+// файлы, включая подключенные через Include и списки URL, отслеживаются
+// через fsnotify, конфиг по URL перечитывается раз в PollInterval.
+func (r *Reloader) Watch(ctx context.Context) error {
+	if isRemote(r.path) {
+		return r.poll(ctx)
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer w.Close()
+
+	files, err := r.watchFiles(w)
+	if err != nil {
+		return err
+	}
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if abs, err := filepath.Abs(ev.Name); err == nil && (files[abs] || r.newConfigFile(abs)) && !ev.Has(fsnotify.Chmod) {
+				debounce.Reset(reloadDebounce)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			r.fail(fmt.Errorf("config watcher: %w", err))
+		case <-debounce.C:
+			r.reload()
+			// После перезагрузки могли появиться новые подключенные файлы.
+			if files, err = r.watchFiles(w); err != nil {
+				r.fail(err)
+			}
+		}
+	}
+}
+
+// poll периодически перечитывает удаленный конфиг.
+func (r *Reloader) poll(ctx context.Context) error {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reload()
+		}
+	}
+}
+
+// reload перечитывает конфиг и заменяет набор задач, если он изменился.
+func (r *Reloader) reload() {
+	tasks, err := r.load(r.path)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	if reflect.DeepEqual(tasks, r.Tasks()) {
+		return
+	}
+	r.tasks.Store(&tasks)
+	if r.OnReload != nil {
+		r.OnReload(tasks)
+	}
+}
+
+func (r *Reloader) fail(err error) {
+	if r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// watchFiles подписывается на каталоги всех локальных файлов конфига,
+// включая весь граф Include, и возвращает множество их абсолютных путей. Следить приходится
+// за каталогами: многие редакторы сохраняют файл через переименование.
+func (r *Reloader) watchFiles(w *fsnotify.Watcher) (map[string]bool, error) {
+	files := make(map[string]bool)
+	add := func(path string) error {
+		if isRemote(path) {
+			return nil
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		if files[abs] {
+			return nil
+		}
+		files[abs] = true
+		if err := w.Add(filepath.Dir(abs)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	}
+
+	if isDir(r.path) {
+		// В каталоге следим и за новыми файлами, см. newConfigFile.
+		if err := w.Add(r.path); err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", r.path, err)
+		}
+	} else if err := add(r.path); err != nil {
+		return nil, err
+	}
+	included, err := IncludedFiles(r.path)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range included {
+		if err := add(path); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// newConfigFile сообщает, что файл попадает в каталог или glob-шаблон
+// конфига, даже если его не было при последней загрузке.
+func (r *Reloader) newConfigFile(abs string) bool {
+	pattern, err := filepath.Abs(r.path)
+	if err != nil {
+		return false
+	}
+	switch {
+	case isPattern(r.path):
+		ok, _ := filepath.Match(pattern, abs)
+		return ok
+	case isDir(r.path):
+		return filepath.Dir(abs) == pattern && slices.Contains(dirExts, configExt(abs))
+	default:
+		return false
+	}
+}
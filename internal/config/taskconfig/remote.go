@@ -0,0 +1,204 @@
+package taskconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Fetcher загружает файлы задач по HTTP(S). Если задан CacheDir, ответы
+// кешируются вместе с ETag, и повторная загрузка неизмененного файла
+// обходится ответом 304.
+type Fetcher struct {
+	Client *http.Client
+	// Header добавляется к запросам к HeaderOrigin (scheme://host),
+	// например для авторизации. Файлы с других хостов загружаются без него,
+	// чтобы подключенный конфиг не получил чужой токен.
+	Header       http.Header
+	HeaderOrigin string
+	// CacheDir - каталог кеша. Пустое значение отключает кеш.
+	CacheDir string
+}
+
+// maxConfigSize - наибольший размер загружаемого файла задач.
+const maxConfigSize = 10 << 20
+
+// DefaultFetcher используется лоадерами для путей вида http(s)://.
+var DefaultFetcher = &Fetcher{Client: &http.Client{Timeout: 30 * time.Second}}
+
+// Fetch загружает файл по URL.
+func (f *Fetcher) Fetch(rawURL string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if f.HeaderOrigin != "" && Origin(rawURL) == f.HeaderOrigin {
+		for name, values := range f.Header {
+			for _, v := range values {
+				req.Header.Add(name, v)
+			}
+		}
+	}
+
+	var bodyPath, etagPath string
+	if f.CacheDir != "" {
+		sum := sha256.Sum256([]byte(rawURL))
+		name := hex.EncodeToString(sum[:8])
+		bodyPath = filepath.Join(f.CacheDir, name+".body")
+		etagPath = filepath.Join(f.CacheDir, name+".etag")
+		if etag, err := os.ReadFile(etagPath); err == nil {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && bodyPath != "":
+		data, err := os.ReadFile(bodyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cached config: %w", err)
+		}
+		return data, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch config: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxConfigSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config response: %w", err)
+	}
+	if len(data) > maxConfigSize {
+		return nil, fmt.Errorf("config response exceeds %d bytes", maxConfigSize)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && bodyPath != "" {
+		if err := f.store(bodyPath, etagPath, data, etag); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// store сохраняет ответ и его ETag в кеш.
+func (f *Fetcher) store(bodyPath, etagPath string, data []byte, etag string) error {
+	if err := os.MkdirAll(f.CacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create config cache: %w", err)
+	}
+	if err := os.WriteFile(bodyPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to cache config: %w", err)
+	}
+	if err := os.WriteFile(etagPath, []byte(etag), 0o644); err != nil {
+		return fmt.Errorf("failed to cache config: %w", err)
+	}
+	return nil
+}
+
+// isRemote сообщает, что путь к файлу задач - URL.
+func isRemote(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// Origin возвращает scheme://host адреса файла задач или пустую строку
+// для локального пути.
+func Origin(p string) string {
+	if !isRemote(p) {
+		return ""
+	}
+	u, err := url.Parse(p)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// readConfig читает файл задач с диска или по HTTP.
+func readConfig(p string) ([]byte, error) {
+	if isRemote(p) {
+		return DefaultFetcher.Fetch(p)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return data, nil
+}
+
+// resolvePath вычисляет путь ref, указанный внутри файла base: относительные
+// пути считаются от каталога base. Пути удаленного файла считаются от его
+// URL и не могут указывать на локальные файлы.
+func resolvePath(base, ref string) (string, error) {
+	if !isRemote(base) {
+		if isRemote(ref) || filepath.IsAbs(ref) {
+			return ref, nil
+		}
+		return filepath.Join(filepath.Dir(base), ref), nil
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse config url: %w", err)
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse include path: %w", err)
+	}
+	resolved := b.ResolveReference(r).String()
+	if !isRemote(resolved) {
+		return "", fmt.Errorf("remote config cannot refer to local file %s", ref)
+	}
+	return resolved, nil
+}
+
+// canonicalPath возвращает путь, по которому один и тот же файл узнается
+// при обходе Include.
+func canonicalPath(p string) (string, error) {
+	if isRemote(p) {
+		return p, nil
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	return abs, nil
+}
+
+// configExt возвращает расширение файла задач в нижнем регистре. У URL
+// учитывается только путь, без параметров запроса.
+func configExt(p string) string {
+	if isRemote(p) {
+		u, err := url.Parse(p)
+		if err != nil {
+			return ""
+		}
+		return strings.ToLower(path.Ext(u.Path))
+	}
+	return strings.ToLower(filepath.Ext(p))
+}
+
+// ParseHeader разбирает заголовок вида "Name: value". Значение может быть
+// ссылкой на секрет, см. ResolveSecret.
+func ParseHeader(s string) (http.Header, error) {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return nil, errors.New(`header must look like "Name: value"`)
+	}
+	value, err := ResolveSecret(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	h := http.Header{}
+	h.Set(strings.TrimSpace(name), value)
+	return h, nil
+}
@@ -0,0 +1,98 @@
+package taskconfig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetcherHeaderOrigin(t *testing.T) {
+	got := make(map[string]string)
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			got[name] = r.Header.Get("Authorization")
+			w.Write([]byte("[]"))
+		}
+	}
+	origin := httptest.NewServer(handler("origin"))
+	defer origin.Close()
+	other := httptest.NewServer(handler("other"))
+	defer other.Close()
+
+	f := &Fetcher{
+		Client:       http.DefaultClient,
+		Header:       http.Header{"Authorization": {"Bearer tok"}},
+		HeaderOrigin: Origin(origin.URL + "/tasks.json"),
+	}
+	for _, u := range []string{origin.URL + "/tasks.json", other.URL + "/tasks.json"} {
+		if _, err := f.Fetch(u); err != nil {
+			t.Fatalf("Fetch(%s): %v", u, err)
+		}
+	}
+	if got["origin"] != "Bearer tok" {
+		t.Errorf("header sent to the config origin = %q, want %q", got["origin"], "Bearer tok")
+	}
+	if got["other"] != "" {
+		t.Errorf("header sent to another origin = %q, want none", got["other"])
+	}
+}
+
+func TestRemoteConfig(t *testing.T) {
+	files := map[string]string{
+		"/ok.json":            `{"Include": ["common/news.json"], "Tasks": [{"URL": "https://example.com/a"}]}`,
+		"/common/news.json":   `[{"URL": "https://example.com/news"}]`,
+		"/har.json":           `[{"URL": "https://example.com", "HAR": "/tmp/x.har"}]`,
+		"/defaults-har.json":  `{"Defaults": {"HAR": "/tmp"}, "Tasks": [{"URL": "https://example.com"}]}`,
+		"/include-local.json": `{"Include": ["file:///etc/tasks.json"], "Tasks": []}`,
+		"/proxy.json":         `{"Defaults": {"Proxy": "http://proxy.example:3128"}, "Tasks": [{"URL": "https://example.com"}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(data))
+	}))
+	defer srv.Close()
+	fetcher := DefaultFetcher
+	DefaultFetcher = &Fetcher{Client: srv.Client()}
+	t.Cleanup(func() { DefaultFetcher = fetcher })
+
+	tasks, err := NewJSONLoader().Load(srv.URL + "/ok.json")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].Source != srv.URL+"/common/news.json" || tasks[1].Source != srv.URL+"/ok.json" {
+		t.Errorf("tasks = %+v, want the included task first, with remote sources", tasks)
+	}
+
+	for path, want := range map[string]string{
+		"/har.json":           "HAR is not allowed",
+		"/defaults-har.json":  "Defaults: HAR is not allowed",
+		"/include-local.json": "cannot refer to local file",
+		"/proxy.json":         "Defaults: Proxy is not allowed",
+	} {
+		_, err := NewJSONLoader().Load(srv.URL + path)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load(%s) error = %v, want %q", path, err, want)
+		}
+	}
+}
+
+func TestLocalConfigAllowsHAR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if err := os.WriteFile(path, []byte(`[{"URL": "https://example.com", "HAR": "out.har"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := NewJSONLoader().Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].HAR != "out.har" {
+		t.Errorf("tasks = %+v, want one task with its HAR path", tasks)
+	}
+}
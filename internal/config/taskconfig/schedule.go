@@ -0,0 +1,35 @@
+package taskconfig
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Политики запуска по расписанию, пока выполняется прошлый запуск.
+const (
+	OverlapSkip  = "skip"
+	OverlapQueue = "queue"
+)
+
+// scheduleParser понимает стандартные cron-выражения из пяти полей,
+// дескрипторы вроде "@daily" и "@every 15m", а также префикс "CRON_TZ=<зона>".
+var scheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseSchedule разбирает расписание задачи из поля Schedule.
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	schedule, err := scheduleParser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// CheckOverlap проверяет политику из поля Overlap.
+func CheckOverlap(overlap string) error {
+	switch overlap {
+	case "", OverlapSkip, OverlapQueue:
+		return nil
+	}
+	return fmt.Errorf("unknown Overlap %q, expected %s or %s", overlap, OverlapSkip, OverlapQueue)
+}
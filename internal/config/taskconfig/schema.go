@@ -0,0 +1,159 @@
+package taskconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"github.com/invopop/jsonschema"
+	validator "github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaURL - идентификатор схемы файла задач.
+const schemaURL = "https://github.com/rx3lixir/ish3ikin/tasks.schema.json"
+
+// Schema возвращает JSON Schema файла задач. Схема строится по структуре
+// Task, поэтому всегда совпадает с тем, что понимают лоадеры. Неизвестные
+// поля схема запрещает. Файл - либо список задач, либо объект с Include и Tasks.
+func Schema() ([]byte, error) {
+	r := &jsonschema.Reflector{RequiredFromJSONSchemaTags: true}
+	file := r.Reflect(&taskFile{})
+	s := &jsonschema.Schema{
+		Version:     file.Version,
+		ID:          schemaURL,
+		Title:       "ish3ikin tasks",
+		Definitions: file.Definitions,
+		OneOf: []*jsonschema.Schema{
+			{Type: "array", Items: &jsonschema.Schema{Ref: "#/$defs/Task"}},
+			{Ref: file.Ref},
+		},
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// JSONSchema описывает три формы записи селектора: строку, массив
+// запасных селекторов и объект.
+func (Selector) JSONSchema() *jsonschema.Schema {
+	props := jsonschema.NewProperties()
+	props.Set("Selector", &jsonschema.Schema{Type: "string"})
+	props.Set("Fallbacks", &jsonschema.Schema{Type: "array", Items: &jsonschema.Schema{Type: "string"}})
+	props.Set("Type", &jsonschema.Schema{Type: "string", Enum: []interface{}{FieldText, FieldCount, FieldExists}})
+	props.Set("Regex", &jsonschema.Schema{Type: "string"})
+
+	return &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{Type: "string"},
+			{Type: "array", Items: &jsonschema.Schema{Type: "string"}, MinItems: ptr(uint64(1))},
+			{Type: "object", Properties: props, AdditionalProperties: jsonschema.FalseSchema},
+		},
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// CheckSchema проверяет файл задач по схеме. YAML и TOML проверяются после
+// перевода в JSON; CSV состоит из фиксированных колонок и не проверяется.
+func CheckSchema(filePath string) error {
+	schema, err := Schema()
+	if err != nil {
+		return fmt.Errorf("failed to build schema: %w", err)
+	}
+	doc, err := validator.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	c := validator.NewCompiler()
+	if err := c.AddResource(schemaURL, doc); err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+	sch, err := c.Compile(schemaURL)
+	if err != nil {
+		return fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	return checkDocument(sch, filePath, make(map[string]bool))
+}
+
+// checkDocument проверяет файл и все файлы из его Include. seen защищает
+// от повторной проверки и циклов; о циклах сообщает лоадер.
+func checkDocument(sch *validator.Schema, filePath string, seen map[string]bool) error {
+	abs, err := canonicalPath(filePath)
+	if err != nil {
+		return err
+	}
+	if seen[abs] {
+		return nil
+	}
+	seen[abs] = true
+
+	inst, err := loadDocument(filePath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+	if inst == nil {
+		return nil
+	}
+	if inst, _, err = migrate(inst); err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+	if err := sch.Validate(inst); err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	doc, _ := inst.(map[string]interface{})
+	includes, _ := doc["Include"].([]interface{})
+	for _, include := range includes {
+		ref, _ := include.(string)
+		path, err := resolvePath(filePath, ref)
+		if err != nil {
+			return err
+		}
+		if err := checkDocument(sch, path, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDocument читает файл задач как JSON-документ для проверки по схеме.
+// Для CSV возвращает nil.
+func loadDocument(filePath string) (interface{}, error) {
+	data, err := readConfig(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	switch configExt(filePath) {
+	case ".json":
+		return parseDocument(data)
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	data, err = json.Marshal(normalize(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert config: %w", err)
+	}
+	return parseDocument(data)
+}
+
+func parseDocument(data []byte) (interface{}, error) {
+	doc, err := validator.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,117 @@
+package taskconfig
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+)
+
+// ResolveSecret раскрывает ссылку на секрет: "env:NAME" - значение переменной
+// окружения, "file:/path" - содержимое файла без завершающего перевода строки.
+// Остальные значения возвращаются как есть.
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(value, "file:"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return value, nil
+	}
+}
+
+// ResolveSecrets раскрывает ссылки на секреты в заголовках и учетных данных задач.
+// Задачам из файлов, загруженных по HTTP, ссылки запрещены: иначе сервер
+// конфига мог бы прочитать файлы и окружение машины запуска.
+func ResolveSecrets(tasks []Task) error {
+	for i := range tasks {
+		t := &tasks[i]
+		resolve := ResolveSecret
+		if isRemote(t.Source) {
+			resolve = refuseSecret
+		}
+		var err error
+		for name, value := range t.Headers {
+			if t.Headers[name], err = resolve(value); err != nil {
+				return fmt.Errorf("task %d: header %s: %w", i+1, name, err)
+			}
+		}
+		if t.Auth != nil {
+			for _, field := range []*string{&t.Auth.Username, &t.Auth.Password, &t.Auth.Token} {
+				if *field, err = resolve(*field); err != nil {
+					return fmt.Errorf("task %d: auth: %w", i+1, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// refuseSecret возвращает значение как есть, если оно не ссылка на секрет.
+func refuseSecret(value string) (string, error) {
+	if strings.HasPrefix(value, "env:") || strings.HasPrefix(value, "file:") {
+		return "", errors.New("secret references are not allowed in a remote config")
+	}
+	return value, nil
+}
+
+// Refs - URL, заголовки и учетные данные задачи в том виде, в каком они
+// заданы в конфиге: со ссылками ${VAR}, "env:" и "file:".
+type Refs struct {
+	URL     string
+	Headers map[string]string
+	Auth    *Auth
+}
+
+// KeepRefs запоминает у задач значения до ExpandEnv и ResolveSecrets, чтобы
+// передавать задачи другим узлам без раскрытых секретов, см. Unresolved.
+func KeepRefs(tasks []Task) {
+	for i := range tasks {
+		t := &tasks[i]
+		t.Refs = &Refs{URL: t.URL, Headers: maps.Clone(t.Headers), Auth: cloneAuth(t.Auth)}
+	}
+}
+
+// Unresolved возвращает задачу со значениями, запомненными KeepRefs.
+// Получатель раскрывает ссылки сам, см. Resolve.
+func (t Task) Unresolved() Task {
+	if t.Refs == nil {
+		return t
+	}
+	t.URL, t.Headers, t.Auth = t.Refs.URL, maps.Clone(t.Refs.Headers), cloneAuth(t.Refs.Auth)
+	t.Refs = nil
+	return t
+}
+
+// Resolve подставляет в задачу, полученную со ссылками, например из очереди,
+// переменные окружения и секреты этой машины.
+func Resolve(task Task) (Task, error) {
+	task.Headers, task.Auth = maps.Clone(task.Headers), cloneAuth(task.Auth)
+	tasks := []Task{task}
+	if err := ExpandEnv(tasks); err != nil {
+		return Task{}, err
+	}
+	if err := ResolveSecrets(tasks); err != nil {
+		return Task{}, err
+	}
+	return tasks[0], nil
+}
+
+func cloneAuth(auth *Auth) *Auth {
+	if auth == nil {
+		return nil
+	}
+	c := *auth
+	return &c
+}
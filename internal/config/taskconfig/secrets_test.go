@@ -0,0 +1,99 @@
+package taskconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReferencesInRemoteConfig(t *testing.T) {
+	t.Setenv("ISH3IKIN_TEST_SECRET", "s3cret")
+	secretFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secretFile, []byte("tok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		task Task
+		// get возвращает проверяемое значение задачи после подстановки.
+		get  func(Task) string
+		want string
+		// refused - ссылка, которую удаленный конфиг задавать не может.
+		refused bool
+	}{
+		{
+			name:    "env var in URL",
+			task:    Task{URL: "https://example.com/?key=${ISH3IKIN_TEST_SECRET}"},
+			get:     func(t Task) string { return t.URL },
+			want:    "https://example.com/?key=s3cret",
+			refused: true,
+		},
+		{
+			name:    "env var in header",
+			task:    Task{URL: "https://example.com", Headers: map[string]string{"X-Key": "${ISH3IKIN_TEST_SECRET}"}},
+			get:     func(t Task) string { return t.Headers["X-Key"] },
+			want:    "s3cret",
+			refused: true,
+		},
+		{
+			name:    "env secret in header",
+			task:    Task{URL: "https://example.com", Headers: map[string]string{"X-Key": "env:ISH3IKIN_TEST_SECRET"}},
+			get:     func(t Task) string { return t.Headers["X-Key"] },
+			want:    "s3cret",
+			refused: true,
+		},
+		{
+			name:    "file secret in auth",
+			task:    Task{URL: "https://example.com", Auth: &Auth{Token: "file:" + secretFile}},
+			get:     func(t Task) string { return t.Auth.Token },
+			want:    "tok",
+			refused: true,
+		},
+		{
+			name:    "env secret in password",
+			task:    Task{URL: "https://example.com", Auth: &Auth{Username: "bob", Password: "env:ISH3IKIN_TEST_SECRET"}},
+			get:     func(t Task) string { return t.Auth.Password },
+			want:    "s3cret",
+			refused: true,
+		},
+		{
+			name: "plain values",
+			task: Task{URL: "https://example.com/$price", Headers: map[string]string{"X-Key": "plain"}, Auth: &Auth{Token: "tok"}},
+			get:  func(t Task) string { return t.URL + " " + t.Headers["X-Key"] + " " + t.Auth.Token },
+			want: "https://example.com/$price plain tok",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, source := range []string{"tasks.json", "https://config.example/tasks.json"} {
+				task := tt.task
+				task.Source = source
+				got, err := Resolve(task)
+				if isRemote(source) && tt.refused {
+					if err == nil {
+						t.Errorf("%s: references resolved to %q, want an error", source, tt.get(got))
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", source, err)
+				}
+				if v := tt.get(got); v != tt.want {
+					t.Errorf("%s: got %q, want %q", source, v, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveSecretMissing(t *testing.T) {
+	for _, value := range []string{"env:ISH3IKIN_TEST_UNSET", "file:" + filepath.Join(t.TempDir(), "missing")} {
+		if _, err := ResolveSecret(value); err == nil {
+			t.Errorf("ResolveSecret(%q) succeeded, want an error", value)
+		}
+	}
+	if _, err := ExpandString("${ISH3IKIN_TEST_UNSET}"); err == nil {
+		t.Error("ExpandString with an unset variable succeeded, want an error")
+	}
+}
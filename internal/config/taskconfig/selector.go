@@ -0,0 +1,112 @@
+package taskconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Типы полей.
+const (
+	// FieldText извлекает текст всех найденных элементов (по умолчанию).
+	FieldText = "text"
+	// FieldCount возвращает количество найденных элементов.
+	FieldCount = "count"
+	// FieldExists возвращает "true" или "false" в зависимости от наличия элементов.
+	FieldExists = "exists"
+)
+
+// Selector описывает правило извлечения одного поля.
+// В конфиге задается либо строкой с CSS-селектором, либо объектом:
+//
+//	"Price": {"Selector": "div.price", "Regex": "([\\d\\s]+,\\d{2})"}
+//
+// Для полей-счетчиков есть сокращенная запись "count(.item)" и "exists(.buy-button)",
+// а цепочку запасных селекторов можно задать массивом строк: [".price-new", ".price"].
+type Selector struct {
+	Selector string `json:"Selector"`
+	// Fallbacks перебираются по порядку, если основной селектор ничего не нашел.
+	Fallbacks []string `json:"Fallbacks,omitempty"`
+	// Type - тип поля: FieldText, FieldCount или FieldExists. Пустое значение равно FieldText.
+	Type string `json:"Type,omitempty"`
+	// Regex применяется к тексту каждого найденного элемента; если в выражении
+	// есть группа захвата, в результат попадает первая группа.
+	Regex string `json:"Regex,omitempty"`
+}
+
+// selectorFields нужен, чтобы разобрать объектную форму без рекурсии в UnmarshalJSON.
+type selectorFields Selector
+
+func (s *Selector) UnmarshalJSON(data []byte) error {
+	var css string
+	if err := json.Unmarshal(data, &css); err == nil {
+		*s = ParseSelector(css)
+		return nil
+	}
+
+	var chain []string
+	if err := json.Unmarshal(data, &chain); err == nil {
+		if len(chain) == 0 {
+			return fmt.Errorf("selector chain must not be empty")
+		}
+		*s = Selector{Selector: chain[0], Fallbacks: chain[1:]}
+		return nil
+	}
+
+	var fields selectorFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("selector must be a string, an array or an object: %w", err)
+	}
+	*s = Selector(fields)
+	return nil
+}
+
+func (s Selector) MarshalJSON() ([]byte, error) {
+	if s.Regex != "" {
+		return json.Marshal(selectorFields(s))
+	}
+
+	switch s.Kind() {
+	case FieldText:
+		if len(s.Fallbacks) > 0 {
+			return json.Marshal(s.Candidates())
+		}
+		return json.Marshal(s.Selector)
+	case FieldCount, FieldExists:
+		if len(s.Fallbacks) == 0 {
+			return json.Marshal(s.Type + "(" + s.Selector + ")")
+		}
+	}
+	return json.Marshal(selectorFields(s))
+}
+
+// Candidates возвращает непустые селекторы в порядке перебора: основной, затем запасные.
+func (s Selector) Candidates() []string {
+	candidates := make([]string, 0, len(s.Fallbacks)+1)
+	for _, css := range append([]string{s.Selector}, s.Fallbacks...) {
+		if css != "" {
+			candidates = append(candidates, css)
+		}
+	}
+	return candidates
+}
+
+// Kind возвращает тип поля с учетом значения по умолчанию.
+func (s Selector) Kind() string {
+	if s.Type == "" {
+		return FieldText
+	}
+	return s.Type
+}
+
+// ParseSelector разбирает строковую форму селектора, включая "count(...)" и "exists(...)".
+func ParseSelector(value string) Selector {
+	trimmed := strings.TrimSpace(value)
+	for _, kind := range []string{FieldCount, FieldExists} {
+		if strings.HasPrefix(trimmed, kind+"(") && strings.HasSuffix(trimmed, ")") {
+			inner := trimmed[len(kind)+1 : len(trimmed)-1]
+			return Selector{Selector: strings.TrimSpace(inner), Type: kind}
+		}
+	}
+	return Selector{Selector: value}
+}
@@ -0,0 +1,21 @@
+package taskconfig
+
+// Stealth управляет маскировкой автоматизации и отпечатком браузера.
+type Stealth struct {
+	// Enabled включает патчи go-rod/stealth. По умолчанию включено.
+	Enabled *bool `json:"Enabled,omitempty"`
+	// UserAgent подменяет User-Agent и navigator.userAgent.
+	UserAgent string `json:"UserAgent,omitempty"`
+	// Platform подменяет navigator.platform, например "Win32".
+	Platform string `json:"Platform,omitempty"`
+	// Languages подменяет navigator.languages.
+	Languages []string `json:"Languages,omitempty"`
+	// WebGLVendor и WebGLRenderer подменяют UNMASKED_VENDOR/RENDERER_WEBGL.
+	WebGLVendor   string `json:"WebGLVendor,omitempty"`
+	WebGLRenderer string `json:"WebGLRenderer,omitempty"`
+}
+
+// On сообщает, нужно ли применять патчи stealth. Отсутствие настройки означает "да".
+func (s *Stealth) On() bool {
+	return s == nil || s.Enabled == nil || *s.Enabled
+}
@@ -0,0 +1,47 @@
+package taskconfig
+
+// Типы шагов сценария.
+const (
+	// StepNavigate открывает URL.
+	StepNavigate = "navigate"
+	// StepClick нажимает на элемент.
+	StepClick = "click"
+	// StepWait делает паузу на WaitMs миллисекунд.
+	StepWait = "wait"
+	// StepExtract извлекает поля Fields в текущую запись и в переменные.
+	StepExtract = "extract"
+	// StepForEach выполняет вложенные шаги для каждого найденного элемента.
+	StepForEach = "foreach"
+)
+
+// Step - шаг многошагового сценария. В URL и Selector можно подставлять
+// переменные в виде {{имя}}: значения полей, извлеченных ранее, элемент
+// текущей итерации foreach и {{URL}} - адрес задачи.
+//
+// Пример "открыть список, зайти в каждую карточку, извлечь поля":
+//
+//	"Steps": [
+//	  {"Type": "foreach", "Selector": "a.card", "As": "link", "Steps": [
+//	    {"Type": "navigate", "URL": "{{link}}"},
+//	    {"Type": "extract", "Fields": {"Title": "h1", "Price": ".price"}}
+//	  ]}
+//	]
+//
+// Шаги "hover" и "move" работают так же, как одноименные Actions.
+type Step struct {
+	Type     string `json:"Type"`
+	URL      string `json:"URL,omitempty"`
+	Selector string `json:"Selector,omitempty"`
+	// Navigates говорит, что клик открывает новую страницу и нужно дождаться ее загрузки.
+	Navigates bool                `json:"Navigates,omitempty"`
+	Fields    map[string]Selector `json:"Fields,omitempty"`
+	// Attribute - что брать из элемента для foreach: "href" (по умолчанию),
+	// "src", "text" или имя любого другого атрибута.
+	Attribute string `json:"Attribute,omitempty"`
+	// As - имя переменной с элементом итерации, по умолчанию "item".
+	As string `json:"As,omitempty"`
+	// Limit ограничивает число итераций foreach.
+	Limit  int    `json:"Limit,omitempty"`
+	Steps  []Step `json:"Steps,omitempty"`
+	WaitMs int    `json:"WaitMs,omitempty"`
+}
@@ -0,0 +1,45 @@
+package taskconfig
+
+import (
+	"slices"
+	"strings"
+)
+
+// FilterByTags оставляет задачи, у которых есть хотя бы один тег из include
+// (пустой include пропускает все задачи) и нет ни одного тега из exclude.
+func FilterByTags(tasks []Task, include, exclude []string) []Task {
+	if len(include) == 0 && len(exclude) == 0 {
+		return tasks
+	}
+	filtered := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if len(include) > 0 && !hasAnyTag(task.Tags, include) {
+			continue
+		}
+		if hasAnyTag(task.Tags, exclude) {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+	return filtered
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		if slices.Contains(wanted, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTags разбирает список тегов через запятую, пропуская пустые.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
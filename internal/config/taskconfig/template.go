@@ -0,0 +1,152 @@
+package taskconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxExpansion ограничивает число задач из одного шаблона, чтобы опечатка
+// в диапазоне не породила миллионы задач.
+const maxExpansion = 10000
+
+var (
+	placeholderRe = regexp.MustCompile(`\{([^{}]+)\}`)
+	rangeRe       = regexp.MustCompile(`^(-?\d+)\.\.(-?\d+)(?:\.\.(\d+))?$`)
+	paramNameRe   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// expandTemplate разворачивает URL-шаблон задачи в набор задач. В URL можно
+// использовать диапазоны {1..50} или {0..100..10} и имена {city}, значения
+// которых берутся из Params. Для нескольких подстановок перебираются все
+// сочетания. Значения сохраняются в Vars: имена - как есть, диапазоны - по
+// порядковому номеру в URL ("1", "2", ...). Начальный ноль в диапазоне
+// ({01..12}) задает ширину чисел. Задача без подстановок возвращается как есть.
+func expandTemplate(task Task) ([]Task, error) {
+	var matches [][]string
+	for _, loc := range placeholderRe.FindAllStringSubmatchIndex(task.URL, -1) {
+		// ${VAR} - ссылка на переменную окружения, ее раскрывает ExpandEnv.
+		if loc[0] > 0 && task.URL[loc[0]-1] == '$' {
+			continue
+		}
+		matches = append(matches, []string{task.URL[loc[0]:loc[1]], task.URL[loc[2]:loc[3]]})
+	}
+	if len(matches) == 0 {
+		if len(task.Params) > 0 {
+			return nil, fmt.Errorf("Params are set but URL %q has no placeholders", task.URL)
+		}
+		return []Task{task}, nil
+	}
+
+	type placeholder struct {
+		text   string
+		name   string
+		values []string
+	}
+	var (
+		placeholders []placeholder
+		seen         = make(map[string]bool)
+		ranges       int
+		total        = 1
+	)
+	for _, m := range matches {
+		text, expr := m[0], strings.TrimSpace(m[1])
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+
+		p := placeholder{text: text}
+		switch {
+		case rangeRe.MatchString(expr):
+			values, err := expandRange(expr)
+			if err != nil {
+				return nil, err
+			}
+			ranges++
+			p.name, p.values = strconv.Itoa(ranges), values
+		case paramNameRe.MatchString(expr):
+			values, ok := task.Params[expr]
+			if !ok || len(values) == 0 {
+				return nil, fmt.Errorf("URL placeholder %s has no values in Params", text)
+			}
+			p.name, p.values = expr, values
+		default:
+			return nil, fmt.Errorf("invalid URL placeholder %s", text)
+		}
+		total *= len(p.values)
+		if total > maxExpansion {
+			return nil, fmt.Errorf("URL template %q expands to more than %d tasks", task.URL, maxExpansion)
+		}
+		placeholders = append(placeholders, p)
+	}
+
+	tasks := make([]Task, 0, total)
+	indexes := make([]int, len(placeholders))
+	for n := 1; ; n++ {
+		t := task
+		t.Params = nil
+		t.Vars = make(map[string]string, len(placeholders)+len(task.Vars))
+		for k, v := range task.Vars {
+			t.Vars[k] = v
+		}
+		labels := make([]string, len(placeholders))
+		for i, p := range placeholders {
+			value := p.values[indexes[i]]
+			t.URL = strings.ReplaceAll(t.URL, p.text, value)
+			t.Vars[p.name] = value
+			labels[i] = p.name + "=" + value
+		}
+		if t.Name != "" {
+			t.Name += " [" + strings.Join(labels, " ") + "]"
+		}
+		if t.ID != "" {
+			t.ID += "-" + strconv.Itoa(n)
+		}
+		tasks = append(tasks, t)
+
+		// Следующее сочетание: последняя подстановка меняется быстрее всех.
+		i := len(indexes) - 1
+		for ; i >= 0; i-- {
+			indexes[i]++
+			if indexes[i] < len(placeholders[i].values) {
+				break
+			}
+			indexes[i] = 0
+		}
+		if i < 0 {
+			return tasks, nil
+		}
+	}
+}
+
+// expandRange разворачивает диапазон "a..b" или "a..b..step".
+func expandRange(expr string) ([]string, error) {
+	m := rangeRe.FindStringSubmatch(expr)
+	from, _ := strconv.Atoi(m[1])
+	to, _ := strconv.Atoi(m[2])
+	step := 1
+	if m[3] != "" {
+		step, _ = strconv.Atoi(m[3])
+		if step <= 0 {
+			return nil, fmt.Errorf("range {%s} must have a positive step", expr)
+		}
+	}
+	if from > to {
+		step = -step
+	}
+	if n := (to-from)/step + 1; n > maxExpansion {
+		return nil, fmt.Errorf("range {%s} has more than %d values", expr, maxExpansion)
+	}
+
+	width := 0
+	if len(m[1]) > 1 && strings.HasPrefix(m[1], "0") {
+		width = len(m[1])
+	}
+	var values []string
+	for v := from; (step > 0 && v <= to) || (step < 0 && v >= to); v += step {
+		values = append(values, fmt.Sprintf("%0*d", width, v))
+	}
+	return values, nil
+}
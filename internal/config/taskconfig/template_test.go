@@ -0,0 +1,84 @@
+package taskconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		name  string
+		task  Task
+		urls  []string
+		names []string
+	}{
+		{
+			name: "no placeholders",
+			task: Task{URL: "https://example.com/${HOST}/news", Name: "news"},
+			urls: []string{"https://example.com/${HOST}/news"}, names: []string{"news"},
+		},
+		{
+			name:  "padded range",
+			task:  Task{URL: "https://example.com/{01..03}", Name: "page"},
+			urls:  []string{"https://example.com/01", "https://example.com/02", "https://example.com/03"},
+			names: []string{"page [1=01]", "page [1=02]", "page [1=03]"},
+		},
+		{
+			name:  "descending range with step",
+			task:  Task{URL: "https://example.com/{10..0..5}"},
+			urls:  []string{"https://example.com/10", "https://example.com/5", "https://example.com/0"},
+			names: []string{"", "", ""},
+		},
+		{
+			name: "params combined with a range",
+			task: Task{URL: "https://example.com/{city}/{1..2}", Name: "shop",
+				Params: map[string][]string{"city": {"msk", "spb"}}},
+			urls: []string{"https://example.com/msk/1", "https://example.com/msk/2",
+				"https://example.com/spb/1", "https://example.com/spb/2"},
+			names: []string{"shop [city=msk 1=1]", "shop [city=msk 1=2]", "shop [city=spb 1=1]", "shop [city=spb 1=2]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := expandTemplate(tt.task)
+			if err != nil {
+				t.Fatalf("expandTemplate: %v", err)
+			}
+			var urls, names []string
+			for _, task := range tasks {
+				urls = append(urls, task.URL)
+				names = append(names, task.Name)
+			}
+			if !reflect.DeepEqual(urls, tt.urls) {
+				t.Errorf("URLs = %q, want %q", urls, tt.urls)
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("names = %q, want %q", names, tt.names)
+			}
+		})
+	}
+}
+
+func TestExpandTemplateVars(t *testing.T) {
+	tasks, err := expandTemplate(Task{ID: "shop", URL: "https://example.com/{city}", Params: map[string][]string{"city": {"msk"}}})
+	if err != nil {
+		t.Fatalf("expandTemplate: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "shop-1" || tasks[0].Vars["city"] != "msk" || tasks[0].Params != nil {
+		t.Errorf("tasks = %+v, want one task shop-1 with Vars city=msk", tasks)
+	}
+}
+
+func TestExpandTemplateErrors(t *testing.T) {
+	for _, task := range []Task{
+		{URL: "https://example.com/", Params: map[string][]string{"city": {"msk"}}},
+		{URL: "https://example.com/{city}"},
+		{URL: "https://example.com/{a-b}"},
+		{URL: "https://example.com/{1..5..0}"},
+		{URL: "https://example.com/{1..200}/{0..199}"},
+	} {
+		if _, err := expandTemplate(task); err == nil {
+			t.Errorf("expandTemplate(%q) succeeded, want an error", task.URL)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package taskconfig
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOMLTasksLoader реализует загрузку из TOML. Задачи описываются массивом
+// таблиц [[Tasks]] с теми же полями, что и JSON, подключаемые файлы -
+// списком Include.
+type TOMLTasksLoader struct{}
+
+func NewTOMLLoader() *TOMLTasksLoader {
+	return &TOMLTasksLoader{}
+}
+
+func (t *TOMLTasksLoader) Load(filePath string) ([]Task, error) {
+	return loadFile(filePath, t)
+}
+
+func (t *TOMLTasksLoader) parse(data []byte) (taskFile, error) {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return taskFile{}, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return decodeRaw(raw)
+}
@@ -0,0 +1,118 @@
+package taskconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// URLList описывает задачи, построенные из списка ссылок по общему шаблону:
+//
+//	{"URLs": {"File": "products.csv"}, "Template": {"Type": "Товары", "Selectors": {...}}}
+//
+// File - текстовый файл с одним URL в строке (пустые строки и строки
+// с # пропускаются) или CSV с заголовком. В CSV колонка URL задает адрес,
+// а остальные колонки попадают в Vars задачи и в результат как поля
+// "Param.<колонка>". Если колонки URL нет, адрес берется из URL шаблона
+// с подстановкой колонок: "https://shop/item/{sku}".
+type URLList struct {
+	File string `json:"File"`
+}
+
+// loadURLList строит задачи из списка ссылок. Путь к списку считается
+// от каталога файла задач.
+func loadURLList(configPath string, list URLList, template *Task) ([]Task, error) {
+	if template == nil {
+		return nil, fmt.Errorf("URLs require a Template task")
+	}
+	path, err := resolvePath(configPath, list.File)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]string
+	if configExt(path) == ".csv" {
+		rows, err = parseURLTable(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else {
+		s := bufio.NewScanner(bytes.NewReader(data))
+		for s.Scan() {
+			line := strings.TrimSpace(s.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			rows = append(rows, map[string]string{"URL": line})
+		}
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read url list: %w", err)
+		}
+	}
+
+	tasks := make([]Task, 0, len(rows))
+	for i, row := range rows {
+		t := *template
+		t.Source, t.Index = path, i
+		t.Vars = make(map[string]string, len(row)+len(template.Vars))
+		for k, v := range template.Vars {
+			t.Vars[k] = v
+		}
+		for column, value := range row {
+			if column == "URL" {
+				t.URL = value
+				continue
+			}
+			t.Vars[column] = value
+			if _, ok := row["URL"]; !ok {
+				t.URL = strings.ReplaceAll(t.URL, "{"+column+"}", value)
+			}
+		}
+		if t.Name == "" {
+			t.Name = t.URL
+		} else {
+			t.Name = fmt.Sprintf("%s [%d]", t.Name, i+1)
+		}
+		if t.ID != "" {
+			t.ID = fmt.Sprintf("%s-%d", t.ID, i+1)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// parseURLTable читает CSV с заголовком в список строк по именам колонок.
+func parseURLTable(data []byte) ([]map[string]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if strings.EqualFold(header[i], "URL") {
+			header[i] = "URL"
+		}
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, value := range record {
+			row[header[i]] = strings.TrimSpace(value)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
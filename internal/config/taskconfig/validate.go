@@ -0,0 +1,176 @@
+package taskconfig
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rx3lixir/ish3ikin/internal/rules"
+)
+
+// Validate проверяет задачи после загрузки и возвращает все найденные
+// проблемы сразу. Каждая проблема указывает файл и индекс задачи в нем,
+// например "tasks.json[3]: URL is missing". Для задач из подключенных
+// файлов указывается их собственный файл; file используется для задач
+// без Source.
+func Validate(file string, tasks []Task) error {
+	var errs []error
+	names := make(map[string]string, len(tasks))
+	for i, task := range tasks {
+		where := fmt.Sprintf("%s[%d]", file, i)
+		if task.Source != "" {
+			where = fmt.Sprintf("%s[%d]", task.Source, task.Index)
+		}
+		report := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf("%s: %s", where, fmt.Sprintf(format, args...)))
+		}
+
+		if task.URL == "" {
+			report("URL is missing")
+		} else if envRef.MatchString(task.URL) {
+			// URL с ${VAR} проверяется после подстановки переменных окружения.
+		} else if u, err := url.Parse(task.URL); err != nil {
+			report("invalid URL %q: %v", task.URL, err)
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			report("URL %q must use http or https", task.URL)
+		} else if u.Host == "" {
+			report("URL %q has no host", task.URL)
+		}
+
+		if task.Name == "" {
+			report("Name is empty")
+		} else if first, ok := names[task.Name]; ok {
+			report("Name %q is already used by %s", task.Name, first)
+		} else {
+			names[task.Name] = where
+		}
+
+		if task.TimeoutSeconds < 0 {
+			report("TimeoutSeconds must not be negative")
+		}
+		if task.Retries != nil && *task.Retries < 0 {
+			report("Retries must not be negative")
+		}
+		if task.Schedule != "" {
+			if _, err := ParseSchedule(task.Schedule); err != nil {
+				report("%v", err)
+			}
+		}
+		if err := CheckOverlap(task.Overlap); err != nil {
+			report("%v", err)
+		}
+		if err := CheckWait(task.WaitUntil); err != nil {
+			report("%v", err)
+		}
+		if task.WaitSelector != "" {
+			if err := checkCSS(task.WaitSelector); err != nil {
+				report("WaitSelector: %v", err)
+			}
+		}
+		if err := CheckProxy(task.Proxy); err != nil {
+			report("%v", err)
+		}
+		for _, alert := range task.Alerts {
+			if _, err := rules.Parse(alert); err != nil {
+				report("Alerts: %v", err)
+			}
+		}
+
+		switch task.EngineName() {
+		case EngineBrowser:
+			if len(task.Selectors) == 0 && len(task.Structured) == 0 && len(task.Steps) == 0 {
+				report("no Selectors, Structured fields or Steps to extract")
+			}
+		case EngineFeed:
+		default:
+			report("unknown Engine %q", task.Engine)
+		}
+
+		for _, field := range slices.Sorted(maps.Keys(task.Selectors)) {
+			if err := checkSelector(task.Selectors[field]); err != nil {
+				report("selector %s: %v", field, err)
+			}
+		}
+		checkSteps(task.Steps, report)
+	}
+	return errors.Join(errs...)
+}
+
+// checkSteps проверяет селекторы шагов сценария, включая вложенные.
+func checkSteps(steps []Step, report func(format string, args ...interface{})) {
+	for _, step := range steps {
+		if step.Selector != "" {
+			if err := checkCSS(step.Selector); err != nil {
+				report("step %s: %v", step.Type, err)
+			}
+		}
+		for _, field := range slices.Sorted(maps.Keys(step.Fields)) {
+			if err := checkSelector(step.Fields[field]); err != nil {
+				report("step %s: selector %s: %v", step.Type, field, err)
+			}
+		}
+		checkSteps(step.Steps, report)
+	}
+}
+
+// checkSelector проверяет тип поля, регулярное выражение и CSS-селекторы.
+func checkSelector(sel Selector) error {
+	switch sel.Kind() {
+	case FieldText, FieldCount, FieldExists:
+	default:
+		return fmt.Errorf("unknown Type %q", sel.Type)
+	}
+	candidates := sel.Candidates()
+	if len(candidates) == 0 {
+		return errors.New("CSS selector is empty")
+	}
+	for _, css := range candidates {
+		if err := checkCSS(css); err != nil {
+			return err
+		}
+	}
+	if sel.Regex != "" {
+		if _, err := regexp.Compile(sel.Regex); err != nil {
+			return fmt.Errorf("invalid Regex: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkCSS ищет явные ошибки в CSS-селекторе: незакрытые скобки и кавычки.
+// Полная проверка синтаксиса остается браузеру.
+func checkCSS(css string) error {
+	var (
+		stack []rune
+		quote rune
+	)
+	pairs := map[rune]rune{')': '(', ']': '['}
+	for _, r := range css {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[':
+			stack = append(stack, r)
+		case r == ')' || r == ']':
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[r] {
+				return fmt.Errorf("malformed CSS selector %q: unexpected %q", css, r)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if quote != 0 || len(stack) > 0 {
+		return fmt.Errorf("malformed CSS selector %q: unclosed bracket or quote", css)
+	}
+	if strings.TrimSpace(css) == "" {
+		return errors.New("CSS selector is empty")
+	}
+	return nil
+}
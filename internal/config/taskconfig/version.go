@@ -0,0 +1,138 @@
+package taskconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion - версия формата файлов задач, которую понимает и пишет
+// эта версия программы. Файлы без Version относятся к версии 1.
+const CurrentVersion = 2
+
+// migrations[v] переводит документ файла задач из версии v в версию v+1.
+// Изменение формата, при котором старые файлы перестают читаться, добавляет
+// сюда шаг и увеличивает CurrentVersion.
+var migrations = map[int]func(doc interface{}) (interface{}, error){
+	// Версия 1 - файлы без Version: массив задач или объект с Tasks.
+	// Версия 2 - всегда объект с Version. Поля задач не менялись, поэтому
+	// шаг только заворачивает массив задач в объект.
+	1: func(doc interface{}) (interface{}, error) {
+		if tasks, ok := doc.([]interface{}); ok {
+			return map[string]interface{}{"Tasks": tasks}, nil
+		}
+		return doc, nil
+	},
+}
+
+// migrate приводит документ файла задач к CurrentVersion и возвращает
+// версию, из которой он переведен.
+func migrate(doc interface{}) (interface{}, int, error) {
+	from, err := documentVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from > CurrentVersion {
+		return nil, 0, fmt.Errorf("file format version %d is newer than supported version %d, upgrade ish3ikin", from, CurrentVersion)
+	}
+	for v := from; v < CurrentVersion; v++ {
+		if doc, err = migrations[v](doc); err != nil {
+			return nil, 0, fmt.Errorf("failed to migrate from version %d: %w", v, err)
+		}
+	}
+	if m, ok := doc.(map[string]interface{}); ok {
+		m["Version"] = CurrentVersion
+	}
+	return doc, from, nil
+}
+
+// documentVersion возвращает версию формата документа из поля Version.
+func documentVersion(doc interface{}) (int, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return 1, nil
+	}
+	value, ok := m["Version"]
+	if !ok {
+		return 1, nil
+	}
+
+	var version float64
+	switch v := value.(type) {
+	case float64:
+		version = v
+	case int:
+		version = float64(v)
+	case int64:
+		version = float64(v)
+	case uint64:
+		version = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("invalid Version %q", v)
+		}
+		version = f
+	default:
+		return 0, fmt.Errorf("Version must be a number, got %v", value)
+	}
+	if version < 1 || version != math.Trunc(version) {
+		return 0, fmt.Errorf("invalid Version %v, expected a positive integer", value)
+	}
+	return int(version), nil
+}
+
+// MigrateFile читает локальный файл задач и возвращает его содержимое,
+// приведенное к CurrentVersion, в том же формате, вместе с исходной версией.
+// Комментарии и порядок ключей при этом не сохраняются.
+func MigrateFile(path string) ([]byte, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw interface{}
+	ext := configExt(path)
+	switch ext {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		_, err = toml.Decode(string(data), &raw)
+	default:
+		return nil, 0, fmt.Errorf("cannot migrate %s files, expected .json, .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	doc, from, err := migrate(normalize(raw))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf bytes.Buffer
+	switch ext {
+	case ".json":
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		err = enc.Encode(doc)
+	case ".yaml", ".yml":
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err = enc.Encode(doc)
+	case ".toml":
+		err = toml.NewEncoder(&buf).Encode(doc)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), from, nil
+}
@@ -0,0 +1,55 @@
+package taskconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		from int
+	}{
+		{name: "version 1 array", data: `[{"URL": "https://example.com"}]`, from: 1},
+		{name: "version 1 object", data: `{"Tasks": [{"URL": "https://example.com"}]}`, from: 1},
+		{name: "current version", data: `{"Version": 2, "Tasks": [{"URL": "https://example.com"}]}`, from: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			data, from, err := MigrateFile(path)
+			if err != nil {
+				t.Fatalf("MigrateFile: %v", err)
+			}
+			if from != tt.from {
+				t.Errorf("from = %d, want %d", from, tt.from)
+			}
+			file, err := decodeFile(data)
+			if err != nil {
+				t.Fatalf("decode migrated file: %v", err)
+			}
+			if file.Version != CurrentVersion || len(file.Tasks) != 1 || file.Tasks[0].URL != "https://example.com" {
+				t.Errorf("migrated file = %+v, want version %d with one task", file, CurrentVersion)
+			}
+		})
+	}
+}
+
+func TestMigrateRejectsVersion(t *testing.T) {
+	for data, want := range map[string]string{
+		`{"Version": 99, "Tasks": []}`:  "newer than supported",
+		`{"Version": 1.5, "Tasks": []}`: "expected a positive integer",
+		`{"Version": "2", "Tasks": []}`: "must be a number",
+	} {
+		_, err := decodeFile([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("decodeFile(%s) error = %v, want %q", data, err, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package taskconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Когда страница считается загруженной, см. поле WaitUntil.
+const (
+	// WaitLoad ждет события load (по умолчанию).
+	WaitLoad = "load"
+	// WaitIdle после load ждет, пока страница перестанет отправлять запросы.
+	WaitIdle = "idle"
+	// WaitStable после load ждет, пока DOM перестанет меняться.
+	WaitStable = "stable"
+)
+
+// CheckWait проверяет стратегию ожидания из поля WaitUntil.
+func CheckWait(wait string) error {
+	switch wait {
+	case "", WaitLoad, WaitIdle, WaitStable:
+		return nil
+	}
+	return fmt.Errorf("unknown WaitUntil %q, expected %s, %s or %s", wait, WaitLoad, WaitIdle, WaitStable)
+}
+
+// CheckProxy проверяет адрес прокси из поля Proxy.
+func CheckProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid Proxy %q, expected scheme://host:port", proxy)
+	}
+	if u.User != nil {
+		return errors.New("Proxy must not contain credentials, use --proxy-user and --proxy-password")
+	}
+	return nil
+}
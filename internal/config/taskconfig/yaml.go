@@ -0,0 +1,28 @@
+package taskconfig
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLTasksLoader реализует загрузку из YAML. Файл устроен так же, как JSON:
+// список задач или объект с Include и Tasks; поддерживаются комментарии,
+// якоря и ссылки.
+type YAMLTasksLoader struct{}
+
+func NewYAMLLoader() *YAMLTasksLoader {
+	return &YAMLTasksLoader{}
+}
+
+func (y *YAMLTasksLoader) Load(filePath string) ([]Task, error) {
+	return loadFile(filePath, y)
+}
+
+func (y *YAMLTasksLoader) parse(data []byte) (taskFile, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return taskFile{}, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return decodeRaw(raw)
+}
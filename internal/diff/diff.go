@@ -0,0 +1,175 @@
+// Package diff сравнивает записи одной задачи из двух запусков.
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+)
+
+// Kind - вид изменения записи или поля.
+type Kind int
+
+// Виды изменений.
+const (
+	Added Kind = iota
+	Removed
+	Changed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// identityFields - поля, по которым записи двух запусков сопоставляются
+// друг с другом. Если ни одно из них не заполнено во всех записях
+// разными значениями, записи сопоставляются по порядку.
+var identityFields = []string{"URL", "Url", "url", "Link", "link", "ID", "Id", "id", "GUID", "guid"}
+
+// ignoredFields не сравниваются: TaskID меняется при перестановке задач.
+var ignoredFields = map[string]bool{"TaskID": true}
+
+// Change - изменение одной записи. Key - значение поля, по которому
+// записи сопоставлены, или номер записи.
+type Change struct {
+	Kind   Kind
+	Key    string
+	Fields []Field
+}
+
+// Field - изменение одного поля записи.
+type Field struct {
+	Kind Kind
+	Name string
+	Old  string
+	New  string
+}
+
+// Records возвращает изменения записей задачи между прошлым (prev)
+// и текущим (next) запуском.
+func Records(prev, next []map[string]string) []Change {
+	field := identityField(prev, next)
+	oldByKey, oldKeys := index(prev, field)
+	newByKey, newKeys := index(next, field)
+
+	var changes []Change
+	for _, key := range oldKeys {
+		if _, ok := newByKey[key]; !ok {
+			changes = append(changes, Change{Kind: Removed, Key: key})
+		}
+	}
+	for _, key := range newKeys {
+		before, ok := oldByKey[key]
+		if !ok {
+			changes = append(changes, Change{Kind: Added, Key: key})
+			continue
+		}
+		if fields := Fields(before, newByKey[key]); len(fields) > 0 {
+			changes = append(changes, Change{Kind: Changed, Key: key, Fields: fields})
+		}
+	}
+	return changes
+}
+
+// Fields возвращает изменения полей записи, упорядоченные по имени поля.
+func Fields(prev, next map[string]string) []Field {
+	var fields []Field
+	for name, value := range prev {
+		if ignoredFields[name] {
+			continue
+		}
+		now, ok := next[name]
+		switch {
+		case !ok:
+			fields = append(fields, Field{Kind: Removed, Name: name, Old: value})
+		case now != value:
+			fields = append(fields, Field{Kind: Changed, Name: name, Old: value, New: now})
+		}
+	}
+	for name, value := range next {
+		if _, ok := prev[name]; !ok && !ignoredFields[name] {
+			fields = append(fields, Field{Kind: Added, Name: name, New: value})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// Keys возвращает ключи записей, по которым их можно узнать в другом
+// запуске: значения поля сопоставления, а без него - отпечатки содержимого
+// записей. В отличие от номеров отпечатки не меняются, когда записи
+// переставлены.
+func Keys(records []map[string]string) []string {
+	if field := identityField(records, records); field != "" {
+		_, keys := index(records, field)
+		return keys
+	}
+	keys := make([]string, len(records))
+	for i, record := range records {
+		keys[i] = fingerprint(record)
+	}
+	return keys
+}
+
+// fingerprint возвращает отпечаток полей записи без ignoredFields.
+func fingerprint(record map[string]string) string {
+	names := make([]string, 0, len(record))
+	for name := range record {
+		if !ignoredFields[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name + "\x00" + record[name] + "\x00"))
+	}
+	return "#" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// identityField выбирает поле, которое отличает записи друг от друга
+// в обоих запусках.
+func identityField(prev, next []map[string]string) string {
+	for _, field := range identityFields {
+		if unique(prev, field) && unique(next, field) {
+			return field
+		}
+	}
+	return ""
+}
+
+// unique сообщает, что поле заполнено во всех записях и не повторяется.
+func unique(records []map[string]string, field string) bool {
+	seen := make(map[string]bool, len(records))
+	for _, record := range records {
+		value := record[field]
+		if value == "" || seen[value] {
+			return false
+		}
+		seen[value] = true
+	}
+	return true
+}
+
+// index раскладывает записи по ключу сопоставления, сохраняя их порядок.
+func index(records []map[string]string, field string) (map[string]map[string]string, []string) {
+	byKey := make(map[string]map[string]string, len(records))
+	keys := make([]string, 0, len(records))
+	for i, record := range records {
+		key := "#" + strconv.Itoa(i+1)
+		if field != "" {
+			key = record[field]
+		}
+		byKey[key] = record
+		keys = append(keys, key)
+	}
+	return byKey, keys
+}
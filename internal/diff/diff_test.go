@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdentityField(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, next []map[string]string
+		want       string
+	}{
+		{
+			name: "url",
+			prev: []map[string]string{{"URL": "a", "Title": "x"}, {"URL": "b", "Title": "x"}},
+			next: []map[string]string{{"URL": "b", "Title": "y"}},
+			want: "URL",
+		},
+		{
+			name: "link when url repeats",
+			prev: []map[string]string{{"URL": "page", "Link": "a"}, {"URL": "page", "Link": "b"}},
+			next: []map[string]string{{"URL": "page", "Link": "c"}, {"URL": "page", "Link": "a"}},
+			want: "Link",
+		},
+		{
+			name: "empty value",
+			prev: []map[string]string{{"ID": "1"}, {"ID": ""}},
+			next: []map[string]string{{"ID": "1"}},
+			want: "",
+		},
+		{
+			name: "unique in one run only",
+			prev: []map[string]string{{"guid": "1"}, {"guid": "2"}},
+			next: []map[string]string{{"guid": "1"}, {"guid": "1"}},
+			want: "",
+		},
+		{
+			name: "no identity fields",
+			prev: []map[string]string{{"Title": "a"}},
+			next: []map[string]string{{"Title": "b"}},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		if got := identityField(tt.prev, tt.next); got != tt.want {
+			t.Errorf("%s: identityField = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	prev := map[string]string{"Price": "100", "Title": "Phone", "Old": "x", "TaskID": "t1"}
+	next := map[string]string{"Price": "90", "Title": "Phone", "New": "y", "TaskID": "t2"}
+	want := []Field{
+		{Kind: Added, Name: "New", New: "y"},
+		{Kind: Removed, Name: "Old", Old: "x"},
+		{Kind: Changed, Name: "Price", Old: "100", New: "90"},
+	}
+	if got := Fields(prev, next); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields = %+v, want %+v", got, want)
+	}
+	if got := Fields(prev, prev); len(got) != 0 {
+		t.Errorf("Fields of equal records = %+v, want none", got)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, next []map[string]string
+		want       []Change
+	}{
+		{
+			name: "matched by url",
+			prev: []map[string]string{
+				{"URL": "a", "Price": "1"},
+				{"URL": "b", "Price": "2"},
+			},
+			next: []map[string]string{
+				{"URL": "c", "Price": "3"},
+				{"URL": "a", "Price": "5"},
+			},
+			want: []Change{
+				{Kind: Removed, Key: "b"},
+				{Kind: Added, Key: "c"},
+				{Kind: Changed, Key: "a", Fields: []Field{{Kind: Changed, Name: "Price", Old: "1", New: "5"}}},
+			},
+		},
+		{
+			name: "reordered records are unchanged",
+			prev: []map[string]string{{"URL": "a", "Price": "1"}, {"URL": "b", "Price": "2"}},
+			next: []map[string]string{{"URL": "b", "Price": "2"}, {"URL": "a", "Price": "1"}},
+		},
+		{
+			name: "matched by position",
+			prev: []map[string]string{{"Title": "a"}, {"Title": "b"}},
+			next: []map[string]string{{"Title": "a"}},
+			want: []Change{{Kind: Removed, Key: "#2"}},
+		},
+		{
+			name: "first run",
+			next: []map[string]string{{"URL": "a"}},
+			want: []Change{{Kind: Added, Key: "a"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := Records(tt.prev, tt.next); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Records = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	byURL := Keys([]map[string]string{{"URL": "a"}, {"URL": "b"}})
+	if !reflect.DeepEqual(byURL, []string{"a", "b"}) {
+		t.Errorf("Keys = %v, want [a b]", byURL)
+	}
+
+	// Без поля сопоставления ключи не зависят от порядка записей
+	first := Keys([]map[string]string{{"Title": "a", "TaskID": "t1"}, {"Title": "b"}})
+	second := Keys([]map[string]string{{"Title": "b"}, {"Title": "a", "TaskID": "t2"}})
+	if first[0] != second[1] || first[1] != second[0] {
+		t.Errorf("Keys depend on the record order: %v and %v", first, second)
+	}
+	if first[0] == first[1] {
+		t.Errorf("different records got the same key %s", first[0])
+	}
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSV копит записи и пишет их при закрытии: набор колонок известен только
+// после всех задач.
+type CSV struct {
+	f       io.WriteCloser
+	records []map[string]string
+}
+
+func newCSV(f io.WriteCloser) *CSV {
+	return &CSV{f: f}
+}
+
+func (c *CSV) Export(records []map[string]string) error {
+	c.records = append(c.records, records...)
+	return nil
+}
+
+func (c *CSV) Close() error {
+	if err := c.write(); err != nil {
+		c.f.Close()
+		return err
+	}
+	return c.f.Close()
+}
+
+func (c *CSV) write() error {
+	w := csv.NewWriter(c.f)
+	cols := columns(c.records)
+	if err := w.Write(cols); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+	row := make([]string, len(cols))
+	for _, record := range c.records {
+		for i, col := range cols {
+			row[i] = record[col]
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("failed to write csv record: %w", err)
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,100 @@
+// Package export записывает результаты задач в файл.
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Форматы файла результатов.
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+)
+
+// Stdout - путь, при котором результаты пишутся в стандартный вывод.
+const Stdout = "-"
+
+// Exporter записывает записи результатов. Close дописывает буферизованные
+// записи и закрывает файл.
+type Exporter interface {
+	Export(records []map[string]string) error
+	Close() error
+}
+
+// Open создает файл результатов path в формате format. Пустой формат
+// выбирается по расширению файла. Путь Stdout пишет в стандартный вывод.
+func Open(path, format string) (Exporter, error) {
+	if format == "" {
+		format = FormatFor(path)
+	}
+	switch format {
+	case FormatCSV, FormatJSON, FormatJSONL:
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, expected csv, json or jsonl", format)
+	}
+
+	var f io.WriteCloser = stdout{os.Stdout}
+	if path != Stdout {
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		f = file
+	}
+	switch format {
+	case FormatCSV:
+		return newCSV(f), nil
+	case FormatJSON:
+		return newJSON(f), nil
+	default:
+		return newJSONL(f), nil
+	}
+}
+
+// stdout - стандартный вывод, который Close экспортера оставляет открытым.
+type stdout struct {
+	io.Writer
+}
+
+func (stdout) Close() error {
+	return nil
+}
+
+// FormatFor возвращает формат по расширению файла, по умолчанию csv.
+func FormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	default:
+		return FormatCSV
+	}
+}
+
+// columns возвращает имена полей всех записей: TaskID первым, остальные по алфавиту.
+func columns(records []map[string]string) []string {
+	seen := make(map[string]bool)
+	var cols []string
+	for _, record := range records {
+		for name := range record {
+			if !seen[name] {
+				seen[name] = true
+				cols = append(cols, name)
+			}
+		}
+	}
+	sort.Slice(cols, func(i, j int) bool {
+		if (cols[i] == "TaskID") != (cols[j] == "TaskID") {
+			return cols[i] == "TaskID"
+		}
+		return cols[i] < cols[j]
+	})
+	return cols
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSON копит записи и пишет их одним массивом при закрытии.
+type JSON struct {
+	f       io.WriteCloser
+	records []map[string]string
+}
+
+func newJSON(f io.WriteCloser) *JSON {
+	return &JSON{f: f, records: []map[string]string{}}
+}
+
+func (j *JSON) Export(records []map[string]string) error {
+	j.records = append(j.records, records...)
+	return nil
+}
+
+func (j *JSON) Close() error {
+	enc := json.NewEncoder(j.f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(j.records); err != nil {
+		j.f.Close()
+		return fmt.Errorf("failed to write json: %w", err)
+	}
+	return j.f.Close()
+}
+
+// JSONL пишет каждую запись отдельной строкой сразу после получения.
+type JSONL struct {
+	f   io.WriteCloser
+	enc *json.Encoder
+}
+
+func newJSONL(f io.WriteCloser) *JSONL {
+	return &JSONL{f: f, enc: json.NewEncoder(f)}
+}
+
+func (j *JSONL) Export(records []map[string]string) error {
+	for _, record := range records {
+		if err := j.enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+	return nil
+}
+
+func (j *JSONL) Close() error {
+	return j.f.Close()
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// jsonKeys приводит ключи JSON-логов к виду, удобному для сборщиков логов:
+// "task id:" записывается как "task_id". Логгер пишет каждую запись
+// одним вызовом Write.
+type jsonKeys struct {
+	w io.Writer
+}
+
+func (j jsonKeys) Write(p []byte) (int, error) {
+	var entry map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&entry); err != nil {
+		return j.w.Write(p)
+	}
+
+	renamed := make(map[string]interface{}, len(entry))
+	for key, value := range entry {
+		renamed[fieldName(key)] = value
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(renamed); err != nil {
+		return j.w.Write(p)
+	}
+	if _, err := j.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// fieldName убирает двоеточие в конце ключа и заменяет пробелы
+// подчеркиваниями.
+func fieldName(key string) string {
+	key = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(key), ":"))
+	return strings.ReplaceAll(key, " ", "_")
+}
@@ -1,18 +1,112 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// NewLogger создает новый экземпляр логгера с предварительно заданной конфигурацией.
-func NewLogger() *log.Logger {
-	logger := log.NewWithOptions(os.Stdout, log.Options{
+// Options - параметры логгера.
+type Options struct {
+	// Level - минимальный уровень сообщений: debug, info, warn или error.
+	Level string
+	// Quiet оставляет только предупреждения и ошибки, Verbose включает
+	// отладочные сообщения. Оба флага перекрывают Level.
+	Quiet   bool
+	Verbose bool
+	// Format - "text" для чтения в терминале или "json" для сборщиков
+	// логов. По умолчанию text.
+	Format string
+	// File - файл, куда лог пишется вместе с консолью, с уровнем FileLevel
+	// (по умолчанию debug). Файл ротируется по размеру MaxSize в мегабайтах
+	// и возрасту MaxAge в днях, старых файлов хранится не больше MaxBackups.
+	File       string
+	FileLevel  string
+	MaxSize    int
+	MaxAge     int
+	MaxBackups int
+}
+
+// Форматы логов.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// NewLogger создает новый экземпляр логгера с предварительно заданной
+// конфигурацией. Через Output можно перенаправить консольный вывод
+// и закрыть файл лога.
+func NewLogger(opts Options) (*slog.Logger, *Output, error) {
+	lvl, err := opts.level()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := &Output{}
+	switch opts.Format {
+	case "", FormatText:
+	case FormatJSON:
+		out.json = true
+	default:
+		return nil, nil, fmt.Errorf("invalid log format %q, expected text or json", opts.Format)
+	}
+
+	out.console = out.handler(os.Stdout, lvl, time.Kitchen)
+	handlers := fanout{out.console}
+	if opts.File != "" {
+		fileLevel := log.DebugLevel
+		if opts.FileLevel != "" {
+			if fileLevel, err = log.ParseLevel(opts.FileLevel); err != nil {
+				return nil, nil, fmt.Errorf("invalid log file level %q: %w", opts.FileLevel, err)
+			}
+		}
+		file := &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSize,
+			MaxAge:     opts.MaxAge,
+			MaxBackups: opts.MaxBackups,
+		}
+		out.file = file
+		handlers = append(handlers, out.handler(file, fileLevel, time.RFC3339))
+	}
+	return slog.New(handlers), out, nil
+}
+
+// handler создает обработчик одного вывода лога. В файл время пишется
+// полностью, в консоль - коротко.
+func (o *Output) handler(w io.Writer, level log.Level, timeFormat string) *log.Logger {
+	options := log.Options{
 		ReportCaller:    true,
 		ReportTimestamp: true,
-		TimeFormat:      time.Kitchen,
-	})
-	return logger
+		TimeFormat:      timeFormat,
+		Level:           level,
+	}
+	if o.json {
+		options.Formatter = log.JSONFormatter
+		options.TimeFormat = time.RFC3339Nano
+		w = jsonKeys{w}
+	}
+	return log.NewWithOptions(w, options)
+}
+
+// level возвращает минимальный уровень консоли с учетом Quiet и Verbose.
+func (o Options) level() (log.Level, error) {
+	switch {
+	case o.Quiet && o.Verbose:
+		return 0, errors.New("quiet and verbose cannot be used together")
+	case o.Quiet:
+		return log.WarnLevel, nil
+	case o.Verbose:
+		return log.DebugLevel, nil
+	}
+	lvl, err := log.ParseLevel(o.Level)
+	if err != nil {
+		return 0, fmt.Errorf("invalid log level %q: %w", o.Level, err)
+	}
+	return lvl, nil
 }
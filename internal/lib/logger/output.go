@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/charmbracelet/log"
+)
+
+// Output - выводы логгера: консоль и, если задан, файл с ротацией.
+// Каждый вывод - отдельный обработчик со своим минимальным уровнем,
+// поэтому в файл можно писать отладку, оставив консоль читаемой. Цвета
+// попадают только в терминал: обработчик выбирает их по своему выводу.
+type Output struct {
+	console *log.Logger
+	json    bool
+	file    io.Closer
+}
+
+// SetConsole направляет консольную часть лога в w, например в панель прогресса.
+func (o *Output) SetConsole(w io.Writer) {
+	if o.json {
+		w = jsonKeys{w}
+	}
+	o.console.SetOutput(w)
+}
+
+// Close закрывает файл лога.
+func (o *Output) Close() error {
+	if o.file == nil {
+		return nil
+	}
+	return o.file.Close()
+}
+
+// fanout передает каждую запись всем выводам, уровень которых ее пропускает.
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, record.Level) {
+			errs = append(errs, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
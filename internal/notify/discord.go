@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// discordMaxTitle и discordMaxDescription - ограничения Discord на embed.
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+)
+
+// discordColors - цвета полосы embed по типам событий.
+var discordColors = map[string]int{
+	EventRunSucceeded:    0x1a7f37,
+	EventRunFailed:       0xcf222e,
+	EventTaskFailing:     0xcf222e,
+	EventSelectorsBroken: 0x9a6700,
+}
+
+// discordEscaper экранирует символы разметки Discord.
+var discordEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`)
+
+// Discord отправляет событие в вебхук канала Discord сообщением с embed.
+type Discord struct {
+	URL string
+	// Username, если задан, заменяет имя вебхука в сообщении.
+	Username string
+	Client   *http.Client
+}
+
+func (d *Discord) Notify(ctx context.Context, e Event) error {
+	title, lines := message(e)
+	embed := map[string]any{
+		"title":       truncate(title, discordMaxTitle),
+		"description": truncate(discordEscaper.Replace(strings.Join(lines, "\n")), discordMaxDescription),
+		"color":       discordColors[e.Type],
+		"timestamp":   e.Time,
+	}
+	payload := map[string]any{
+		"embeds": []any{embed},
+		// Упоминания из текста ошибок и имен задач никого не вызывают
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+	if d.Username != "" {
+		payload["username"] = d.Username
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return postJSON(ctx, d.Client, d.URL, nil, body)
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// discordPayload - часть сообщения вебхука Discord, которую проверяют тесты.
+type discordPayload struct {
+	Username string
+	Embeds   []struct {
+		Title       string
+		Description string
+		Color       int
+		Timestamp   time.Time
+	}
+	AllowedMentions struct {
+		Parse []string
+	} `json:"allowed_mentions"`
+}
+
+func discordServer(t *testing.T) (*httptest.Server, *discordPayload) {
+	t.Helper()
+	var got discordPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = discordPayload{}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func TestDiscord(t *testing.T) {
+	srv, got := discordServer(t)
+	discord := &Discord{URL: srv.URL, Username: "ish3ikin"}
+
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	event := Event{Type: EventTaskFailing, Time: at, Task: &Task{Name: "shop", URL: "https://example.com/a_b",
+		ConsecutiveFailures: 3, Error: "@everyone **down** `now`"}}
+	if err := discord.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if got.Username != "ish3ikin" {
+		t.Errorf("username = %q, want ish3ikin", got.Username)
+	}
+	if got.AllowedMentions.Parse == nil || len(got.AllowedMentions.Parse) != 0 {
+		t.Errorf("allowed_mentions.parse = %v, want an empty list", got.AllowedMentions.Parse)
+	}
+	if len(got.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(got.Embeds))
+	}
+	embed := got.Embeds[0]
+	if embed.Title != "🚨 Task shop failed 3 times in a row" || embed.Color != discordColors[EventTaskFailing] || !embed.Timestamp.Equal(at) {
+		t.Errorf("embed = %+v, want the event title, failure color and time", embed)
+	}
+	want := "URL: https://example.com/a\\_b\nError: @everyone \\*\\*down\\*\\* \\`now\\`"
+	if embed.Description != want {
+		t.Errorf("description = %q, want %q", embed.Description, want)
+	}
+}
+
+func TestDiscordLimits(t *testing.T) {
+	srv, got := discordServer(t)
+	discord := &Discord{URL: srv.URL}
+
+	event := Event{Type: EventTaskFailing, Task: &Task{Name: strings.Repeat("n", 300), Error: strings.Repeat("e", 5000)}}
+	if err := discord.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	embed := got.Embeds[0]
+	if n := len([]rune(embed.Title)); n > discordMaxTitle {
+		t.Errorf("title has %d characters, want at most %d", n, discordMaxTitle)
+	}
+	if n := len([]rune(embed.Description)); n > discordMaxDescription {
+		t.Errorf("description has %d characters, want at most %d", n, discordMaxDescription)
+	}
+	if got.Username != "" {
+		t.Errorf("username = %q, want the webhook name", got.Username)
+	}
+}
@@ -0,0 +1,280 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+const (
+	// smtpPort - порт отправки почты с STARTTLS по умолчанию.
+	smtpPort = 587
+	// smtpsPort - порт, на котором TLS включается сразу при подключении.
+	smtpsPort = 465
+	// maxAttachment ограничивает размер прикладываемого файла результатов,
+	// больший файл только дается ссылкой.
+	maxAttachment = 10 << 20
+)
+
+// defaultEmailTemplate - письмо по умолчанию: стандартное сообщение события.
+var defaultEmailTemplate = template.Must(template.New("email").Parse(`<!doctype html>
+<html>
+<body style="font-family: sans-serif; font-size: 14px; color: #1f2328">
+<h2 style="font-size: 16px">{{.Title}}</h2>
+<ul style="padding-left: 1.2em">
+{{- range .Lines}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- if .Link}}
+<p><a href="{{.Link}}">Download results</a></p>
+{{- end}}
+{{- if .Attached}}
+<p>Results are attached.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// Email отправляет событие письмом через SMTP. Для событий запуска
+// к письму прикладывается файл результатов (Attach) или ссылка на него
+// (LinkBase).
+type Email struct {
+	Host string
+	// Port - порт сервера, 0 - 587. На 465 TLS включается сразу,
+	// на остальных - командой STARTTLS, если сервер ее поддерживает.
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Subject - шаблон темы, nil - заголовок стандартного сообщения.
+	Subject *texttemplate.Template
+	// Body - HTML-шаблон письма, nil - шаблон по умолчанию.
+	Body *template.Template
+	// Attach прикладывает файл результатов, если он не больше 10 МБ.
+	Attach bool
+	// LinkBase - адрес каталога, откуда доступны файлы результатов.
+	// Ссылка на файл - LinkBase и его имя.
+	LinkBase string
+}
+
+// emailData - данные шаблонов письма.
+type emailData struct {
+	Event
+	// Title и Lines - стандартное сообщение события.
+	Title string
+	Lines []string
+	// Link - ссылка на файл результатов, если задан LinkBase.
+	Link string
+	// Attached сообщает, что файл результатов приложен к письму.
+	Attached bool
+}
+
+// ParseEmailTemplates разбирает шаблон темы и HTML-шаблон письма из файла
+// path. Пустые значения оставляют шаблоны по умолчанию (nil).
+func ParseEmailTemplates(subject, path string) (*texttemplate.Template, *template.Template, error) {
+	var s *texttemplate.Template
+	if subject != "" {
+		var err error
+		if s, err = texttemplate.New("subject").Funcs(templateFuncs).Option("missingkey=error").Parse(subject); err != nil {
+			return nil, nil, fmt.Errorf("invalid email subject template: %w", err)
+		}
+	}
+	var body *template.Template
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read email template: %w", err)
+		}
+		if body, err = template.New(filepath.Base(path)).Funcs(template.FuncMap(templateFuncs)).Option("missingkey=error").Parse(string(data)); err != nil {
+			return nil, nil, fmt.Errorf("invalid email template %s: %w", path, err)
+		}
+	}
+	return s, body, nil
+}
+
+func (m *Email) Notify(ctx context.Context, e Event) error {
+	data := emailData{Event: e}
+	data.Title, data.Lines = message(e)
+
+	var attachment []byte
+	var output string
+	if e.Summary != nil && e.Summary.Output != "" {
+		output = e.Summary.Output
+		if m.LinkBase != "" {
+			data.Link = strings.TrimSuffix(m.LinkBase, "/") + "/" + filepath.Base(output)
+		}
+		if m.Attach {
+			// Без файла письмо все равно уходит, со ссылкой, если она есть
+			if info, err := os.Stat(output); err == nil && info.Size() <= maxAttachment {
+				if attachment, err = os.ReadFile(output); err == nil {
+					data.Attached = true
+				}
+			}
+		}
+	}
+
+	subject := data.Title
+	if m.Subject != nil {
+		var b strings.Builder
+		if err := m.Subject.Execute(&b, data); err != nil {
+			return fmt.Errorf("failed to render email subject: %w", err)
+		}
+		subject = strings.TrimSpace(b.String())
+	}
+	body := m.Body
+	if body == nil {
+		body = defaultEmailTemplate
+	}
+	var html bytes.Buffer
+	if err := body.Execute(&html, data); err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+
+	var att *attachmentFile
+	if data.Attached {
+		att = &attachmentFile{name: filepath.Base(output), data: attachment}
+	}
+	msg, err := m.compose(subject, html.Bytes(), att)
+	if err != nil {
+		return err
+	}
+	return m.send(ctx, msg)
+}
+
+// attachmentFile - файл, прикладываемый к письму.
+type attachmentFile struct {
+	name string
+	data []byte
+}
+
+// compose собирает письмо: HTML в quoted-printable и вложение в base64.
+func (m *Email) compose(subject string, html []byte, att *attachmentFile) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write(html); err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	if att != nil {
+		contentType := mime.TypeByExtension(filepath.Ext(att.name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.name})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compose email: %w", err)
+		}
+		encoded := base64.StdEncoding.EncodeToString(att.data)
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// send доставляет письмо всем получателям. Вход без TLS net/smtp
+// разрешает только на localhost.
+func (m *Email) send(ctx context.Context, msg []byte) error {
+	port := m.Port
+	if port == 0 {
+		port = smtpPort
+	}
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: m.Host}
+
+	var conn net.Conn
+	var err error
+	if port == smtpsPort {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session with %s: %w", addr, err)
+	}
+	defer c.Close()
+
+	if port != smtpsPort {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("failed to start TLS with %s: %w", addr, err)
+			}
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate with %s: %w", addr, err)
+		}
+	}
+	if err := c.Mail(m.From); err != nil {
+		return fmt.Errorf("sender rejected by %s: %w", addr, err)
+	}
+	for _, to := range m.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s rejected by %s: %w", to, addr, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", addr, err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", addr, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", addr, err)
+	}
+	return c.Quit()
+}
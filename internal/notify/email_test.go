@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+	"time"
+)
+
+// smtpServer - SMTP-сервер без TLS и авторизации, который сохраняет
+// отправителя, получателей и текст каждого письма.
+type smtpServer struct {
+	addr string
+	mail chan smtpMail
+}
+
+type smtpMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	s := &smtpServer{addr: lis.Addr().String(), mail: make(chan smtpMail, 1)}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP")
+	var m smtpMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		switch upper := strings.ToUpper(cmd); {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			m.from = strings.Trim(cmd[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			m.to = append(m.to, strings.Trim(cmd[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			m.data = data.String()
+			s.mail <- m
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// receive ждет письмо, отправленное на сервер.
+func (s *smtpServer) receive(t *testing.T) smtpMail {
+	t.Helper()
+	select {
+	case m := <-s.mail:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+		return smtpMail{}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	server := newSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNum, _ := net.LookupPort("tcp", port)
+
+	output := filepath.Join(t.TempDir(), "results.csv")
+	if err := os.WriteFile(output, []byte("Title,Price\nPhone,9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	subject := texttemplate.Must(texttemplate.New("subject").Parse("[{{.Host}}] {{.Title}}"))
+	email := &Email{
+		Host:     host,
+		Port:     portNum,
+		From:     "bot@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  subject,
+		Attach:   true,
+		LinkBase: "https://files.example.com/runs/",
+	}
+	event := Event{Type: EventRunFailed, Host: "box", Summary: &Summary{Total: 1, Failed: 1, Output: output,
+		Failures: []Failure{{TaskID: "t1", URL: "https://example.com", Error: "<script>"}}}}
+	if err := email.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got := server.receive(t)
+	if got.from != "bot@example.com" || strings.Join(got.to, ",") != "a@example.com,b@example.com" {
+		t.Errorf("envelope = %s -> %v, want the sender and both recipients", got.from, got.to)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || decoded != "[box] ❌ Run failed on box" {
+		t.Errorf("subject = %q, %v, want the rendered subject template", decoded, err)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("Content-Type: %v", err)
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	htmlPart, err := parts.NextPart()
+	if err != nil {
+		t.Fatalf("HTML part: %v", err)
+	}
+	html, _ := io.ReadAll(quotedprintable.NewReader(htmlPart))
+	for _, want := range []string{"&lt;script&gt;", `href="https://files.example.com/runs/results.csv"`, "Results are attached."} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("HTML does not contain %q:\n%s", want, html)
+		}
+	}
+
+	att, err := parts.NextPart()
+	if err != nil {
+		t.Fatalf("attachment part: %v", err)
+	}
+	if att.FileName() != "results.csv" {
+		t.Errorf("attachment name = %q, want results.csv", att.FileName())
+	}
+	// multipart.Reader сам раскодирует только quoted-printable
+	data, _ := io.ReadAll(att)
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(data), "\r\n", ""))
+	if err != nil || string(content) != "Title,Price\nPhone,9\n" {
+		t.Errorf("attachment = %q, %v, want the results file", content, err)
+	}
+}
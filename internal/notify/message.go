@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const (
+	// maxFailures ограничивает число упавших задач в сообщении мессенджера.
+	maxFailures = 10
+	// maxErrorLen ограничивает длину текста ошибки в сообщении.
+	maxErrorLen = 300
+	// maxChanges ограничивает число измененных записей в сообщении.
+	maxChanges = 10
+	// maxValueLen ограничивает длину значения поля в сообщении.
+	maxValueLen = 100
+)
+
+// message описывает событие текстом для мессенджеров: заголовок и строки
+// без разметки. Каналы сами экранируют их и оформляют заголовок.
+func message(e Event) (title string, lines []string) {
+	switch {
+	case e.Summary != nil:
+		s := e.Summary
+		title = "✅ Run succeeded"
+		if e.Type == EventRunFailed {
+			title = "❌ Run failed"
+		}
+		if e.Host != "" {
+			title += " on " + e.Host
+		}
+		if e.RunID != "" {
+			lines = append(lines, "Run: "+e.RunID)
+		}
+		tasks := fmt.Sprintf("Tasks: %d total, %d succeeded, %d failed", s.Total, s.Succeeded, s.Failed)
+		if s.Skipped > 0 {
+			tasks += fmt.Sprintf(", %d skipped", s.Skipped)
+		}
+		if s.Interrupted > 0 {
+			tasks += fmt.Sprintf(", %d interrupted", s.Interrupted)
+		}
+		lines = append(lines, tasks, fmt.Sprintf("Records: %d", s.Records))
+		if s.Duration != "" {
+			lines = append(lines, "Duration: "+s.Duration)
+		}
+		if s.Output != "" {
+			lines = append(lines, "Output: "+s.Output)
+		}
+		for i, f := range s.Failures {
+			if i == maxFailures {
+				lines = append(lines, fmt.Sprintf("… and %d more", len(s.Failures)-maxFailures))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("• %s %s: %s", f.TaskID, f.URL, truncate(f.Error, maxErrorLen)))
+		}
+	case e.Task != nil:
+		t := e.Task
+		switch e.Type {
+		case EventAlert:
+			title = fmt.Sprintf("🎯 Task %s: %s", t.Name, t.Rule)
+			lines = append(lines, "URL: "+t.URL)
+			lines = append(lines, matchLines(t.Matches)...)
+		case EventChanged:
+			title = fmt.Sprintf("🔔 Task %s changed", t.Name)
+			lines = append(lines, "URL: "+t.URL)
+			lines = append(lines, changeLines(t.Changes)...)
+		case EventSelectorsBroken:
+			title = fmt.Sprintf("⚠️ Selectors of task %s found nothing", t.Name)
+			lines = append(lines, "URL: "+t.URL, "Empty fields: "+strings.Join(t.EmptyFields, ", "))
+		default:
+			title = fmt.Sprintf("🚨 Task %s failed", t.Name)
+			if t.ConsecutiveFailures > 1 {
+				title += fmt.Sprintf(" %d times in a row", t.ConsecutiveFailures)
+			}
+			lines = append(lines, "URL: "+t.URL, "Error: "+truncate(t.Error, maxErrorLen))
+		}
+		if e.Host != "" {
+			lines = append(lines, "Host: "+e.Host)
+		}
+		if e.RunID != "" {
+			lines = append(lines, "Run: "+e.RunID)
+		}
+	default:
+		title = e.Type
+	}
+	return title, lines
+}
+
+// changeLines описывает изменения записей: + добавлена, - удалена,
+// ~ изменена со списком полей.
+func changeLines(changes []Change) []string {
+	var lines []string
+	for i, c := range changes {
+		if i == maxChanges {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(changes)-maxChanges))
+			break
+		}
+		switch c.Kind {
+		case "added":
+			lines = append(lines, "+ "+c.Key)
+		case "removed":
+			lines = append(lines, "- "+c.Key)
+		default:
+			fields := make([]string, len(c.Fields))
+			for j, f := range c.Fields {
+				fields[j] = fmt.Sprintf("%s: %s → %s", f.Name, truncate(f.Old, maxValueLen), truncate(f.New, maxValueLen))
+			}
+			lines = append(lines, "~ "+c.Key+": "+strings.Join(fields, "; "))
+		}
+	}
+	return lines
+}
+
+// matchLines описывает записи, подошедшие под условие, по строке на запись.
+func matchLines(matches []map[string]string) []string {
+	var lines []string
+	for i, record := range matches {
+		if i == maxChanges {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(matches)-maxChanges))
+			break
+		}
+		fields := make([]string, 0, len(record))
+		for _, name := range slices.Sorted(maps.Keys(record)) {
+			fields = append(fields, name+": "+truncate(record[name], maxValueLen))
+		}
+		lines = append(lines, "• "+strings.Join(fields, "; "))
+	}
+	return lines
+}
+
+// truncate обрезает s до n символов.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n-1])) + "…"
+}
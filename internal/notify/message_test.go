@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"html"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		title string
+		lines []string
+	}{
+		{
+			name: "run succeeded",
+			event: Event{Type: EventRunSucceeded, Host: "box", RunID: "r1",
+				Summary: &Summary{Total: 3, Succeeded: 3, Records: 12, Duration: "5s", Output: "out.csv"}},
+			title: "✅ Run succeeded on box",
+			lines: []string{"Run: r1", "Tasks: 3 total, 3 succeeded, 0 failed", "Records: 12", "Duration: 5s", "Output: out.csv"},
+		},
+		{
+			name: "run failed",
+			event: Event{Type: EventRunFailed, Summary: &Summary{Total: 2, Succeeded: 1, Failed: 1, Skipped: 1,
+				Failures: []Failure{{TaskID: "t2", URL: "https://example.com", Error: "timeout"}}}},
+			title: "❌ Run failed",
+			lines: []string{"Tasks: 2 total, 1 succeeded, 1 failed, 1 skipped", "Records: 0", "• t2 https://example.com: timeout"},
+		},
+		{
+			name:  "task failing",
+			event: Event{Type: EventTaskFailing, RunID: "r1", Task: &Task{Name: "news", URL: "u", ConsecutiveFailures: 3, Error: "404"}},
+			title: "🚨 Task news failed 3 times in a row",
+			lines: []string{"URL: u", "Error: 404", "Run: r1"},
+		},
+		{
+			name:  "selectors broken",
+			event: Event{Type: EventSelectorsBroken, Task: &Task{Name: "news", URL: "u", EmptyFields: []string{"Price", "Title"}}},
+			title: "⚠️ Selectors of task news found nothing",
+			lines: []string{"URL: u", "Empty fields: Price, Title"},
+		},
+		{
+			name: "changed",
+			event: Event{Type: EventChanged, Task: &Task{Name: "shop", URL: "u", Changes: []Change{
+				{Kind: "added", Key: "a"},
+				{Kind: "removed", Key: "b"},
+				{Kind: "changed", Key: "c", Fields: []FieldChange{{Name: "Price", Old: "10", New: "9"}, {Name: "Stock", Old: "yes", New: "no"}}},
+			}}},
+			title: "🔔 Task shop changed",
+			lines: []string{"URL: u", "+ a", "- b", "~ c: Price: 10 → 9; Stock: yes → no"},
+		},
+		{
+			name: "alert",
+			event: Event{Type: EventAlert, Task: &Task{Name: "shop", URL: "u", Rule: "Price < 10",
+				Matches: []map[string]string{{"Title": "Phone", "Price": "9"}}}},
+			title: "🎯 Task shop: Price < 10",
+			lines: []string{"URL: u", "• Price: 9; Title: Phone"},
+		},
+		{
+			name:  "unknown",
+			event: Event{Type: "custom"},
+			title: "custom",
+		},
+	}
+	for _, tt := range tests {
+		title, lines := message(tt.event)
+		if title != tt.title {
+			t.Errorf("%s: title = %q, want %q", tt.name, title, tt.title)
+		}
+		if !reflect.DeepEqual(lines, tt.lines) {
+			t.Errorf("%s: lines = %q, want %q", tt.name, lines, tt.lines)
+		}
+	}
+}
+
+func TestMessageLimits(t *testing.T) {
+	failures := make([]Failure, maxFailures+2)
+	for i := range failures {
+		failures[i] = Failure{TaskID: "t", URL: "u", Error: strings.Repeat("x", maxErrorLen+50)}
+	}
+	_, lines := message(Event{Type: EventRunFailed, Summary: &Summary{Failures: failures}})
+	if got := lines[len(lines)-1]; got != "… and 2 more" {
+		t.Errorf("last line = %q, want … and 2 more", got)
+	}
+	if got := len([]rune(lines[2])); got > len("• t u: ")+maxErrorLen {
+		t.Errorf("failure line has %d characters, the error is not truncated", got)
+	}
+
+	changes := make([]Change, maxChanges+1)
+	for i := range changes {
+		changes[i] = Change{Kind: "added", Key: "k"}
+	}
+	if lines := changeLines(changes); len(lines) != maxChanges+1 || lines[maxChanges] != "… and 1 more" {
+		t.Errorf("changeLines of %d changes = %q", len(changes), lines)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"hello world", 7, "hello…"},
+		{"привет мир", 5, "прив…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateEscaped(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"a &lt; b", 10, "a &lt; b"},
+		{"ab&amp;cd", 9, "ab&amp;cd"},
+		// Обрезка внутри мнемоники отбрасывает ее целиком
+		{"ab&amp;cd", 5, "ab…"},
+		{"ab&amp;cd", 8, "ab&amp;…"},
+		{"text", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncateEscaped(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncateEscaped(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+
+	long := html.EscapeString(strings.Repeat("<b>&", 2000))
+	got := truncateEscaped(long, telegramMaxLen)
+	if n := len([]rune(got)); n > telegramMaxLen {
+		t.Errorf("truncated text has %d characters, want at most %d", n, telegramMaxLen)
+	}
+	tail := strings.TrimSuffix(got, "…")
+	if i := strings.LastIndexByte(tail, '&'); i >= 0 && !strings.Contains(tail[i:], ";") {
+		t.Errorf("truncated text ends inside an entity: %q", tail[i:])
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	templates, err := ParseTemplates(map[string]string{
+		EventChanged: `{{.Task.Name}}: {{truncate 5 .Task.URL}} {{join .Task.EmptyFields ","}}`,
+	})
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+	text, ok, err := templates.render(Event{Type: EventChanged, Task: &Task{Name: "shop", URL: "https://example.com", EmptyFields: []string{"a", "b"}}})
+	if err != nil || !ok {
+		t.Fatalf("render = %v, %v", ok, err)
+	}
+	if want := "shop: http… a,b"; text != want {
+		t.Errorf("render = %q, want %q", text, want)
+	}
+	if _, ok, _ := templates.render(Event{Type: EventAlert}); ok {
+		t.Error("render used a template for an event type without one")
+	}
+
+	for _, texts := range []map[string]string{
+		{"finished": "x"},
+		{EventAlert: "{{.Task.Name"},
+	} {
+		if _, err := ParseTemplates(texts); err == nil {
+			t.Errorf("ParseTemplates(%v) succeeded, want an error", texts)
+		}
+	}
+}
+
+func TestNewRunEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary Summary
+		want    string
+	}{
+		{name: "clean run", summary: Summary{Total: 2, Succeeded: 2}, want: EventRunSucceeded},
+		{name: "disabled task", summary: Summary{Total: 3, Succeeded: 2, Skipped: 1}, want: EventRunSucceeded},
+		{name: "failed task", summary: Summary{Total: 2, Succeeded: 1, Failed: 1}, want: EventRunFailed},
+		{name: "interrupted run", summary: Summary{Total: 2, Succeeded: 1, Interrupted: 1}, want: EventRunFailed},
+	}
+	for _, tt := range tests {
+		if got := NewRunEvent("r1", tt.summary).Type; got != tt.want {
+			t.Errorf("%s: type = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
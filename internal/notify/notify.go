@@ -0,0 +1,181 @@
+// Package notify сообщает о завершении запусков и повторяющихся ошибках
+// задач во внешние системы: вебхуки и мессенджеры.
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Типы событий.
+const (
+	// EventRunSucceeded - запуск завершен без ошибок задач.
+	EventRunSucceeded = "run_succeeded"
+	// EventRunFailed - в запуске есть упавшие задачи или он прерван.
+	EventRunFailed = "run_failed"
+	// EventTaskFailing - задача упала несколько раз подряд, см. Streaks.
+	EventTaskFailing = "task_failing"
+	// EventSelectorsBroken - задача выполнилась, но часть ее полей пуста во
+	// всех записях: скорее всего, сайт поменял верстку.
+	EventSelectorsBroken = "selectors_broken"
+	// EventChanged - записи задачи изменились по сравнению с прошлым
+	// выполнением, см. Task.Watch.
+	EventChanged = "changed"
+	// EventAlert - записи задачи начали удовлетворять условию из Task.Alerts.
+	EventAlert = "alert"
+)
+
+// Events - все типы событий.
+var Events = []string{EventRunSucceeded, EventRunFailed, EventTaskFailing, EventSelectorsBroken, EventChanged, EventAlert}
+
+// Event - уведомление. Для событий запуска заполнено Summary,
+// для событий задачи - Task.
+type Event struct {
+	Type string
+	Time time.Time
+	// Host - машина, на которой произошло событие.
+	Host    string
+	RunID   string   `json:",omitempty"`
+	Summary *Summary `json:",omitempty"`
+	Task    *Task    `json:",omitempty"`
+}
+
+// Summary - итоги запуска.
+type Summary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	// Skipped - задачи, которые не выполнялись намеренно: отключенные,
+	// дубликаты, свежие или выполненные раньше. На итог запуска не влияют.
+	Skipped int `json:",omitempty"`
+	// Interrupted - задачи, которые не завершились, потому что запуск
+	// прерван или отменен. Такой запуск считается неудачным.
+	Interrupted int `json:",omitempty"`
+	Records     int
+	Duration    string
+	Output      string    `json:",omitempty"`
+	Failures    []Failure `json:",omitempty"`
+}
+
+// Failure - упавшая задача запуска.
+type Failure struct {
+	TaskID string
+	URL    string
+	Error  string
+}
+
+// Task - задача, которая падает несколько раз подряд, перестала находить
+// поля, получила новые значения или значения, подходящие под условие.
+type Task struct {
+	ID   string
+	Name string
+	URL  string
+	// ConsecutiveFailures - сколько раз подряд задача завершилась ошибкой.
+	ConsecutiveFailures int    `json:",omitempty"`
+	Error               string `json:",omitempty"`
+	// EmptyFields - поля, селекторы которых ничего не нашли.
+	EmptyFields []string `json:",omitempty"`
+	// Changes - изменения записей с прошлого выполнения.
+	Changes []Change `json:",omitempty"`
+	// Rule - условие, а Matches - записи, которые начали ему удовлетворять.
+	Rule    string              `json:",omitempty"`
+	Matches []map[string]string `json:",omitempty"`
+}
+
+// Change - изменение записи задачи: Kind - "added", "removed" или
+// "changed", Key - значение поля, по которому сопоставлены записи,
+// или номер записи.
+type Change struct {
+	Kind   string
+	Key    string
+	Fields []FieldChange `json:",omitempty"`
+}
+
+// FieldChange - изменение поля записи.
+type FieldChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// NewRunEvent создает событие завершения запуска по его итогам.
+func NewRunEvent(runID string, summary Summary) Event {
+	typ := EventRunSucceeded
+	if summary.Failed > 0 || summary.Interrupted > 0 {
+		typ = EventRunFailed
+	}
+	return Event{Type: typ, Time: time.Now(), Host: hostname(), RunID: runID, Summary: &summary}
+}
+
+// NewTaskEvent создает событие typ о задаче.
+func NewTaskEvent(typ, runID string, task Task) Event {
+	return Event{Type: typ, Time: time.Now(), Host: hostname(), RunID: runID, Task: &task}
+}
+
+func hostname() string {
+	host, _ := os.Hostname()
+	return host
+}
+
+// Notifier доставляет событие в один канал.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// target - канал и типы событий, на которые он подписан.
+type target struct {
+	name     string
+	notifier Notifier
+	events   []string
+}
+
+// Dispatcher рассылает события по каналам. Ошибки доставки пишутся в лог
+// и не прерывают запуск.
+type Dispatcher struct {
+	targets []target
+	logger  *slog.Logger
+}
+
+// NewDispatcher создает рассылку без каналов, см. Add.
+func NewDispatcher(logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{logger: logger}
+}
+
+// Add подписывает канал на события events, пустой список - на все.
+// name называет канал в логе.
+func (d *Dispatcher) Add(name string, n Notifier, events []string) {
+	d.targets = append(d.targets, target{name: name, notifier: n, events: events})
+}
+
+// Enabled сообщает, что есть хотя бы один канал.
+func (d *Dispatcher) Enabled() bool {
+	return d != nil && len(d.targets) > 0
+}
+
+// Send отправляет событие во все подписанные каналы одновременно
+// и ждет, пока они ответят или отменят ctx.
+func (d *Dispatcher) Send(ctx context.Context, e Event) {
+	if !d.Enabled() {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, t := range d.targets {
+		if len(t.events) > 0 && !slices.Contains(t.events, e.Type) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := t.notifier.Notify(ctx, e); err != nil {
+				d.logger.Warn("⭕ Failed to send notification", "channel:", t.name, "event:", e.Type, "error:", err)
+				return
+			}
+			d.logger.Debug("Notification sent", "channel:", t.name, "event:", e.Type)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// slackEscaper экранирует символы разметки Slack.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Slack отправляет событие в incoming webhook Slack. Для типов событий
+// с шаблоном в Templates текст сообщения - результат шаблона в разметке
+// Slack (mrkdwn), для остальных - стандартное сообщение. Строки события
+// экранируются до шаблона, чтобы собранные значения вроде <!channel>
+// или <url|текст> не упоминали канал и не подменяли ссылки.
+type Slack struct {
+	URL       string
+	Templates Templates
+	Client    *http.Client
+}
+
+func (s *Slack) Notify(ctx context.Context, e Event) error {
+	text, ok, err := s.Templates.render(slackEvent(e))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		title, lines := message(e)
+		text = "*" + slackEscaper.Replace(title) + "*"
+		if len(lines) > 0 {
+			text += "\n" + slackEscaper.Replace(strings.Join(lines, "\n"))
+		}
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return postJSON(ctx, s.Client, s.URL, nil, body)
+}
+
+// slackEvent возвращает копию события с экранированными строками.
+func slackEvent(e Event) Event {
+	esc := slackEscaper.Replace
+	e.Host, e.RunID = esc(e.Host), esc(e.RunID)
+	if e.Summary != nil {
+		s := *e.Summary
+		s.Output = esc(s.Output)
+		s.Failures = make([]Failure, len(e.Summary.Failures))
+		for i, f := range e.Summary.Failures {
+			s.Failures[i] = Failure{TaskID: esc(f.TaskID), URL: esc(f.URL), Error: esc(f.Error)}
+		}
+		e.Summary = &s
+	}
+	if e.Task != nil {
+		t := *e.Task
+		t.ID, t.Name, t.URL, t.Error, t.Rule = esc(t.ID), esc(t.Name), esc(t.URL), esc(t.Error), esc(t.Rule)
+		t.EmptyFields = make([]string, len(e.Task.EmptyFields))
+		for i, field := range e.Task.EmptyFields {
+			t.EmptyFields[i] = esc(field)
+		}
+		t.Changes = make([]Change, len(e.Task.Changes))
+		for i, c := range e.Task.Changes {
+			fields := make([]FieldChange, len(c.Fields))
+			for j, f := range c.Fields {
+				fields[j] = FieldChange{Name: esc(f.Name), Old: esc(f.Old), New: esc(f.New)}
+			}
+			t.Changes[i] = Change{Kind: esc(c.Kind), Key: esc(c.Key), Fields: fields}
+		}
+		t.Matches = make([]map[string]string, len(e.Task.Matches))
+		for i, m := range e.Task.Matches {
+			t.Matches[i] = make(map[string]string, len(m))
+			for k, v := range m {
+				t.Matches[i][esc(k)] = esc(v)
+			}
+		}
+		e.Task = &t
+	}
+	return e
+}
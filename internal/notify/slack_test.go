@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// slackServer принимает сообщения вебхука и сохраняет тексты.
+func slackServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		var body struct{ Text string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		texts = append(texts, body.Text)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &texts
+}
+
+func TestSlack(t *testing.T) {
+	srv, texts := slackServer(t)
+	templates, err := ParseTemplates(map[string]string{
+		EventSelectorsBroken: `:warning: *{{.Task.Name}}*: {{join .Task.EmptyFields ", "}}`,
+	})
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+	slack := &Slack{URL: srv.URL, Templates: templates}
+
+	events := []Event{
+		{Type: EventSelectorsBroken, Task: &Task{Name: "shop", EmptyFields: []string{"Price", "<Title>"}}},
+		{Type: EventRunFailed, Host: "box", Summary: &Summary{Total: 1, Failed: 1,
+			Failures: []Failure{{TaskID: "t1", URL: "https://example.com/?a=1&b=2", Error: "<!here> 500"}}}},
+	}
+	for _, e := range events {
+		if err := slack.Notify(context.Background(), e); err != nil {
+			t.Fatalf("Notify(%s): %v", e.Type, err)
+		}
+	}
+
+	want := []string{
+		":warning: *shop*: Price, &lt;Title&gt;",
+		"*❌ Run failed on box*\nTasks: 1 total, 0 succeeded, 1 failed\nRecords: 0\n• t1 https://example.com/?a=1&amp;b=2: &lt;!here&gt; 500",
+	}
+	if len(*texts) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(*texts), len(want))
+	}
+	for i := range want {
+		if (*texts)[i] != want[i] {
+			t.Errorf("message %d = %q, want %q", i, (*texts)[i], want[i])
+		}
+	}
+}
+
+func TestSlackEscapesTemplateValues(t *testing.T) {
+	var got struct{ Text string }
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	templates, err := ParseTemplates(map[string]string{
+		EventAlert: `{{.Task.Error}} {{range .Task.Matches}}{{.price}}{{end}} {{escape "<b>"}}`,
+	})
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+	slack := &Slack{URL: srv.URL, Templates: templates}
+	event := Event{Type: EventAlert, Task: &Task{
+		Error:   "<!channel> down",
+		Matches: []map[string]string{{"price": "<https://evil.example|bank>"}},
+	}}
+	if err := slack.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if want := "&lt;!channel&gt; down &lt;https://evil.example|bank&gt; &lt;b&gt;"; got.Text != want {
+		t.Errorf("text = %q, want %q", got.Text, want)
+	}
+	if event.Task.Error != "<!channel> down" {
+		t.Errorf("Notify changed the event: %q", event.Task.Error)
+	}
+}
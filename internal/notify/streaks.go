@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Streaks считает, сколько раз подряд у каждой задачи повторяется проблема:
+// ошибка или пустые поля. С файлом счетчики переживают перезапуск процесса,
+// что нужно запускам из cron.
+type Streaks struct {
+	path string
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// OpenStreaks читает счетчики из файла path. Пустой путь хранит их
+// только в памяти, файла, которого еще нет, - пустые счетчики.
+func OpenStreaks(path string) (*Streaks, error) {
+	s := &Streaks{path: path, counts: make(map[string]int)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read failure streaks: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.counts); err != nil {
+		return nil, fmt.Errorf("failed to parse failure streaks %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Observe учитывает завершение задачи с ключом name и возвращает, сколько
+// раз подряд проблема повторилась, включая этот.
+func (s *Streaks) Observe(name string, failed bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !failed {
+		delete(s.counts, name)
+		return 0
+	}
+	s.counts[name]++
+	return s.counts[name]
+}
+
+// Save записывает счетчики в файл, если он задан.
+func (s *Streaks) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.counts, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode failure streaks: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create failure streaks directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write failure streaks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+const (
+	// telegramAPI - адрес Bot API.
+	telegramAPI = "https://api.telegram.org"
+	// telegramMaxLen - максимальная длина сообщения Telegram.
+	telegramMaxLen = 4096
+)
+
+// Telegram отправляет событие сообщением бота в чат.
+type Telegram struct {
+	Token string
+	// ChatID - числовой идентификатор чата или "@channel".
+	ChatID string
+	Client *http.Client
+}
+
+func (t *Telegram) Notify(ctx context.Context, e Event) error {
+	title, lines := message(e)
+	text := "<b>" + html.EscapeString(title) + "</b>"
+	if len(lines) > 0 {
+		// Лимит считается по отправленному тексту, поэтому обрезаем после экранирования
+		text += "\n" + truncateEscaped(html.EscapeString(strings.Join(lines, "\n")), telegramMaxLen-len([]rune(text))-1)
+	}
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  t.ChatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return postJSON(ctx, t.Client, telegramAPI+"/bot"+t.Token+"/sendMessage", nil, body)
+}
+
+// truncateEscaped обрезает экранированный HTML-текст s до n символов,
+// не разрывая мнемоники вроде &amp;.
+func truncateEscaped(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	cut := string(r[:n-1])
+	if i := strings.LastIndexByte(cut, '&'); i >= 0 && !strings.Contains(cut[i:], ";") {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// redirectTransport отправляет все запросы на тестовый сервер, сохраняя путь.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// telegramServer принимает сообщения бота и сохраняет путь и тело последнего.
+func telegramServer(t *testing.T, status int) (*http.Client, *string, *map[string]any) {
+	t.Helper()
+	var path string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return &http.Client{Transport: redirectTransport{target}}, &path, &body
+}
+
+func TestTelegram(t *testing.T) {
+	client, path, body := telegramServer(t, http.StatusOK)
+	tg := &Telegram{Token: "123:abc", ChatID: "@alerts", Client: client}
+
+	event := Event{Type: EventTaskFailing, Task: &Task{Name: "a<b>", URL: "https://example.com/?a=1&b=2", ConsecutiveFailures: 2, Error: "<html> 503"}}
+	if err := tg.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if *path != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %q, want the sendMessage method of the bot", *path)
+	}
+	want := "<b>🚨 Task a&lt;b&gt; failed 2 times in a row</b>\nURL: https://example.com/?a=1&amp;b=2\nError: &lt;html&gt; 503"
+	if got := (*body)["text"]; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if (*body)["chat_id"] != "@alerts" || (*body)["parse_mode"] != "HTML" || (*body)["disable_web_page_preview"] != true {
+		t.Errorf("body = %v, want the chat, HTML parse mode and no previews", *body)
+	}
+}
+
+func TestTelegramLongMessage(t *testing.T) {
+	client, _, body := telegramServer(t, http.StatusOK)
+	tg := &Telegram{Token: "t", ChatID: "1", Client: client}
+
+	event := Event{Type: EventTaskFailing, Task: &Task{Name: "a", Error: strings.Repeat("<&>", 3000)}}
+	if err := tg.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	text, _ := (*body)["text"].(string)
+	if n := len([]rune(text)); n > telegramMaxLen {
+		t.Errorf("text has %d characters, want at most %d", n, telegramMaxLen)
+	}
+	if !strings.HasSuffix(text, "…") || strings.HasSuffix(strings.TrimSuffix(text, "…"), "&") {
+		t.Errorf("text ends with %q, want it cut after a whole entity", text[len(text)-20:])
+	}
+}
+
+func TestTelegramError(t *testing.T) {
+	client, _, _ := telegramServer(t, http.StatusBadRequest)
+	tg := &Telegram{Token: "secret-token", ChatID: "1", Client: client}
+
+	err := tg.Notify(context.Background(), Event{Type: EventRunSucceeded, Summary: &Summary{}})
+	if err == nil {
+		t.Fatal("Notify succeeded on 400, want an error")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("error %q contains the bot token", err)
+	}
+}
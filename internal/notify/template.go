@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Templates - шаблоны сообщений по типам событий. Шаблон получает Event,
+// кроме стандартных функций доступны join, truncate и escape - экранирование
+// разметки Slack для строк, собранных в самом шаблоне.
+type Templates map[string]*template.Template
+
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"truncate": func(n int, s string) string { return truncate(s, n) },
+	"escape":   slackEscaper.Replace,
+}
+
+// ParseTemplates разбирает шаблоны: тип события -> текст шаблона.
+func ParseTemplates(texts map[string]string) (Templates, error) {
+	templates := make(Templates, len(texts))
+	for typ, text := range texts {
+		if !slices.Contains(Events, typ) {
+			return nil, fmt.Errorf("template for unknown event %q, expected one of %s", typ, strings.Join(Events, ", "))
+		}
+		t, err := template.New(typ).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %w", typ, err)
+		}
+		templates[typ] = t
+	}
+	return templates, nil
+}
+
+// render возвращает текст события по шаблону его типа. ok - false, если
+// шаблона для типа нет.
+func (t Templates) render(e Event) (text string, ok bool, err error) {
+	tmpl, ok := t[e.Type]
+	if !ok {
+		return "", false, nil
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, e); err != nil {
+		return "", true, fmt.Errorf("failed to render %s template: %w", e.Type, err)
+	}
+	return b.String(), true, nil
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// webhookTimeout ограничивает одну попытку доставки.
+	webhookTimeout = 10 * time.Second
+	// webhookAttempts - сколько раз пробовать доставить событие.
+	webhookAttempts = 3
+	// webhookRetryDelay - пауза перед второй попыткой, дальше она удваивается.
+	webhookRetryDelay = time.Second
+)
+
+// Webhook отправляет событие POST-запросом с JSON события в теле.
+// Ошибки сети и ответы 5xx повторяются.
+type Webhook struct {
+	URL string
+	// Headers добавляются к запросу, например для авторизации.
+	Headers map[string]string
+	Client  *http.Client
+}
+
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return postJSON(ctx, w.Client, w.URL, w.Headers, body)
+}
+
+// postJSON отправляет body на endpoint с повторами. Используется и каналами,
+// у которых свой формат сообщения.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body []byte) error {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	delay := webhookRetryDelay
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		var retry bool
+		if retry, err = post(ctx, client, endpoint, headers, body); err == nil || !retry {
+			return err
+		}
+		if attempt == webhookAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return fmt.Errorf("%w (after %d attempts)", err, webhookAttempts)
+}
+
+// post делает одну попытку. retry сообщает, что ошибку имеет смысл повторить.
+func post(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// В пути адреса бывает токен канала, поэтому в ошибке только хост
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return ctx.Err() == nil, fmt.Errorf("failed to reach %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests,
+			fmt.Errorf("%s responded with %s: %s", req.URL.Host, resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return false, nil
+}
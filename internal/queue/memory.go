@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// Memory - очередь в памяти процесса. Подходит для запуска одним
+// экземпляром и для проверки настроек без внешних сервисов.
+type Memory struct {
+	mu     sync.Mutex
+	tasks  []taskconfig.Task
+	notify chan struct{}
+	closed bool
+}
+
+// NewMemory создает пустую очередь в памяти.
+func NewMemory() *Memory {
+	return &Memory{notify: make(chan struct{})}
+}
+
+// Push кладет задачу в конец очереди.
+func (m *Memory) Push(ctx context.Context, task taskconfig.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	m.tasks = append(m.tasks, task)
+	m.wake()
+	return nil
+}
+
+// Pop ждет задачу из начала очереди. Nack возвращает задачу в конец.
+func (m *Memory) Pop(ctx context.Context) (*Delivery, error) {
+	for {
+		m.mu.Lock()
+		if m.closed {
+			m.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if len(m.tasks) > 0 {
+			task := m.tasks[0]
+			m.tasks = m.tasks[1:]
+			m.mu.Unlock()
+			return &Delivery{
+				Task: task,
+				ack:  func(context.Context) error { return nil },
+				nack: func(ctx context.Context) error { return m.Push(ctx, task) },
+			}, nil
+		}
+		notify := m.notify
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// Close закрывает очередь и будит ожидающих в Pop.
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.closed {
+		m.closed = true
+		m.wake()
+	}
+	return nil
+}
+
+// wake будит всех ожидающих в Pop. Вызывается под m.mu.
+func (m *Memory) wake() {
+	close(m.notify)
+	m.notify = make(chan struct{})
+}
@@ -0,0 +1,67 @@
+// Package queue содержит очереди задач конфига, общие для нескольких
+// экземпляров ish3ikin: один процесс кладет задачи, другие их выполняют.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// ErrClosed возвращается при работе с закрытой очередью.
+var ErrClosed = errors.New("queue is closed")
+
+// Queue - очередь задач конфига.
+type Queue interface {
+	// Push кладет задачу в очередь.
+	Push(ctx context.Context, task taskconfig.Task) error
+	// Pop ждет следующую задачу, пока не отменен ctx.
+	Pop(ctx context.Context) (*Delivery, error)
+	// Close освобождает соединение с очередью. Задачи в общей очереди остаются.
+	Close() error
+}
+
+// Delivery - задача, полученная из очереди. Пока она не подтверждена
+// через Ack, другие потребители ее не получат. Nack возвращает задачу
+// в очередь, чтобы ее выполнил кто-то другой.
+type Delivery struct {
+	Task taskconfig.Task
+	ack  func(ctx context.Context) error
+	nack func(ctx context.Context) error
+}
+
+// Ack подтверждает, что задача обработана, и удаляет ее из очереди.
+func (d *Delivery) Ack(ctx context.Context) error {
+	return d.ack(ctx)
+}
+
+// Nack возвращает задачу в очередь.
+func (d *Delivery) Nack(ctx context.Context) error {
+	return d.nack(ctx)
+}
+
+// Open подключается к очереди по адресу:
+//
+//	memory://                                  - очередь в памяти процесса, для тестов
+//	                                             и встраивания: другие процессы ее не видят
+//	redis://[:password@]host:port/db?key=name  - список Redis
+//	sqs://sqs.<region>.amazonaws.com/<account>/<queue> - очередь Amazon SQS
+func Open(ctx context.Context, rawURL string) (Queue, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse queue url: %w", err)
+	}
+	switch u.Scheme {
+	case "memory":
+		return NewMemory(), nil
+	case "redis", "rediss":
+		return openRedis(u)
+	case "sqs":
+		return openSQS(ctx, u)
+	default:
+		return nil, fmt.Errorf("unsupported queue scheme %q", u.Scheme)
+	}
+}
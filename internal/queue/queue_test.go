@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// testQueue проверяет Push -> Pop -> Ack/Nack на любой очереди.
+func testQueue(t *testing.T, q Queue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, id := range []string{"t1", "t2"} {
+		if err := q.Push(ctx, taskconfig.Task{ID: id, URL: "https://example.com/" + id}); err != nil {
+			t.Fatalf("Push(%s): %v", id, err)
+		}
+	}
+
+	first, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("Pop: %v", err)
+	}
+	if first.Task.ID != "t1" || first.Task.URL != "https://example.com/t1" {
+		t.Fatalf("Pop = %+v, want t1 first", first.Task)
+	}
+	if err := first.Nack(ctx); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+
+	// Возвращенная задача выполняется снова, после уже ждущих
+	var got []string
+	for range 2 {
+		d, err := q.Pop(ctx)
+		if err != nil {
+			t.Fatalf("Pop: %v", err)
+		}
+		got = append(got, d.Task.ID)
+		if err := d.Ack(ctx); err != nil {
+			t.Fatalf("Ack: %v", err)
+		}
+	}
+	if got[0] != "t2" || got[1] != "t1" {
+		t.Errorf("popped %v after Nack, want [t2 t1]", got)
+	}
+
+	// Подтвержденные задачи не возвращаются
+	short, cancelShort := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelShort()
+	if d, err := q.Pop(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pop on an empty queue = %v, %v, want the context deadline", d, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	q := NewMemory()
+	testQueue(t, q)
+
+	q.Close()
+	if _, err := q.Pop(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Pop after Close = %v, want ErrClosed", err)
+	}
+	if err := q.Push(context.Background(), taskconfig.Task{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Push after Close = %v, want ErrClosed", err)
+	}
+}
+
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	q := NewRedis(redis.NewClient(&redis.Options{Addr: srv.Addr()}), "test:tasks")
+	t.Cleanup(func() { q.Close() })
+	return q, srv
+}
+
+func TestRedis(t *testing.T) {
+	q, srv := newTestRedis(t)
+	testQueue(t, q)
+
+	for _, key := range []string{"test:tasks", "test:tasks:processing"} {
+		if srv.Exists(key) {
+			items, _ := srv.List(key)
+			t.Errorf("%s still holds %v after every task was acked", key, items)
+		}
+	}
+}
+
+func TestRedisKeepsTaskInProcessing(t *testing.T) {
+	q, srv := newTestRedis(t)
+	ctx := context.Background()
+	if err := q.Push(ctx, taskconfig.Task{ID: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	d, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Пока задача не подтверждена, ее видно в списке обрабатываемых
+	if items, err := srv.List("test:tasks:processing"); err != nil || len(items) != 1 {
+		t.Errorf("processing list = %v, %v, want the popped task", items, err)
+	}
+	if err := d.Ack(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Exists("test:tasks:processing") {
+		t.Error("acked task is still in the processing list")
+	}
+}
+
+func TestRedisResults(t *testing.T) {
+	q, srv := newTestRedis(t)
+	results := q.Results(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Битый итог пропускается
+	srv.Lpush("test:tasks:results", "not json")
+	want := Result{TaskID: "t1", URL: "https://example.com", Records: []map[string]string{{"Title": "a"}}, Attempts: 2, Worker: "w1"}
+	if err := results.Publish(ctx, want); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	got, err := results.Next(ctx)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if got.TaskID != want.TaskID || got.URL != want.URL || got.Records[0]["Title"] != "a" || got.Attempts != 2 || got.Worker != "w1" {
+		t.Errorf("Next = %+v, want %+v", got, want)
+	}
+}
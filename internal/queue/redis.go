@@ -0,0 +1,172 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+const (
+	// defaultRedisKey - список задач, если в адресе нет параметра key.
+	defaultRedisKey = "ish3ikin:tasks"
+	// redisPollTimeout ограничивает одно блокирующее ожидание, чтобы Pop
+	// вовремя замечал отмену контекста.
+	redisPollTimeout = 5 * time.Second
+)
+
+// Redis - очередь на списке Redis. Полученные задачи переносятся в список
+// <key>:processing и удаляются оттуда после Ack, поэтому задачи упавшего
+// потребителя можно найти и вернуть в очередь вручную.
+type Redis struct {
+	client     *redis.Client
+	key        string
+	processing string
+}
+
+// openRedis подключается к Redis по адресу redis://host:port/db?key=name.
+func openRedis(u *url.URL) (*Redis, error) {
+	q := u.Query()
+	key := q.Get("key")
+	if key == "" {
+		key = defaultRedisKey
+	}
+	q.Del("key")
+	u.RawQuery = q.Encode()
+
+	opts, err := redis.ParseURL(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+	return NewRedis(redis.NewClient(opts), key), nil
+}
+
+// NewRedis создает очередь на списке key.
+func NewRedis(client *redis.Client, key string) *Redis {
+	return &Redis{
+		client:     client,
+		key:        key,
+		processing: key + ":processing",
+	}
+}
+
+// Push кладет задачу в очередь.
+func (r *Redis) Push(ctx context.Context, task taskconfig.Task) error {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+	if err := r.client.LPush(ctx, r.key, payload).Err(); err != nil {
+		return fmt.Errorf("failed to push task: %w", err)
+	}
+	return nil
+}
+
+// Pop ждет задачу и переносит ее в список обрабатываемых.
+func (r *Redis) Pop(ctx context.Context) (*Delivery, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		payload, err := r.client.BLMove(ctx, r.key, r.processing, "RIGHT", "LEFT", redisPollTimeout).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to pop task: %w", err)
+		}
+
+		var task taskconfig.Task
+		if err := json.Unmarshal([]byte(payload), &task); err != nil {
+			// Битую задачу не вернуть в работу, убираем ее, чтобы не копилась.
+			r.client.LRem(ctx, r.processing, 1, payload)
+			return nil, fmt.Errorf("failed to decode task: %w", err)
+		}
+		return &Delivery{
+			Task: task,
+			ack: func(ctx context.Context) error {
+				return r.client.LRem(ctx, r.processing, 1, payload).Err()
+			},
+			nack: func(ctx context.Context) error {
+				_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+					p.LRem(ctx, r.processing, 1, payload)
+					p.LPush(ctx, r.key, payload)
+					return nil
+				})
+				return err
+			},
+		}, nil
+	}
+}
+
+// Close закрывает соединение с Redis.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// Results возвращает канал итогов задач на списке <key>:results. Канал
+// использует соединение очереди, закрывать нужно что-то одно.
+func (r *Redis) Results(logger *slog.Logger) *RedisResults {
+	return &RedisResults{client: r.client, key: r.key + ":results", logger: logger}
+}
+
+// RedisResults - канал итогов задач на списке Redis.
+type RedisResults struct {
+	client *redis.Client
+	key    string
+	logger *slog.Logger
+}
+
+// Publish кладет итог задачи в список.
+func (r *RedisResults) Publish(ctx context.Context, res Result) error {
+	payload, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	if err := r.client.LPush(ctx, r.key, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish result: %w", err)
+	}
+	return nil
+}
+
+// Next ждет итог и забирает его из списка. Битый итог уже забран
+// из списка, поэтому он пропускается с предупреждением.
+func (r *RedisResults) Next(ctx context.Context) (Result, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		reply, err := r.client.BRPop(ctx, redisPollTimeout, r.key).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return Result{}, ctx.Err()
+			}
+			return Result{}, fmt.Errorf("failed to take result: %w", err)
+		}
+
+		// BRPOP возвращает имя списка и значение
+		var res Result
+		if err := json.Unmarshal([]byte(reply[1]), &res); err != nil {
+			r.logger.Warn("⭕ Skipping undecodable task result", "list:", r.key, "error:", err)
+			continue
+		}
+		return res, nil
+	}
+}
+
+// Close закрывает соединение с Redis.
+func (r *RedisResults) Close() error {
+	return r.client.Close()
+}
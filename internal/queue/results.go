@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// Result - итог задачи из очереди, который исполнитель отправляет обратно
+// координатору. Задачу итог называет только ID и URL из очереди: полная
+// задача несла бы раскрытые на исполнителе секреты.
+type Result struct {
+	TaskID  string
+	URL     string
+	Records []map[string]string `json:",omitempty"`
+	// Error - текст ошибки, пустой у успешной задачи.
+	Error string `json:",omitempty"`
+	// Duplicate - задача не выполнялась, потому что исполнитель уже
+	// выполнял задачу с тем же URL.
+	Duplicate bool `json:",omitempty"`
+	Attempts  int
+	Duration  time.Duration
+	// Worker - узел, выполнивший задачу.
+	Worker string
+}
+
+// Results - канал итогов задач от исполнителей к координатору. Итоги
+// не привязаны к запуску, поэтому одну очередь в каждый момент
+// должен собирать один координатор.
+type Results interface {
+	// Publish отправляет итог задачи.
+	Publish(ctx context.Context, r Result) error
+	// Next ждет следующий итог, пока не отменен ctx.
+	Next(ctx context.Context) (Result, error)
+	// Close освобождает соединение.
+	Close() error
+}
+
+// OpenResults подключается к каналу итогов очереди с адресом rawURL.
+// Итоги поддерживает только очередь Redis: они хранятся в списке
+// с ключом очереди и суффиксом ":results".
+func OpenResults(rawURL string, logger *slog.Logger) (Results, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse queue url: %w", err)
+	}
+	switch u.Scheme {
+	case "redis", "rediss":
+		q, err := openRedis(u)
+		if err != nil {
+			return nil, err
+		}
+		return q.Results(logger), nil
+	default:
+		return nil, fmt.Errorf("queue scheme %q cannot send results back, use redis://", u.Scheme)
+	}
+}
@@ -0,0 +1,166 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+const (
+	// sqsWaitSeconds - время long polling одного запроса, максимум для SQS.
+	sqsWaitSeconds = 20
+	// sqsVisibility - на сколько полученное сообщение скрывается от других
+	// потребителей. Пока задача выполняется, срок продлевается каждые
+	// sqsHeartbeat, поэтому упавший исполнитель задерживает задачу ненадолго.
+	sqsVisibility = 60 * time.Second
+	sqsHeartbeat  = 20 * time.Second
+)
+
+// sqsAPI - методы клиента SQS, которыми пользуется очередь.
+type sqsAPI interface {
+	SendMessage(ctx context.Context, in *sqs.SendMessageInput, opts ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	ReceiveMessage(ctx context.Context, in *sqs.ReceiveMessageInput, opts ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, in *sqs.DeleteMessageInput, opts ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, in *sqs.ChangeMessageVisibilityInput, opts ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+}
+
+// SQS - очередь Amazon SQS. Полученное сообщение скрыто от других
+// потребителей, пока его задача не подтверждена: срок видимости
+// продлевается, сколько бы задача ни выполнялась. Ack удаляет сообщение,
+// Nack сразу делает его снова видимым.
+type SQS struct {
+	client    sqsAPI
+	queueURL  string
+	heartbeat time.Duration
+}
+
+// openSQS подключается к очереди sqs://sqs.<region>.amazonaws.com/<account>/<queue>.
+// Учетные данные берутся из стандартного окружения AWS.
+func openSQS(ctx context.Context, u *url.URL) (*SQS, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if parts := strings.Split(u.Hostname(), "."); len(parts) > 2 && parts[0] == "sqs" {
+		opts = append(opts, awsconfig.WithRegion(parts[1]))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	queueURL := *u
+	queueURL.Scheme = "https"
+	return NewSQS(sqs.NewFromConfig(cfg), queueURL.String()), nil
+}
+
+// NewSQS создает очередь по ее URL.
+func NewSQS(client *sqs.Client, queueURL string) *SQS {
+	return &SQS{client: client, queueURL: queueURL, heartbeat: sqsHeartbeat}
+}
+
+// Push отправляет задачу сообщением в очередь.
+func (s *SQS) Push(ctx context.Context, task taskconfig.Task) error {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+	_, err = s.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.queueURL),
+		MessageBody: aws.String(string(payload)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push task: %w", err)
+	}
+	return nil
+}
+
+// Pop ждет сообщение с задачей через long polling.
+func (s *SQS) Pop(ctx context.Context) (*Delivery, error) {
+	for {
+		out, err := s.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.queueURL),
+			MaxNumberOfMessages: 1,
+			WaitTimeSeconds:     sqsWaitSeconds,
+			VisibilityTimeout:   int32(sqsVisibility / time.Second),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to receive task: %w", err)
+		}
+		if len(out.Messages) == 0 {
+			continue
+		}
+
+		msg := out.Messages[0]
+		handle := msg.ReceiptHandle
+		var task taskconfig.Task
+		if err := json.Unmarshal([]byte(aws.ToString(msg.Body)), &task); err != nil {
+			// Битое сообщение оставляем в очереди: SQS переложит его
+			// в dead-letter очередь, если она настроена.
+			return nil, fmt.Errorf("failed to decode task: %w", err)
+		}
+		stop := s.keepHidden(handle)
+		return &Delivery{
+			Task: task,
+			ack: func(ctx context.Context) error {
+				stop()
+				_, err := s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+					QueueUrl:      aws.String(s.queueURL),
+					ReceiptHandle: handle,
+				})
+				return err
+			},
+			nack: func(ctx context.Context) error {
+				stop()
+				_, err := s.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+					QueueUrl:          aws.String(s.queueURL),
+					ReceiptHandle:     handle,
+					VisibilityTimeout: 0,
+				})
+				return err
+			},
+		}, nil
+	}
+}
+
+// keepHidden продлевает видимость сообщения handle, пока не вызвана
+// возвращенная функция остановки.
+func (s *SQS) keepHidden(handle *string) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Неудачное продление повторится на следующем тике
+				s.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+					QueueUrl:          aws.String(s.queueURL),
+					ReceiptHandle:     handle,
+					VisibilityTimeout: int32(sqsVisibility / time.Second),
+				})
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Close ничего не делает: клиент SQS не держит соединений.
+func (s *SQS) Close() error {
+	return nil
+}
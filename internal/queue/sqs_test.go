@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// sqsStub отдает одно сообщение и запоминает запросы к нему.
+type sqsStub struct {
+	mu         sync.Mutex
+	body       string
+	receive    *sqs.ReceiveMessageInput
+	visibility []int32
+	deleted    bool
+}
+
+func (s *sqsStub) SendMessage(context.Context, *sqs.SendMessageInput, ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func (s *sqsStub) ReceiveMessage(_ context.Context, in *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receive = in
+	return &sqs.ReceiveMessageOutput{Messages: []types.Message{{Body: aws.String(s.body), ReceiptHandle: aws.String("h1")}}}, nil
+}
+
+func (s *sqsStub) DeleteMessage(context.Context, *sqs.DeleteMessageInput, ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = true
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (s *sqsStub) ChangeMessageVisibility(_ context.Context, in *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visibility = append(s.visibility, in.VisibilityTimeout)
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func (s *sqsStub) calls() []int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int32(nil), s.visibility...)
+}
+
+func TestSQSKeepsMessageHidden(t *testing.T) {
+	for _, ack := range []bool{true, false} {
+		body, _ := json.Marshal(taskconfig.Task{ID: "t1", URL: "https://example.com"})
+		stub := &sqsStub{body: string(body)}
+		q := &SQS{client: stub, queueURL: "https://sqs.example/q", heartbeat: 5 * time.Millisecond}
+
+		d, err := q.Pop(context.Background())
+		if err != nil {
+			t.Fatalf("Pop: %v", err)
+		}
+		if d.Task.ID != "t1" || stub.receive.VisibilityTimeout != int32(sqsVisibility/time.Second) {
+			t.Fatalf("Pop = %+v with visibility %d", d.Task, stub.receive.VisibilityTimeout)
+		}
+		time.Sleep(30 * time.Millisecond)
+		if ack {
+			err = d.Ack(context.Background())
+		} else {
+			err = d.Nack(context.Background())
+		}
+		if err != nil {
+			t.Fatalf("settle: %v", err)
+		}
+		settled := stub.calls()
+		time.Sleep(20 * time.Millisecond)
+		if calls := stub.calls(); len(calls) != len(settled) {
+			t.Errorf("visibility changed after the task was settled: %v", calls[len(settled):])
+		}
+
+		beats := settled
+		if !ack {
+			if len(settled) == 0 || settled[len(settled)-1] != 0 {
+				t.Fatalf("Nack did not make the message visible: %v", settled)
+			}
+			beats = settled[:len(settled)-1]
+		} else if !stub.deleted {
+			t.Error("Ack did not delete the message")
+		}
+		if len(beats) < 2 {
+			t.Errorf("visibility extended %d times while the task ran, want at least 2", len(beats))
+		}
+		for _, v := range beats {
+			if v != int32(sqsVisibility/time.Second) {
+				t.Errorf("heartbeat set visibility to %d, want %d", v, int32(sqsVisibility/time.Second))
+			}
+		}
+	}
+}
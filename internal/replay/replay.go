@@ -0,0 +1,142 @@
+// Package replay записывает ответы сайтов на диск и отдает их обратно
+// без сети, чтобы селекторы можно было проверять на одних и тех же
+// страницах, например в CI.
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRecorded возвращается, если ответа на запрос нет в записи.
+var ErrNotRecorded = errors.New("response is not recorded")
+
+// Mode - режим работы хранилища.
+type Mode int
+
+const (
+	// Record сохраняет ответы сайтов.
+	Record Mode = iota + 1
+	// Replay отдает сохраненные ответы вместо сети.
+	Replay
+)
+
+// Response - записанный ответ на запрос. Тело хранится распакованным.
+type Response struct {
+	Method string
+	URL    string
+	Status int
+	Header map[string]string
+	Body   []byte
+}
+
+// Store - каталог записанных ответов. Ответ хранится в отдельном файле,
+// имя которого - хеш метода и URL запроса, поэтому один ответ служит
+// всем задачам с тем же адресом.
+type Store struct {
+	dir  string
+	mode Mode
+}
+
+// Open открывает каталог записей. При записи каталог создается.
+func Open(dir string, mode Mode) (*Store, error) {
+	switch mode {
+	case Record:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create recording directory: %w", err)
+		}
+	case Replay:
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open recording: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("recording %s is not a directory", dir)
+		}
+	default:
+		return nil, fmt.Errorf("unknown replay mode %d", mode)
+	}
+	return &Store{dir: dir, mode: mode}, nil
+}
+
+// Replaying сообщает, что ответы берутся из записи, а не из сети.
+func (s *Store) Replaying() bool {
+	return s.mode == Replay
+}
+
+// Save сохраняет ответ, заменяя прежнюю запись того же запроса.
+func (s *Store) Save(resp Response) error {
+	resp.Header = cleanHeader(resp.Header)
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+
+	// Пишем через временный файл, чтобы прерванная запись не оставила
+	// половину ответа.
+	path := s.path(resp.Method, resp.URL)
+	tmp, err := os.CreateTemp(s.dir, ".response-*")
+	if err != nil {
+		return fmt.Errorf("failed to save response: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save response: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save response: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save response: %w", err)
+	}
+	return nil
+}
+
+// Load возвращает записанный ответ на запрос или ErrNotRecorded.
+func (s *Store) Load(method, url string) (Response, error) {
+	data, err := os.ReadFile(s.path(method, url))
+	if errors.Is(err, os.ErrNotExist) {
+		return Response{}, fmt.Errorf("%s %s: %w", method, url, ErrNotRecorded)
+	}
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return Response{}, fmt.Errorf("failed to decode response of %s: %w", url, err)
+	}
+	return resp, nil
+}
+
+func (s *Store) path(method, url string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(method) + " " + url))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:12])+".json")
+}
+
+// skippedHeaders описывают передачу исходного тела и неверны для
+// распакованного тела из записи.
+var skippedHeaders = []string{"Content-Encoding", "Content-Length", "Transfer-Encoding"}
+
+func cleanHeader(header map[string]string) map[string]string {
+	clean := make(map[string]string, len(header))
+	for name, value := range header {
+		skip := false
+		for _, skipped := range skippedHeaders {
+			if strings.EqualFold(name, skipped) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			clean[name] = value
+		}
+	}
+	return clean
+}
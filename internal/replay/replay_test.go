@@ -0,0 +1,85 @@
+package replay
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", url, err)
+	}
+	return resp, string(body)
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Page", req.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "<h1>"+req.URL.Path+"</h1>")
+	}))
+	url := srv.URL + "/news"
+
+	recorder, err := Open(dir, Record)
+	if err != nil {
+		t.Fatalf("Open(Record): %v", err)
+	}
+	resp, body := get(t, &http.Client{Transport: recorder.Transport(nil)}, url)
+	if resp.StatusCode != http.StatusCreated || body != "<h1>/news</h1>" {
+		t.Fatalf("recorded response = %d %q", resp.StatusCode, body)
+	}
+	srv.Close()
+
+	// Сервер остановлен: ответ берется только из записи
+	player, err := Open(dir, Replay)
+	if err != nil {
+		t.Fatalf("Open(Replay): %v", err)
+	}
+	client := &http.Client{Transport: player.Transport(nil)}
+	resp, body = get(t, client, url)
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("replayed status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if body != "<h1>/news</h1>" {
+		t.Errorf("replayed body = %q, want <h1>/news</h1>", body)
+	}
+	if got := resp.Header.Get("X-Page"); got != "/news" {
+		t.Errorf("replayed X-Page = %q, want /news", got)
+	}
+	if got := resp.Header.Get("Content-Length"); got != "" {
+		t.Errorf("replayed Content-Length header = %q, want none", got)
+	}
+
+	if _, err := client.Get(srv.URL + "/other"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("GET of an unrecorded URL: err = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestLoadMethod(t *testing.T) {
+	store, err := Open(t.TempDir(), Record)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	err = store.Save(Response{Method: "get", URL: "https://example.com/", Status: http.StatusOK, Body: []byte("ok")})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Метод сравнивается без учета регистра, а разные методы хранятся отдельно
+	if resp, err := store.Load("GET", "https://example.com/"); err != nil || string(resp.Body) != "ok" {
+		t.Errorf("Load(GET) = %q, %v, want ok", resp.Body, err)
+	}
+	if _, err := store.Load("POST", "https://example.com/"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Load(POST) err = %v, want ErrNotRecorded", err)
+	}
+}
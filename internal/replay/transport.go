@@ -0,0 +1,80 @@
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Transport возвращает http.RoundTripper, который при записи сохраняет
+// ответы next, а при воспроизведении отвечает из записи, не обращаясь
+// к сети. Пустой next означает http.DefaultTransport.
+func (s *Store) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{store: s, next: next}
+}
+
+type transport struct {
+	store *Store
+	next  http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	if t.store.Replaying() {
+		resp, err := t.store.Load(req.Method, url)
+		if err != nil {
+			return nil, err
+		}
+		return httpResponse(req, resp), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		header[name] = strings.Join(values, ", ")
+	}
+	err = t.store.Save(Response{
+		Method: req.Method,
+		URL:    url,
+		Status: resp.StatusCode,
+		Header: header,
+		Body:   body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func httpResponse(req *http.Request, resp Response) *http.Response {
+	header := make(http.Header, len(resp.Header))
+	for name, value := range resp.Header {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
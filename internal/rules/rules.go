@@ -0,0 +1,279 @@
+// Package rules проверяет записи результатов простыми условиями вроде
+// Price < 5000 или InStock == "true".
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Операторы сравнения.
+const (
+	opEq       = "=="
+	opNe       = "!="
+	opLt       = "<"
+	opLe       = "<="
+	opGt       = ">"
+	opGe       = ">="
+	opContains = "contains"
+)
+
+// operators упорядочены так, чтобы двухсимвольные находились раньше
+// своих односимвольных префиксов.
+var operators = []string{opEq, opNe, opLe, opGe, opLt, opGt}
+
+// Rule - условие на поля записи: сравнения, соединенные && и ||.
+// && связывает сильнее ||, скобки не поддерживаются.
+type Rule struct {
+	expr string
+	// any - варианты ||, каждый из которых - сравнения через &&.
+	any [][]comparison
+}
+
+// comparison - сравнение поля со значением.
+type comparison struct {
+	field string
+	op    string
+	value string
+}
+
+// Parse разбирает условие. Поле - имя поля записи, значение - число,
+// строка в кавычках или слово без пробелов.
+func Parse(expr string) (*Rule, error) {
+	r := &Rule{expr: strings.TrimSpace(expr)}
+	if r.expr == "" {
+		return nil, errors.New("empty rule")
+	}
+	for _, alt := range splitOutside(r.expr, "||") {
+		var all []comparison
+		for _, part := range splitOutside(alt, "&&") {
+			c, err := parseComparison(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %q: %w", r.expr, err)
+			}
+			all = append(all, c)
+		}
+		r.any = append(r.any, all)
+	}
+	return r, nil
+}
+
+func (r *Rule) String() string {
+	return r.expr
+}
+
+// Fields возвращает поля, которые упоминает условие, без повторов.
+func (r *Rule) Fields() []string {
+	var fields []string
+	for _, all := range r.any {
+		for _, c := range all {
+			if !slices.Contains(fields, c.field) {
+				fields = append(fields, c.field)
+			}
+		}
+	}
+	return fields
+}
+
+// Match сообщает, что запись удовлетворяет условию. Сравнение с пустым
+// или отсутствующим полем ложно, кроме != с непустым значением.
+func (r *Rule) Match(record map[string]string) bool {
+	for _, all := range r.any {
+		if !slices.ContainsFunc(all, func(c comparison) bool { return !c.match(record) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// match сравнивает поле записи со значением: как числа, если значение
+// условия - число, иначе как строки. Числовое сравнение с полем, из которого
+// число не извлекается, ложно.
+func (c comparison) match(record map[string]string) bool {
+	actual := strings.TrimSpace(record[c.field])
+	if actual == "" {
+		return c.op == opNe && c.value != ""
+	}
+	if c.op == opContains {
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(c.value))
+	}
+
+	var cmp int
+	if b, err := strconv.ParseFloat(c.value, 64); err == nil {
+		a, ok := parseNumber(actual)
+		if !ok {
+			return false
+		}
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(actual, c.value)
+	}
+
+	switch c.op {
+	case opEq:
+		return cmp == 0
+	case opNe:
+		return cmp != 0
+	case opLt:
+		return cmp < 0
+	case opLe:
+		return cmp <= 0
+	case opGt:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// parseComparison разбирает "поле оператор значение".
+func parseComparison(s string) (comparison, error) {
+	field, rest, ok := cutField(s)
+	if !ok {
+		return comparison{}, fmt.Errorf("expected a field name at %q", s)
+	}
+	rest = strings.TrimSpace(rest)
+
+	var c comparison
+	c.field = field
+	if after, ok := strings.CutPrefix(rest, opContains); ok && (after == "" || unicode.IsSpace(rune(after[0]))) {
+		c.op, rest = opContains, after
+	} else {
+		for _, op := range operators {
+			if after, ok := strings.CutPrefix(rest, op); ok {
+				c.op, rest = op, after
+				break
+			}
+		}
+	}
+	if c.op == "" {
+		return comparison{}, fmt.Errorf("expected an operator (%s or %s) after %s", strings.Join(operators, ", "), opContains, field)
+	}
+
+	rest = strings.TrimSpace(rest)
+	switch {
+	case rest == "":
+		return comparison{}, fmt.Errorf("missing value after %s %s", field, c.op)
+	case rest[0] == '"' || rest[0] == '\'':
+		if len(rest) < 2 || rest[len(rest)-1] != rest[0] {
+			return comparison{}, fmt.Errorf("unterminated string %s", rest)
+		}
+		c.value = rest[1 : len(rest)-1]
+		if rest[0] == '"' {
+			value, err := strconv.Unquote(rest)
+			if err != nil {
+				return comparison{}, fmt.Errorf("invalid string %s: %w", rest, err)
+			}
+			c.value = value
+		}
+	case strings.ContainsFunc(rest, unicode.IsSpace):
+		return comparison{}, fmt.Errorf("unexpected %q, quote values with spaces", rest)
+	default:
+		c.value = rest
+	}
+	return c, nil
+}
+
+// cutField отделяет имя поля: буквы, цифры, "_", "." и "-", как в именах
+// полей результатов ("Param.city", "Header.Last-Modified").
+func cutField(s string) (field, rest string, ok bool) {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-'
+	})
+	if end == -1 {
+		end = len(s)
+	}
+	return s[:end], s[end:], end > 0
+}
+
+// splitOutside делит s по sep вне строк в кавычках.
+func splitOutside(s, sep string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseNumber разбирает первое число из текста страницы: "4 990 ₽",
+// "$1,299.00", "12,5 кг", "4.5 из 5". Пробел разделяет разряды, только если
+// за ним ровно три цифры. Из двух разделителей десятичный - последний,
+// одиночная запятая - десятичная, если за ней не ровно три цифры.
+func parseNumber(s string) (float64, bool) {
+	runes := []rune(s)
+	start := slices.IndexFunc(runes, isDigit)
+	if start == -1 {
+		return 0, false
+	}
+	var b strings.Builder
+	if start > 0 && runes[start-1] == '-' {
+		b.WriteRune('-')
+	}
+scan:
+	for i := start; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case isDigit(r):
+			b.WriteRune(r)
+		case (r == '.' || r == ',') && i+1 < len(runes) && isDigit(runes[i+1]):
+			b.WriteRune(r)
+		case groupSpace(r) && digitGroup(runes[i+1:]):
+		default:
+			break scan
+		}
+	}
+
+	num := b.String()
+	dot, comma := strings.LastIndex(num, "."), strings.LastIndex(num, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && comma > dot:
+		num = strings.ReplaceAll(num, ".", "")
+		num = strings.Replace(num, ",", ".", 1)
+	case dot >= 0 && comma >= 0:
+		num = strings.ReplaceAll(num, ",", "")
+	case comma >= 0 && strings.Count(num, ",") == 1 && len(num)-comma-1 != 3:
+		num = strings.Replace(num, ",", ".", 1)
+	default:
+		num = strings.ReplaceAll(num, ",", "")
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	return f, err == nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// groupSpace сообщает, что r - пробел, которым на страницах разделяют разряды.
+func groupSpace(r rune) bool {
+	return r == ' ' || r == '\u00a0' || r == '\u202f' || r == '\u2009'
+}
+
+// digitGroup сообщает, что rest начинается ровно с трех цифр.
+func digitGroup(rest []rune) bool {
+	if len(rest) < 3 || !isDigit(rest[0]) || !isDigit(rest[1]) || !isDigit(rest[2]) {
+		return false
+	}
+	return len(rest) == 3 || !isDigit(rest[3])
+}
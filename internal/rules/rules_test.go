@@ -0,0 +1,102 @@
+package rules
+
+import "testing"
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"Price",
+		"Price <",
+		"Price ~ 5",
+		`Title == "unterminated`,
+		"Title == two words",
+		"< 5",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		rule   string
+		record map[string]string
+		want   bool
+	}{
+		{"Price < 5000", map[string]string{"Price": "4 990 ₽"}, true},
+		{"Price < 5000", map[string]string{"Price": "5 990 ₽"}, false},
+		{"Price <= 1299", map[string]string{"Price": "$1,299.00"}, true},
+		{"Weight > 12", map[string]string{"Weight": "12,5 кг"}, true},
+		{"Rating >= 4.5", map[string]string{"Rating": "4.5 из 5"}, true},
+		{"Price < 1300", map[string]string{"Price": "1 200 ₽ (было 1 500 ₽)"}, true},
+		{"Price == 1500", map[string]string{"Price": "1 200 ₽ (было 1 500 ₽)"}, false},
+		// Числовое условие не выполняется для поля без числа
+		{"Price < 5000", map[string]string{"Price": "нет в наличии"}, false},
+		{"Price > 0", map[string]string{"Price": "N/A"}, false},
+		{"Price != 100", map[string]string{"Price": "N/A"}, false},
+		{"Price < 5000", map[string]string{}, false},
+		{"Price != 100", map[string]string{}, true},
+		{`InStock == "true"`, map[string]string{"InStock": "true"}, true},
+		{`InStock != true`, map[string]string{"InStock": "false"}, true},
+		{"Title contains sale", map[string]string{"Title": "Big SALE today"}, true},
+		{"Title contains sale", map[string]string{"Title": "Regular price"}, false},
+		{`Price < 100 && Title contains "sale"`, map[string]string{"Price": "50", "Title": "Sale"}, true},
+		{`Price < 100 && Title contains "sale"`, map[string]string{"Price": "150", "Title": "Sale"}, false},
+		{"Price < 100 || InStock == yes", map[string]string{"Price": "150", "InStock": "yes"}, true},
+		{`Title == "a || b"`, map[string]string{"Title": "a || b"}, true},
+		{"Header.Last-Modified == today", map[string]string{"Header.Last-Modified": "today"}, true},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.rule, err)
+		}
+		if got := rule.Match(tt.record); got != tt.want {
+			t.Errorf("%q.Match(%v) = %v, want %v", tt.rule, tt.record, got, tt.want)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+		ok   bool
+	}{
+		{"4990", 4990, true},
+		{"4 990 ₽", 4990, true},
+		{"4\u00a0990\u00a0₽", 4990, true},
+		{"4.5 5", 4.5, true},
+		{"1 200 300", 1200300, true},
+		{"$1,299.00", 1299, true},
+		{"1.299,50 €", 1299.5, true},
+		{"1,299", 1299, true},
+		{"12,5 кг", 12.5, true},
+		{"-15%", -15, true},
+		{"4.5 из 5", 4.5, true},
+		{"1 200 ₽ (было 1 500 ₽)", 1200, true},
+		{"Рейтинг: 4.8.", 4.8, true},
+		{"3 шт. по 20", 3, true},
+		{"нет в наличии", 0, false},
+		{"", 0, false},
+		{"-", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.text)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseNumber(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	rule, err := Parse("Price < 5 && InStock == yes || Price > 100")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := rule.Fields()
+	if len(got) != 2 || got[0] != "Price" || got[1] != "InStock" {
+		t.Errorf("Fields() = %v, want [Price InStock]", got)
+	}
+}
@@ -0,0 +1,268 @@
+// Package scheduler запускает задачи по их расписаниям из поля Schedule
+// в долгоживущем процессе вместо записей в crontab.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+const (
+	// DefaultMisfireThreshold - опоздание запуска, после которого он
+	// считается пропущенным: процесс спал, машина была выключена или
+	// часы переведены.
+	DefaultMisfireThreshold = time.Minute
+	// maxWait ограничивает сон между проверками, чтобы заметить переход
+	// системных часов.
+	maxWait = time.Minute
+	// maxMissed ограничивает подсчет пропущенных запусков для частых расписаний.
+	maxMissed = 1000
+)
+
+// Options - настройки планировщика.
+type Options struct {
+	// Run выполняет задачу и возвращает управление, когда она завершена
+	// или отменен ctx.
+	Run func(ctx context.Context, task taskconfig.Task)
+	// Overlap - политика для задач без своего Overlap, по умолчанию
+	// taskconfig.OverlapSkip.
+	Overlap string
+	// MisfireThreshold - см. DefaultMisfireThreshold.
+	MisfireThreshold time.Duration
+	Logger           *slog.Logger
+}
+
+// Scheduler запускает задачи, когда подходит время по их расписанию.
+// Пропущенные запуски не наверстываются: опоздавшая задача выполняется
+// один раз, а пропуск записывается в лог.
+type Scheduler struct {
+	opts Options
+
+	mu sync.Mutex
+	// entries - задачи с расписанием по имени.
+	entries map[string]*entry
+	changed chan struct{}
+	wg      sync.WaitGroup
+}
+
+// entry - задача с расписанием и состояние ее запуска.
+type entry struct {
+	task     taskconfig.Task
+	schedule cron.Schedule
+	next     time.Time
+	lastRun  time.Time
+	running  bool
+	// queued - следующий запуск ждет окончания текущего (OverlapQueue).
+	queued bool
+}
+
+// Job - задача с расписанием и состояние ее запусков.
+type Job struct {
+	Name     string
+	Schedule string
+	// Next - время следующего запуска, нулевое, если расписание не срабатывает.
+	Next    time.Time
+	LastRun *time.Time `json:",omitempty"`
+	Running bool
+	// Queued - следующий запуск ждет окончания текущего.
+	Queued  bool `json:",omitempty"`
+	Overlap string
+}
+
+// New создает планировщик без задач, см. Update.
+func New(opts Options) *Scheduler {
+	if opts.Overlap == "" {
+		opts.Overlap = taskconfig.OverlapSkip
+	}
+	if opts.MisfireThreshold <= 0 {
+		opts.MisfireThreshold = DefaultMisfireThreshold
+	}
+	return &Scheduler{
+		opts:    opts,
+		entries: make(map[string]*entry),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Update заменяет набор задач. Задачи без Schedule и выключенные
+// пропускаются. Если расписание задачи не изменилось, время ее следующего
+// запуска сохраняется, а выполняемый запуск доделывается в любом случае.
+// Возвращает число задач с расписанием.
+func (s *Scheduler) Update(tasks []taskconfig.Task) int {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make(map[string]*entry, len(tasks))
+	for _, task := range tasks {
+		if task.Schedule == "" || !task.IsEnabled() {
+			continue
+		}
+		e, ok := s.entries[task.Name]
+		if !ok || e.task.Schedule != task.Schedule {
+			schedule, err := taskconfig.ParseSchedule(task.Schedule)
+			if err != nil {
+				s.opts.Logger.Error("⭕ Invalid schedule", "task:", task.Name, "error:", err)
+				continue
+			}
+			if !ok {
+				e = &entry{}
+			}
+			e.schedule, e.next = schedule, schedule.Next(now)
+			if e.next.IsZero() {
+				s.opts.Logger.Warn("⭕ Schedule never fires", "task:", task.Name, "schedule:", task.Schedule)
+			} else {
+				s.opts.Logger.Info("📅 Task scheduled", "task:", task.Name, "schedule:", task.Schedule, "next run:", e.next.Format(time.DateTime))
+			}
+		}
+		e.task = task
+		entries[task.Name] = e
+	}
+	for name := range s.entries {
+		if _, ok := entries[name]; !ok {
+			s.opts.Logger.Info("🗑️ Task unscheduled", "task:", name)
+		}
+	}
+	s.entries = entries
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+	return len(entries)
+}
+
+// Run запускает задачи по расписанию, пока не отменен ctx. Отмена ctx
+// передается выполняемым запускам; Run ждет, пока они вернут управление.
+func (s *Scheduler) Run(ctx context.Context) {
+	defer s.wg.Wait()
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		wait := maxWait
+		for _, e := range s.entries {
+			if e.next.IsZero() {
+				continue
+			}
+			if !e.next.After(now) {
+				s.fire(ctx, e, now)
+			}
+			if d := e.next.Sub(now); d < wait {
+				wait = d
+			}
+		}
+		s.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// fire запускает задачу, время которой подошло, с учетом политики
+// Overlap, и назначает следующий запуск. Вызывается под mu.
+func (s *Scheduler) fire(ctx context.Context, e *entry, now time.Time) {
+	if late := now.Sub(e.next); late > s.opts.MisfireThreshold {
+		missed := 0
+		for t := e.schedule.Next(e.next); !t.IsZero() && !t.After(now) && missed < maxMissed; t = e.schedule.Next(t) {
+			missed++
+		}
+		s.opts.Logger.Warn("⏰ Scheduled run misfired, running it once now", "task:", e.task.Name,
+			"scheduled at:", e.next.Format(time.DateTime), "late by:", late.Round(time.Second), "missed runs:", missed)
+	}
+	e.next = e.schedule.Next(now)
+
+	if !e.running {
+		e.running = true
+		s.wg.Add(1)
+		go s.run(ctx, e)
+		return
+	}
+	switch s.overlap(e.task) {
+	case taskconfig.OverlapQueue:
+		if e.queued {
+			s.opts.Logger.Warn("⏭️ Skipping scheduled run, the next one is already queued", "task:", e.task.Name)
+			return
+		}
+		e.queued = true
+		s.opts.Logger.Info("⏳ Previous run is still going, queued the next one", "task:", e.task.Name)
+	default:
+		s.opts.Logger.Warn("⏭️ Skipping scheduled run, previous one is still going", "task:", e.task.Name, "next run:", e.next.Format(time.DateTime))
+	}
+}
+
+// run выполняет задачу, а затем запуски, поставленные в очередь за ней.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	defer s.wg.Done()
+	s.mu.Lock()
+	for {
+		task := e.task
+		e.lastRun = time.Now()
+		s.mu.Unlock()
+
+		s.opts.Logger.Info("⏰ Running scheduled task", "task id:", task.ID, "task:", task.Name)
+		s.opts.Run(ctx, task)
+
+		s.mu.Lock()
+		// Задачу могли убрать из конфига, пока она выполнялась.
+		if !e.queued || ctx.Err() != nil || s.entries[task.Name] != e {
+			e.running, e.queued = false, false
+			s.mu.Unlock()
+			return
+		}
+		e.queued = false
+	}
+}
+
+// Jobs возвращает задачи с расписанием по времени следующего запуска.
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	jobs := make([]Job, 0, len(s.entries))
+	for _, e := range s.entries {
+		job := Job{
+			Name:     e.task.Name,
+			Schedule: e.task.Schedule,
+			Next:     e.next,
+			Running:  e.running,
+			Queued:   e.queued,
+			Overlap:  s.overlap(e.task),
+		}
+		if !e.lastRun.IsZero() {
+			lastRun := e.lastRun
+			job.LastRun = &lastRun
+		}
+		jobs = append(jobs, job)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Next.IsZero() != jobs[j].Next.IsZero() {
+			return jobs[j].Next.IsZero()
+		}
+		if !jobs[i].Next.Equal(jobs[j].Next) {
+			return jobs[i].Next.Before(jobs[j].Next)
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs
+}
+
+// overlap возвращает политику задачи с учетом значения по умолчанию.
+func (s *Scheduler) overlap(task taskconfig.Task) string {
+	if task.Overlap == "" {
+		return s.opts.Overlap
+	}
+	return task.Overlap
+}
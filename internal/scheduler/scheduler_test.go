@@ -0,0 +1,181 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func newTestScheduler(run func(ctx context.Context, task taskconfig.Task)) *Scheduler {
+	return New(Options{Run: run, Logger: discard})
+}
+
+func TestUpdate(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name  string
+		tasks []taskconfig.Task
+		want  []string
+	}{
+		{
+			name:  "scheduled tasks",
+			tasks: []taskconfig.Task{{Name: "a", Schedule: "@every 1h"}, {Name: "b", Schedule: "0 9 * * 1"}},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "no schedule",
+			tasks: []taskconfig.Task{{Name: "a", Schedule: "@every 1h"}, {Name: "b"}},
+			want:  []string{"a"},
+		},
+		{
+			name:  "disabled",
+			tasks: []taskconfig.Task{{Name: "a", Schedule: "@every 1h", Enabled: &disabled}},
+		},
+		{
+			name:  "invalid schedule",
+			tasks: []taskconfig.Task{{Name: "a", Schedule: "every hour"}, {Name: "b", Schedule: "@daily"}},
+			want:  []string{"b"},
+		},
+	}
+	for _, tt := range tests {
+		s := newTestScheduler(nil)
+		if n := s.Update(tt.tasks); n != len(tt.want) {
+			t.Errorf("%s: Update = %d, want %d", tt.name, n, len(tt.want))
+		}
+		for _, name := range tt.want {
+			if _, ok := s.entries[name]; !ok {
+				t.Errorf("%s: task %s is not scheduled", tt.name, name)
+			}
+		}
+	}
+}
+
+func TestUpdateKeepsNextRun(t *testing.T) {
+	s := newTestScheduler(nil)
+	s.Update([]taskconfig.Task{{Name: "a", Schedule: "@every 1h"}, {Name: "b", Schedule: "@every 1h"}})
+	next := time.Now().Add(time.Minute)
+	s.entries["a"].next = next
+	s.entries["b"].next = next
+
+	// Расписание b изменилось, поэтому его следующий запуск считается заново
+	s.Update([]taskconfig.Task{{Name: "a", Schedule: "@every 1h", URL: "new"}, {Name: "b", Schedule: "@every 2h"}})
+	if got := s.entries["a"].next; !got.Equal(next) {
+		t.Errorf("next run of an unchanged schedule = %v, want %v", got, next)
+	}
+	if got := s.entries["a"].task.URL; got != "new" {
+		t.Errorf("task URL = %q, want the updated task", got)
+	}
+	if got := s.entries["b"].next; got.Equal(next) {
+		t.Error("next run of a changed schedule was kept")
+	}
+}
+
+func TestFireOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		overlap     string
+		running     bool
+		queued      bool
+		wantStarted bool
+		wantQueued  bool
+	}{
+		{name: "idle", overlap: taskconfig.OverlapSkip, wantStarted: true},
+		{name: "skip while running", overlap: taskconfig.OverlapSkip, running: true},
+		{name: "queue while running", overlap: taskconfig.OverlapQueue, running: true, wantQueued: true},
+		{name: "queue already queued", overlap: taskconfig.OverlapQueue, running: true, queued: true, wantQueued: true},
+		{name: "default policy", running: true},
+	}
+	for _, tt := range tests {
+		started := make(chan string, 1)
+		s := newTestScheduler(func(ctx context.Context, task taskconfig.Task) {
+			started <- task.Name
+		})
+		s.Update([]taskconfig.Task{{Name: "a", Schedule: "@every 1h", Overlap: tt.overlap}})
+
+		s.mu.Lock()
+		e := s.entries["a"]
+		e.running, e.queued = tt.running, tt.queued
+		now := time.Now()
+		e.next = now
+		s.fire(context.Background(), e, now)
+		queued, next := e.queued, e.next
+		s.mu.Unlock()
+		s.wg.Wait()
+
+		select {
+		case <-started:
+			if !tt.wantStarted {
+				t.Errorf("%s: task started", tt.name)
+			}
+		default:
+			if tt.wantStarted {
+				t.Errorf("%s: task did not start", tt.name)
+			}
+		}
+		if queued != tt.wantQueued {
+			t.Errorf("%s: queued = %v, want %v", tt.name, queued, tt.wantQueued)
+		}
+		if !next.After(now) {
+			t.Errorf("%s: next run %v is not after %v", tt.name, next, now)
+		}
+	}
+}
+
+func TestRunQueued(t *testing.T) {
+	runs := 0
+	s := newTestScheduler(nil)
+	s.opts.Run = func(ctx context.Context, task taskconfig.Task) {
+		runs++
+		// Следующий запуск подошел, пока выполнялся первый
+		if runs == 1 {
+			s.mu.Lock()
+			s.entries["a"].queued = true
+			s.mu.Unlock()
+		}
+	}
+	s.Update([]taskconfig.Task{{Name: "a", Schedule: "@every 1h", Overlap: taskconfig.OverlapQueue}})
+
+	e := s.entries["a"]
+	e.running = true
+	s.wg.Add(1)
+	s.run(context.Background(), e)
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2", runs)
+	}
+	if e.running || e.queued {
+		t.Errorf("after the queued run running = %v, queued = %v, want both false", e.running, e.queued)
+	}
+}
+
+func TestJobsOrder(t *testing.T) {
+	s := newTestScheduler(nil)
+	s.Update([]taskconfig.Task{
+		{Name: "never", Schedule: "0 0 30 2 *"},
+		{Name: "later", Schedule: "@every 2h"},
+		{Name: "b", Schedule: "@every 1h"},
+		{Name: "a", Schedule: "@every 1h"},
+	})
+	next := time.Now().Add(time.Hour)
+	s.entries["a"].next = next
+	s.entries["b"].next = next
+
+	var names []string
+	for _, job := range s.Jobs() {
+		names = append(names, job.Name)
+	}
+	want := []string{"a", "b", "later", "never"}
+	if len(names) != len(want) {
+		t.Fatalf("Jobs = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Jobs = %v, want %v", names, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// actionTimeout ограничивает ожидание элемента и само действие.
+const actionTimeout = 10 * time.Second
+
+// runActions выполняет шаги взаимодействия по порядку. Неудачный шаг
+// логируется и пропускается, прерывает выполнение только отмена контекста.
+func (r *RodScraper) runActions(ctx context.Context, page *rod.Page, actions []taskconfig.Action) error {
+	for i, action := range actions {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("actions canceled at step %d: %w", i, ctx.Err())
+		default:
+		}
+
+		if err := runAction(page, action); err != nil {
+			r.Logger.Warn("⭕ Action failed", "step:", i, "type:", action.Type, "selector:", action.Selector, "error:", err)
+			continue
+		}
+		r.Logger.Debug("🖱️ Action performed", "step:", i, "type:", action.Type, "selector:", action.Selector)
+
+		if err := sleep(ctx, action.WaitMs); err != nil {
+			return fmt.Errorf("actions canceled at step %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func runAction(page *rod.Page, action taskconfig.Action) error {
+	page = page.Timeout(actionTimeout)
+	defer page.CancelTimeout()
+
+	switch action.Type {
+	case taskconfig.ActionHover:
+		el, err := page.Element(action.Selector)
+		if err != nil {
+			return err
+		}
+		return el.Hover()
+
+	case taskconfig.ActionMove:
+		target := proto.Point{X: action.X, Y: action.Y}
+		if action.Selector != "" {
+			el, err := page.Element(action.Selector)
+			if err != nil {
+				return err
+			}
+			if err := el.ScrollIntoView(); err != nil {
+				return err
+			}
+			shape, err := el.Shape()
+			if err != nil {
+				return err
+			}
+			box := shape.Box()
+			target = proto.Point{X: box.X + box.Width/2, Y: box.Y + box.Height/2}
+		}
+
+		steps := action.Steps
+		if steps <= 0 {
+			steps = 1
+		}
+		return page.Mouse.MoveLinear(target, steps)
+	}
+
+	return fmt.Errorf("unknown action type %q", action.Type)
+}
@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+const artifactsTimeout = 15 * time.Second
+
+var unsafeFileChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// saveArtifacts сохраняет скриншот и HTML страницы в каталог отладки.
+// Файлы называются по имени задачи и времени: <имя>_<время>.png/.html.
+func (r *RodScraper) saveArtifacts(page *rod.Page, task taskconfig.Task, reason string) {
+	if err := os.MkdirAll(r.DebugDir, 0o755); err != nil {
+		r.Logger.Warn("⭕ Failed to create debug directory", "dir:", r.DebugDir, "error:", err)
+		return
+	}
+
+	name := task.Name
+	if name == "" {
+		name = task.URL
+	}
+	base := filepath.Join(r.DebugDir, fmt.Sprintf("%s_%s",
+		unsafeFileChars.ReplaceAllString(name, "_"), time.Now().Format("20060102-150405")))
+
+	page = page.Timeout(artifactsTimeout)
+	defer page.CancelTimeout()
+
+	if shot, err := page.Screenshot(true, nil); err != nil {
+		r.Logger.Warn("⭕ Failed to take screenshot", "url:", task.URL, "error:", err)
+	} else if err := os.WriteFile(base+".png", shot, 0o644); err != nil {
+		r.Logger.Warn("⭕ Failed to save screenshot", "path:", base+".png", "error:", err)
+	}
+
+	if html, err := page.HTML(); err != nil {
+		r.Logger.Warn("⭕ Failed to get page HTML", "url:", task.URL, "error:", err)
+	} else if err := os.WriteFile(base+".html", []byte(html), 0o644); err != nil {
+		r.Logger.Warn("⭕ Failed to save HTML", "path:", base+".html", "error:", err)
+	}
+
+	r.Logger.Info("🐞 Debug artifacts saved", "path:", base, "reason:", reason)
+}
+
+// allFieldsEmpty сообщает, что ни одно из полей задачи ничего не извлекло.
+func allFieldsEmpty(records []map[string]string, task taskconfig.Task) bool {
+	if len(task.Selectors) == 0 && len(task.Structured) == 0 {
+		return false
+	}
+	for _, record := range records {
+		for key := range task.Selectors {
+			if record[key] != "" {
+				return false
+			}
+		}
+		for key := range task.Structured {
+			if record[key] != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/captcha"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// ErrCaptchaUnsolved возвращается, если капча обнаружена, но решатель не настроен.
+var ErrCaptchaUnsolved = errors.New("captcha detected but no solver configured")
+
+// captchaInfoJS определяет тип капчи и ее sitekey по разметке страницы.
+const captchaInfoJS = `() => {
+	const el = document.querySelector(".h-captcha[data-sitekey], .cf-turnstile[data-sitekey], .g-recaptcha[data-sitekey], [data-sitekey]");
+	if (!el) return JSON.stringify({});
+	let kind = "recaptcha";
+	if (el.classList.contains("h-captcha")) kind = "hcaptcha";
+	if (el.classList.contains("cf-turnstile")) kind = "turnstile";
+	return JSON.stringify({kind: kind, siteKey: el.getAttribute("data-sitekey"), callback: el.getAttribute("data-callback") || ""});
+}`
+
+// captchaInjectJS подставляет токен в скрытые поля формы и вызывает data-callback, если он задан.
+const captchaInjectJS = `(token, callback) => {
+	const names = ["g-recaptcha-response", "h-captcha-response", "cf-turnstile-response"];
+	for (const name of names) {
+		document.querySelectorAll("[name='" + name + "'], #" + name).forEach((el) => { el.value = token; el.innerHTML = token; });
+	}
+	if (callback && typeof window[callback] === "function") window[callback](token);
+}`
+
+type captchaInfo struct {
+	Kind     string `json:"kind"`
+	SiteKey  string `json:"siteKey"`
+	Callback string `json:"callback"`
+}
+
+// handleCaptcha проверяет страницу на капчу и, если она есть, решает ее через CaptchaSolver.
+func (r *RodScraper) handleCaptcha(ctx context.Context, page *rod.Page, task taskconfig.Task) error {
+	if task.Captcha == nil || !detectCaptcha(page, task.Captcha.Detect) {
+		return nil
+	}
+
+	r.Logger.Warn("🧩 Captcha detected", "url:", task.URL)
+	if r.CaptchaSolver == nil {
+		return ErrCaptchaUnsolved
+	}
+
+	res, err := page.Eval(captchaInfoJS)
+	if err != nil {
+		return fmt.Errorf("failed to inspect captcha: %w", err)
+	}
+	var info captchaInfo
+	if err := json.Unmarshal([]byte(res.Value.Str()), &info); err != nil {
+		return fmt.Errorf("failed to decode captcha info: %w", err)
+	}
+	if info.SiteKey == "" {
+		return errors.New("captcha sitekey not found")
+	}
+	if task.Captcha.Kind != "" {
+		info.Kind = task.Captcha.Kind
+	}
+
+	token, err := r.CaptchaSolver.Solve(ctx, captcha.Challenge{
+		Kind:    info.Kind,
+		SiteKey: info.SiteKey,
+		PageURL: pageURL(page, task.URL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to solve captcha: %w", err)
+	}
+
+	if _, err := page.Eval(captchaInjectJS, token, info.Callback); err != nil {
+		return fmt.Errorf("failed to inject captcha token: %w", err)
+	}
+
+	if task.Captcha.Submit != "" {
+		button, err := page.Element(task.Captcha.Submit)
+		if err != nil {
+			return fmt.Errorf("failed to find captcha submit button: %w", err)
+		}
+		wait := page.WaitNavigation(proto.PageLifecycleEventNameLoad)
+		if err := button.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return fmt.Errorf("failed to submit captcha: %w", err)
+		}
+		wait()
+	}
+
+	r.Logger.Info("✅ Captcha solved", "url:", task.URL)
+	return nil
+}
+
+func detectCaptcha(page *rod.Page, selectors []string) bool {
+	for _, selector := range selectors {
+		if has, _, err := page.Has(selector); err == nil && has {
+			return true
+		}
+	}
+	return false
+}
+
+// pageURL возвращает текущий адрес страницы с учетом редиректов.
+func pageURL(page *rod.Page, fallback string) string {
+	info, err := page.Info()
+	if err != nil {
+		return fallback
+	}
+	return info.URL
+}
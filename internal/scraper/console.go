@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// consoleRecorder накапливает сообщения консоли и JS-исключения страницы.
+type consoleRecorder struct {
+	mu    sync.Mutex
+	lines []string
+	stop  func()
+}
+
+// recordConsole начинает запись консоли страницы. Каждое сообщение сразу
+// пишется в лог на уровне Debug и сохраняется для результата.
+func recordConsole(page *rod.Page, logger *slog.Logger, url string) *consoleRecorder {
+	rec := &consoleRecorder{}
+
+	listener, cancel := page.WithCancel()
+	rec.stop = cancel
+
+	add := func(line string) {
+		logger.Debug("📜 Page console", "url:", url, "message:", line)
+		rec.mu.Lock()
+		rec.lines = append(rec.lines, line)
+		rec.mu.Unlock()
+	}
+
+	go listener.EachEvent(
+		func(e *proto.RuntimeConsoleAPICalled) {
+			args := make([]string, 0, len(e.Args))
+			for _, arg := range e.Args {
+				args = append(args, remoteObjectString(arg))
+			}
+			add(fmt.Sprintf("[%s] %s", e.Type, strings.Join(args, " ")))
+		},
+		func(e *proto.RuntimeExceptionThrown) {
+			d := e.ExceptionDetails
+			text := d.Text
+			if d.Exception != nil && d.Exception.Description != "" {
+				text = d.Exception.Description
+			}
+			add(fmt.Sprintf("[exception] %s (%s:%d)", text, d.URL, d.LineNumber))
+		},
+		func(e *proto.LogEntryAdded) {
+			add(fmt.Sprintf("[%s] %s %s", e.Entry.Level, e.Entry.Text, e.Entry.URL))
+		},
+	)()
+
+	return rec
+}
+
+// Stop прекращает запись и возвращает накопленные сообщения.
+func (c *consoleRecorder) Stop() []string {
+	c.stop()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lines
+}
+
+func remoteObjectString(obj *proto.RuntimeRemoteObject) string {
+	if obj.Type == proto.RuntimeRemoteObjectTypeString {
+		return obj.Value.Str()
+	}
+	if obj.Description != "" {
+		return obj.Description
+	}
+	return obj.Value.JSON("", "")
+}
@@ -0,0 +1,51 @@
+package scraper
+
+import (
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+const consentClickTimeout = 3 * time.Second
+
+// handleDialogs закрывает или принимает JS-диалоги, чтобы они не блокировали страницу.
+// Возвращает функцию, которая снимает обработчик.
+func (r *RodScraper) handleDialogs(page *rod.Page, dialogs *taskconfig.Dialogs) func() {
+	listener, cancel := page.WithCancel()
+
+	go listener.EachEvent(func(e *proto.PageJavascriptDialogOpening) {
+		accept := dialogs.Accept()
+		r.Logger.Info("💬 Handling dialog", "type:", e.Type, "message:", e.Message, "accept:", accept)
+
+		handle := proto.PageHandleJavaScriptDialog{Accept: accept}
+		if accept && dialogs != nil {
+			handle.PromptText = dialogs.PromptText
+		}
+		if err := handle.Call(page); err != nil {
+			r.Logger.Warn("⭕ Failed to handle dialog", "error:", err)
+		}
+	})()
+
+	return cancel
+}
+
+// dismissConsent нажимает кнопки cookie-баннеров, если они есть на странице.
+func (r *RodScraper) dismissConsent(page *rod.Page, dialogs *taskconfig.Dialogs) {
+	if dialogs == nil {
+		return
+	}
+
+	for _, selector := range dialogs.Consent {
+		has, button, err := page.Has(selector)
+		if err != nil || !has {
+			continue
+		}
+		if err := button.Timeout(consentClickTimeout).Click(proto.InputMouseButtonLeft, 1); err != nil {
+			r.Logger.Warn("⭕ Failed to click consent button", "selector:", selector, "error:", err)
+			continue
+		}
+		r.Logger.Info("🍪 Consent banner dismissed", "selector:", selector)
+	}
+}
@@ -0,0 +1,102 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+const defaultGeoAccuracy = 100
+
+// devicePresets сопоставляет профили устройств из конфига с эмуляциями rod.
+var devicePresets = map[string]devices.Device{
+	"mobile":  devices.IPhoneX,
+	"tablet":  devices.IPad,
+	"desktop": devices.LaptopWithMDPIScreen,
+}
+
+// emulate применяет к странице настройки эмуляции задачи. Вызывается до навигации.
+func emulate(browser *rod.Browser, page *rod.Page, task taskconfig.Task) error {
+	e := task.Emulation
+	if e == nil {
+		return nil
+	}
+
+	if e.Device != "" {
+		device, ok := devicePresets[e.Device]
+		if !ok {
+			return fmt.Errorf("unknown device profile %q", e.Device)
+		}
+		if e.Landscape {
+			device = device.Landscape()
+		}
+		if err := page.Emulate(device); err != nil {
+			return fmt.Errorf("failed to emulate device: %w", err)
+		}
+	}
+
+	if vp := e.Viewport; vp != nil {
+		scale := vp.DeviceScaleFactor
+		if scale <= 0 {
+			scale = 1
+		}
+		err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:             vp.Width,
+			Height:            vp.Height,
+			DeviceScaleFactor: scale,
+			Mobile:            vp.Mobile,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set viewport: %w", err)
+		}
+		if err := (proto.EmulationSetTouchEmulationEnabled{Enabled: vp.Touch}).Call(page); err != nil {
+			return fmt.Errorf("failed to set touch emulation: %w", err)
+		}
+	}
+
+	if e.Locale != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: e.Locale}).Call(page); err != nil {
+			return fmt.Errorf("failed to override locale: %w", err)
+		}
+	}
+
+	if e.Timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: e.Timezone}).Call(page); err != nil {
+			return fmt.Errorf("failed to override timezone: %w", err)
+		}
+	}
+
+	if geo := e.Geolocation; geo != nil {
+		target, err := url.Parse(task.URL)
+		if err != nil {
+			return fmt.Errorf("failed to parse task url: %w", err)
+		}
+
+		err = proto.BrowserGrantPermissions{
+			Permissions: []proto.BrowserPermissionType{proto.BrowserPermissionTypeGeolocation},
+			Origin:      target.Scheme + "://" + target.Host,
+		}.Call(browser)
+		if err != nil {
+			return fmt.Errorf("failed to grant geolocation permission: %w", err)
+		}
+
+		accuracy := geo.Accuracy
+		if accuracy <= 0 {
+			accuracy = defaultGeoAccuracy
+		}
+		err = proto.EmulationSetGeolocationOverride{
+			Latitude:  &geo.Latitude,
+			Longitude: &geo.Longitude,
+			Accuracy:  &accuracy,
+		}.Call(page)
+		if err != nil {
+			return fmt.Errorf("failed to override geolocation: %w", err)
+		}
+	}
+
+	return nil
+}
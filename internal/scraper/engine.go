@@ -0,0 +1,22 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// Engines направляет задачу в скрапер, соответствующий ее полю Engine.
+// Задачи без Engine обрабатываются браузерным скрапером.
+type Engines map[string]Scraper
+
+// Scrape выбирает скрапер по движку задачи и делегирует ему работу.
+func (e Engines) Scrape(ctx context.Context, task taskconfig.Task) ([]map[string]string, error) {
+	engine := task.EngineName()
+	scraper, ok := e[engine]
+	if !ok {
+		return nil, fmt.Errorf("unknown engine %q", engine)
+	}
+	return scraper.Scrape(ctx, task)
+}
@@ -0,0 +1,123 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// extractFields извлекает поля по селекторам и записывает их в results.
+func (r *RodScraper) extractFields(ctx context.Context, page *rod.Page, selectors map[string]taskconfig.Selector, results map[string]string) error {
+	for key, selector := range selectors {
+		select {
+		case <-ctx.Done():
+			r.Logger.Warn("⭕ Scraping canceled during selector processing", "key:", key)
+			return fmt.Errorf("scraping canceled: %w", ctx.Err())
+		default:
+		}
+
+		if len(selector.Candidates()) == 0 {
+			results[key] = ""
+			continue
+		}
+
+		switch selector.Kind() {
+		case taskconfig.FieldCount, taskconfig.FieldExists:
+			elements, _ := r.findElements(page, key, selector)
+			if selector.Kind() == taskconfig.FieldCount {
+				results[key] = strconv.Itoa(len(elements))
+			} else {
+				results[key] = strconv.FormatBool(len(elements) > 0)
+			}
+			r.Logger.Debug("✅ Successfully scraped", "key:", key, "count:", len(elements))
+			continue
+		case taskconfig.FieldText:
+		default:
+			r.Logger.Warn("⭕ Unknown field type", "key:", key, "type:", selector.Type)
+			results[key] = ""
+			continue
+		}
+
+		var pattern *regexp.Regexp
+		if selector.Regex != "" {
+			var err error
+			pattern, err = regexp.Compile(selector.Regex)
+			if err != nil {
+				r.Logger.Warn("⭕ Invalid regex", "key:", key, "regex:", selector.Regex, "error:", err)
+				results[key] = ""
+				continue
+			}
+		}
+
+		elements, css := r.findElements(page, key, selector)
+		if len(elements) == 0 {
+			r.Logger.Warn("⭕ No elements found", "key:", key, "selectors:", selector.Candidates())
+			results[key] = ""
+			continue
+		}
+
+		var texts []string
+		for _, element := range elements {
+			select {
+			case <-ctx.Done():
+				r.Logger.Warn("⭕ Scraping canceled during element processing", "key:", key)
+				return fmt.Errorf("scraping canceled: %w", ctx.Err())
+			default:
+			}
+			text, err := element.Text()
+			if err != nil {
+				r.Logger.Warn("⭕ Failed to get text for element", "selector:", css, "error:", err)
+				continue
+			}
+			if pattern != nil {
+				var ok bool
+				if text, ok = applyRegex(pattern, text); !ok {
+					r.Logger.Warn("⭕ Regex did not match", "key:", key, "regex:", selector.Regex)
+					continue
+				}
+			}
+			texts = append(texts, text)
+		}
+
+		results[key] = strings.Join(texts, "\n")
+		r.Logger.Debug("✅ Successfully scraped", "key:", key, "count:", len(texts))
+	}
+
+	return nil
+}
+
+// findElements перебирает цепочку селекторов поля и возвращает элементы первого
+// сработавшего селектора вместе с самим селектором. Промахи отдельных селекторов
+// пишутся в Debug: отсутствие элементов для count и exists - обычный результат.
+func (r *RodScraper) findElements(page *rod.Page, key string, selector taskconfig.Selector) (rod.Elements, string) {
+	candidates := selector.Candidates()
+	for i, css := range candidates {
+		elements, err := page.Elements(css)
+		if err != nil || len(elements) == 0 {
+			r.Logger.Debug("⭕ No elements for selector", "key:", key, "selector:", css, "error:", err)
+			continue
+		}
+		if i > 0 {
+			r.Logger.Debug("↪️ Using fallback selector", "key:", key, "selector:", css, "fallback:", i)
+		}
+		return elements, css
+	}
+	return nil, ""
+}
+
+// applyRegex возвращает первую группу захвата, а если групп нет - все совпадение.
+func applyRegex(pattern *regexp.Regexp, text string) (string, bool) {
+	match := pattern.FindStringSubmatch(text)
+	if match == nil {
+		return "", false
+	}
+	if len(match) > 1 {
+		return strings.TrimSpace(match[1]), true
+	}
+	return strings.TrimSpace(match[0]), true
+}
@@ -0,0 +1,226 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"golang.org/x/net/html/charset"
+)
+
+const defaultFeedTimeout = 30 * time.Second
+
+// maxFeedSize ограничивает размер ленты, чтобы огромный ответ не занял всю память.
+const maxFeedSize = 20 << 20
+
+// FeedScraper загружает RSS/Atom ленты без браузера. Каждая запись ленты
+// становится отдельной записью результата с полями Title, Link, Published,
+// Updated, Author, ID и Content.
+type FeedScraper struct {
+	Client *http.Client
+	Logger *slog.Logger
+	// Timeout - лимит на загрузку ленты для задач без NavigationTimeout.
+	Timeout time.Duration
+}
+
+func NewFeedScraper(logger *slog.Logger) *FeedScraper {
+	return &FeedScraper{
+		Client:  &http.Client{Transport: ProxyTransport(nil, nil)},
+		Logger:  logger,
+		Timeout: defaultFeedTimeout,
+	}
+}
+
+// feedDocument покрывает RSS 2.0, RSS 1.0 (RDF) и Atom: у всех трех
+// форматов записи лежат либо в channel/item, либо в item, либо в entry.
+type feedDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []feedItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []feedItem `xml:"item"`
+	Entries []feedItem `xml:"entry"`
+}
+
+type feedItem struct {
+	Title       string     `xml:"title"`
+	Links       []feedLink `xml:"link"`
+	GUID        string     `xml:"guid"`
+	ID          string     `xml:"id"`
+	PubDate     string     `xml:"pubDate"`
+	DCDate      string     `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Published   string     `xml:"published"`
+	Updated     string     `xml:"updated"`
+	Author      feedAuthor `xml:"author"`
+	Creator     string     `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Description string     `xml:"description"`
+	Encoded     string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Summary     string     `xml:"summary"`
+	Content     string     `xml:"content"`
+}
+
+// feedLink - в RSS ссылка задается текстом элемента, в Atom - атрибутом href.
+type feedLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+// feedAuthor - в RSS автор задается строкой, в Atom - элементом name.
+type feedAuthor struct {
+	Name string `xml:"name"`
+	Text string `xml:",chardata"`
+}
+
+// Scrape загружает ленту и возвращает по записи на каждый ее элемент.
+func (f *FeedScraper) Scrape(ctx context.Context, task taskconfig.Task) ([]map[string]string, error) {
+	f.Logger.Info("📰 Fetching feed", "url:", task.URL)
+
+	if timeout := timeoutFor(task.NavigationTimeout, f.Timeout); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if task.Proxy != "" {
+		proxy, err := url.Parse(task.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		ctx = withProxy(ctx, proxy)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, task.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build feed request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	for name, value := range task.Headers {
+		req.Header.Set(name, value)
+	}
+	if header := authorizationHeader(task.Auth); header != "" {
+		req.Header.Set("Authorization", header)
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, URL: task.URL}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	if len(data) > maxFeedSize {
+		return nil, fmt.Errorf("feed exceeds %d bytes", maxFeedSize)
+	}
+
+	items, err := parseFeed(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		record := item.record()
+		record["URL"] = task.URL
+		record["Type"] = task.Type
+		records = append(records, record)
+	}
+
+	f.Logger.Info("✅ Successfully fetched feed", "url:", task.URL, "count:", len(records))
+	return records, nil
+}
+
+func parseFeed(r io.Reader) ([]feedItem, error) {
+	decoder := xml.NewDecoder(r)
+	// Ленты русских сайтов нередко объявляют windows-1251 или koi8-r,
+	// их текст перекодируется в UTF-8.
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var doc feedDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		return doc.Channel.Items, nil
+	case "rdf":
+		return doc.Items, nil
+	case "feed":
+		return doc.Entries, nil
+	}
+	return nil, fmt.Errorf("unsupported feed format: <%s>", doc.XMLName.Local)
+}
+
+func (i feedItem) record() map[string]string {
+	return map[string]string{
+		"Title":     strings.TrimSpace(i.Title),
+		"Link":      i.link(),
+		"ID":        strings.TrimSpace(firstNonEmpty(i.GUID, i.ID)),
+		"Published": normalizeFeedDate(firstNonEmpty(i.PubDate, i.Published, i.DCDate, i.Updated)),
+		"Updated":   normalizeFeedDate(i.Updated),
+		"Author":    strings.TrimSpace(firstNonEmpty(i.Author.Name, i.Author.Text, i.Creator)),
+		"Content":   strings.TrimSpace(firstNonEmpty(i.Encoded, i.Content, i.Description, i.Summary)),
+	}
+}
+
+// link выбирает основную ссылку записи: rel="alternate" или без rel в Atom, текст в RSS.
+func (i feedItem) link() string {
+	for _, l := range i.Links {
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return l.Href
+		}
+	}
+	for _, l := range i.Links {
+		if text := strings.TrimSpace(l.Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// normalizeFeedDate приводит дату к RFC 3339; нераспознанные даты возвращаются как есть.
+func normalizeFeedDate(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// runSteps выполняет сценарий и возвращает записи результата. Каждая итерация
+// foreach порождает отдельную запись; если foreach в сценарии нет, результатом
+// будет одна запись, накопленная шагами extract.
+func (r *RodScraper) runSteps(ctx context.Context, page *rod.Page, steps []taskconfig.Step, vars, record map[string]string) ([]map[string]string, error) {
+	var emitted []map[string]string
+
+	for i, step := range steps {
+		select {
+		case <-ctx.Done():
+			return emitted, fmt.Errorf("flow canceled at step %d: %w", i, ctx.Err())
+		default:
+		}
+
+		switch step.Type {
+		case taskconfig.StepNavigate:
+			target := expandVars(step.URL, vars)
+			if err := page.Navigate(target); err != nil {
+				return emitted, fmt.Errorf("step %d: failed to navigate to %s: %w", i, target, err)
+			}
+			if err := page.WaitLoad(); err != nil {
+				r.Logger.Warn("⭕ Page did not load fully", "url:", target, "error:", err)
+			}
+
+		case taskconfig.StepClick:
+			selector := expandVars(step.Selector, vars)
+			if err := clickElement(page, selector, step.Navigates); err != nil {
+				return emitted, fmt.Errorf("step %d: failed to click %s: %w", i, selector, err)
+			}
+
+		case taskconfig.StepWait:
+			if err := sleep(ctx, step.WaitMs); err != nil {
+				return emitted, err
+			}
+			continue
+
+		case taskconfig.StepExtract:
+			fields := make(map[string]string, len(step.Fields))
+			if err := r.extractFields(ctx, page, step.Fields, fields); err != nil {
+				return emitted, err
+			}
+			for key, value := range fields {
+				record[key] = value
+				vars[key] = value
+			}
+
+		case taskconfig.StepForEach:
+			records, err := r.forEach(ctx, page, step, vars, record)
+			emitted = append(emitted, records...)
+			if err != nil {
+				return emitted, err
+			}
+
+		case taskconfig.ActionHover, taskconfig.ActionMove:
+			action := taskconfig.Action{Type: step.Type, Selector: expandVars(step.Selector, vars)}
+			if err := runAction(page, action); err != nil {
+				r.Logger.Warn("⭕ Action failed", "step:", i, "type:", step.Type, "error:", err)
+			}
+
+		default:
+			return emitted, fmt.Errorf("step %d: unknown step type %q", i, step.Type)
+		}
+
+		if err := sleep(ctx, step.WaitMs); err != nil {
+			return emitted, err
+		}
+	}
+
+	if len(emitted) == 0 {
+		emitted = append(emitted, record)
+	}
+	return emitted, nil
+}
+
+// forEach собирает значения элементов и выполняет вложенные шаги для каждого из них.
+// Ошибка в одной итерации логируется и не прерывает остальные.
+func (r *RodScraper) forEach(ctx context.Context, page *rod.Page, step taskconfig.Step, vars, record map[string]string) ([]map[string]string, error) {
+	selector := expandVars(step.Selector, vars)
+	elements, err := page.Elements(selector)
+	if err != nil {
+		return nil, fmt.Errorf("foreach: failed to query %s: %w", selector, err)
+	}
+
+	var items []string
+	for _, el := range elements {
+		if step.Limit > 0 && len(items) >= step.Limit {
+			break
+		}
+		value, err := elementValue(el, step.Attribute)
+		if err != nil || value == "" {
+			continue
+		}
+		items = append(items, value)
+	}
+
+	name := step.As
+	if name == "" {
+		name = "item"
+	}
+
+	r.Logger.Debug("🔁 Iterating", "selector:", selector, "count:", len(items))
+
+	var records []map[string]string
+	for _, item := range items {
+		iterVars := copyMap(vars)
+		iterVars[name] = item
+		iterRecord := copyMap(record)
+
+		recs, err := r.runSteps(ctx, page, step.Steps, iterVars, iterRecord)
+		records = append(records, recs...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return records, err
+			}
+			r.Logger.Warn("⭕ Iteration failed", "item:", item, "error:", err)
+		}
+	}
+	return records, nil
+}
+
+// elementValue возвращает значение элемента для foreach. Для href и src
+// берется свойство DOM, чтобы получить абсолютный адрес.
+func elementValue(el *rod.Element, attribute string) (string, error) {
+	switch attribute {
+	case "", "href", "src":
+		if attribute == "" {
+			attribute = "href"
+		}
+		prop, err := el.Property(attribute)
+		if err != nil {
+			return "", err
+		}
+		return prop.Str(), nil
+	case "text":
+		return el.Text()
+	}
+
+	value, err := el.Attribute(attribute)
+	if err != nil || value == nil {
+		return "", err
+	}
+	return *value, nil
+}
+
+func clickElement(page *rod.Page, selector string, navigates bool) error {
+	page = page.Timeout(actionTimeout)
+	defer page.CancelTimeout()
+
+	el, err := page.Element(selector)
+	if err != nil {
+		return err
+	}
+
+	if !navigates {
+		return el.Click(proto.InputMouseButtonLeft, 1)
+	}
+
+	wait := page.WaitNavigation(proto.PageLifecycleEventNameLoad)
+	if err := el.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return err
+	}
+	wait()
+	return nil
+}
+
+// expandVars подставляет переменные вида {{имя}}. Неизвестные переменные остаются как есть.
+func expandVars(s string, vars map[string]string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		pairs = append(pairs, "{{"+name+"}}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+func sleep(ctx context.Context, ms int) error {
+	if ms <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return nil
+	}
+}
+
+func copyMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
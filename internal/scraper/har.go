@@ -0,0 +1,329 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// Структуры формата HAR 1.2 (http://www.softwareishard.com/blog/har-12-spec/).
+// Тела ответов не сохраняются - только размеры и метаданные. Cookies
+// не записываются, а значения заголовков с учетными данными и cookies
+// заменяются на harRedacted, как в обезличенном экспорте HAR из Chrome.
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Pages   []harPage  `json:"pages"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harPage struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     map[string]int `json:"pageTimings"`
+}
+
+type harEntry struct {
+	Pageref         string      `json:"pageref"`
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harPending - запрос, для которого еще не пришло событие завершения загрузки.
+type harPending struct {
+	entry   harEntry
+	started proto.MonotonicTime
+	timing  *proto.NetworkResourceTiming
+}
+
+// harRecorder записывает сетевую активность страницы в формате HAR.
+type harRecorder struct {
+	mu      sync.Mutex
+	page    harPage
+	pending map[proto.NetworkRequestID]*harPending
+	entries []harEntry
+	stop    func()
+}
+
+const harPageID = "page_1"
+
+// recordHAR начинает запись сетевой активности страницы. Подписку нужно сделать до навигации.
+func recordHAR(page *rod.Page, task taskconfig.Task) *harRecorder {
+	rec := &harRecorder{
+		page: harPage{
+			StartedDateTime: time.Now(),
+			ID:              harPageID,
+			Title:           task.URL,
+			PageTimings:     map[string]int{"onContentLoad": -1, "onLoad": -1},
+		},
+		pending: make(map[proto.NetworkRequestID]*harPending),
+	}
+
+	listener, cancel := page.WithCancel()
+	rec.stop = cancel
+
+	go listener.EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+
+			if prev, ok := rec.pending[e.RequestID]; ok && e.RedirectResponse != nil {
+				prev.entry.Response = harResponseFrom(e.RedirectResponse)
+				prev.entry.Response.RedirectURL = e.Request.URL
+				rec.finish(prev, e.Timestamp, e.RedirectResponse.EncodedDataLength)
+			}
+			rec.pending[e.RequestID] = &harPending{
+				entry: harEntry{
+					Pageref:         harPageID,
+					StartedDateTime: e.WallTime.Time(),
+					Request:         harRequestFrom(e.Request),
+				},
+				started: e.Timestamp,
+			}
+		},
+		func(e *proto.NetworkResponseReceived) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+
+			if p, ok := rec.pending[e.RequestID]; ok {
+				p.entry.Response = harResponseFrom(e.Response)
+				p.entry.ServerIPAddress = e.Response.RemoteIPAddress
+				p.timing = e.Response.Timing
+			}
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+
+			if p, ok := rec.pending[e.RequestID]; ok {
+				delete(rec.pending, e.RequestID)
+				rec.finish(p, e.Timestamp, e.EncodedDataLength)
+			}
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+
+			if p, ok := rec.pending[e.RequestID]; ok {
+				delete(rec.pending, e.RequestID)
+				p.entry.Comment = e.ErrorText
+				rec.finish(p, e.Timestamp, 0)
+			}
+		},
+	)()
+
+	return rec
+}
+
+// finish вычисляет тайминги и переносит запрос в готовые записи. Вызывается под mu.
+func (h *harRecorder) finish(p *harPending, finished proto.MonotonicTime, encodedLength float64) {
+	total := float64(finished-p.started) * 1000
+	p.entry.Time = total
+	p.entry.Response.BodySize = int(encodedLength)
+	p.entry.Timings = harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: total}
+
+	if t := p.timing; t != nil {
+		span := func(start, end float64) float64 {
+			if start < 0 || end < 0 {
+				return -1
+			}
+			return end - start
+		}
+		headersEnd := t.RequestTime*1000 + t.ReceiveHeadersEnd
+		p.entry.Timings = harTimings{
+			Blocked: (t.RequestTime - float64(p.started)) * 1000,
+			DNS:     span(t.DNSStart, t.DNSEnd),
+			Connect: span(t.ConnectStart, t.ConnectEnd),
+			SSL:     span(t.SslStart, t.SslEnd),
+			Send:    t.SendEnd - t.SendStart,
+			Wait:    t.ReceiveHeadersEnd - t.SendEnd,
+			Receive: float64(finished)*1000 - headersEnd,
+		}
+	}
+
+	h.entries = append(h.entries, p.entry)
+}
+
+// Save прекращает запись и сохраняет HAR в файл. Если path - каталог,
+// имя файла строится из имени задачи и времени.
+func (h *harRecorder) Save(path string, task taskconfig.Task) (string, error) {
+	h.stop()
+
+	h.mu.Lock()
+	entries := append([]harEntry(nil), h.entries...)
+	for _, p := range h.pending {
+		p.entry.Comment = "incomplete"
+		entries = append(entries, p.entry)
+	}
+	h.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		name := task.Name
+		if name == "" {
+			name = task.URL
+		}
+		path = filepath.Join(path, fmt.Sprintf("%s_%s.har",
+			unsafeFileChars.ReplaceAllString(name, "_"), time.Now().Format("20060102-150405")))
+	}
+
+	data, err := json.MarshalIndent(map[string]harLog{"log": {
+		Version: "1.2",
+		Creator: harCreator{Name: "ish3ikin", Version: "1.0"},
+		Pages:   []harPage{h.page},
+		Entries: entries,
+	}}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write HAR: %w", err)
+	}
+	return path, nil
+}
+
+func harRequestFrom(req *proto.NetworkRequest) harRequest {
+	r := harRequest{
+		Method:      req.Method,
+		URL:         req.URL + req.URLFragment,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(req.Headers),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(req.PostData),
+	}
+	if parsed, err := url.Parse(req.URL); err == nil {
+		for name, values := range parsed.Query() {
+			for _, value := range values {
+				r.QueryString = append(r.QueryString, harNameValue{Name: name, Value: value})
+			}
+		}
+	}
+	if req.PostData != "" {
+		mime := ""
+		for name, value := range req.Headers {
+			if strings.EqualFold(name, "Content-Type") {
+				mime = value.Str()
+			}
+		}
+		r.PostData = &harPostData{MimeType: mime, Text: req.PostData}
+	}
+	return r
+}
+
+func harResponseFrom(resp *proto.NetworkResponse) harResponse {
+	version := resp.Protocol
+	if version == "" {
+		version = "HTTP/1.1"
+	}
+	return harResponse{
+		Status:      resp.Status,
+		StatusText:  resp.StatusText,
+		HTTPVersion: version,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(resp.Headers),
+		Content:     harContent{Size: -1, MimeType: resp.MIMEType},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+// harRedacted заменяет в HAR значения заголовков из harSensitiveHeaders.
+const harRedacted = "[redacted]"
+
+// harSensitiveHeaders - заголовки с учетными данными в нижнем регистре.
+// Их значения, например из Auth и секретов задачи, не попадают в файл.
+var harSensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+func harHeaders(headers proto.NetworkHeaders) []harNameValue {
+	out := make([]harNameValue, 0, len(headers))
+	for name, value := range headers {
+		v := value.Str()
+		if harSensitiveHeaders[strings.ToLower(name)] {
+			v = harRedacted
+		}
+		out = append(out, harNameValue{Name: name, Value: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
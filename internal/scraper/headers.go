@@ -0,0 +1,96 @@
+package scraper
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// applyHeaders устанавливает дополнительные заголовки задачи для всех запросов страницы.
+func applyHeaders(page *rod.Page, task taskconfig.Task) error {
+	headers := make(map[string]string, len(task.Headers)+1)
+	if task.Emulation != nil && task.Emulation.Locale != "" {
+		headers["Accept-Language"] = task.Emulation.Locale
+	}
+	for name, value := range task.Headers {
+		headers[name] = value
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dict := make([]string, 0, len(headers)*2)
+	for _, name := range names {
+		dict = append(dict, name, headers[name])
+	}
+
+	if _, err := page.SetExtraHeaders(dict); err != nil {
+		return fmt.Errorf("failed to set extra headers: %w", err)
+	}
+	return nil
+}
+
+// authorize перехватывает запросы к origin задачи и добавляет к ним заголовок
+// Authorization. Запросы к сторонним доменам учетные данные не получают.
+// Возвращает функцию, которая снимает перехват.
+func authorize(page *rod.Page, task taskconfig.Task) (func(), error) {
+	header := authorizationHeader(task.Auth)
+	if header == "" {
+		return func() {}, nil
+	}
+
+	target, err := url.Parse(task.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse task url: %w", err)
+	}
+	origin := target.Scheme + "://" + target.Host
+
+	err = proto.FetchEnable{
+		Patterns: []*proto.FetchRequestPattern{{URLPattern: origin + "/*"}},
+	}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable request interception: %w", err)
+	}
+
+	listener, cancel := page.WithCancel()
+	go listener.EachEvent(func(e *proto.FetchRequestPaused) {
+		entries := []*proto.FetchHeaderEntry{{Name: "Authorization", Value: header}}
+		// Заголовки страницы могут прийти в любом регистре
+		for name, value := range e.Request.Headers {
+			if !strings.EqualFold(name, "Authorization") {
+				entries = append(entries, &proto.FetchHeaderEntry{Name: name, Value: value.Str()})
+			}
+		}
+		_ = proto.FetchContinueRequest{RequestID: e.RequestID, Headers: entries}.Call(page)
+	})()
+
+	return func() {
+		cancel()
+		_ = proto.FetchDisable{}.Call(page)
+	}, nil
+}
+
+func authorizationHeader(auth *taskconfig.Auth) string {
+	switch {
+	case auth == nil:
+		return ""
+	case auth.Token != "":
+		return "Bearer " + auth.Token
+	case auth.Username != "" || auth.Password != "":
+		credentials := auth.Username + ":" + auth.Password
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// HandleProxyAuth отвечает на запросы авторизации прокси учетными данными
+// для всех страниц браузера. Возвращает функцию, которая снимает обработку.
+// Перехват приостанавливает каждый запрос, поэтому без учетных данных он
+// не включается, а приостановленные запросы сразу продолжаются.
+func HandleProxyAuth(browser *rod.Browser, username, password string) (func(), error) {
+	if username == "" && password == "" {
+		return func() {}, nil
+	}
+	err := proto.FetchEnable{
+		Patterns:           []*proto.FetchRequestPattern{{URLPattern: "*"}},
+		HandleAuthRequests: true,
+	}.Call(browser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable proxy authentication: %w", err)
+	}
+
+	listener, cancel := browser.WithCancel()
+	go listener.EachEvent(func(e *proto.FetchRequestPaused) {
+		_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(browser)
+	}, func(e *proto.FetchAuthRequired) {
+		response := proto.FetchAuthChallengeResponseResponseDefault
+		if e.AuthChallenge.Source == proto.FetchAuthChallengeSourceProxy {
+			response = proto.FetchAuthChallengeResponseResponseProvideCredentials
+		}
+		_ = proto.FetchContinueWithAuth{
+			RequestID: e.RequestID,
+			AuthChallengeResponse: &proto.FetchAuthChallengeResponse{
+				Response: response,
+				Username: username,
+				Password: password,
+			},
+		}.Call(browser)
+	})()
+
+	return func() {
+		cancel()
+		_ = proto.FetchDisable{}.Call(browser)
+	}, nil
+}
+
+// proxyBrowser возвращает браузер, в котором открываются страницы задачи.
+// Для задачи со своим прокси создается отдельный контекст браузера с этим
+// прокси, release удаляет его вместе со страницами.
+func proxyBrowser(browser *rod.Browser, proxy string) (*rod.Browser, func(), error) {
+	if proxy == "" {
+		return browser, func() {}, nil
+	}
+	res, err := proto.TargetCreateBrowserContext{ProxyServer: proxy}.Call(browser)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create browser context for proxy: %w", err)
+	}
+	withProxy := *browser
+	withProxy.BrowserContextID = res.BrowserContextID
+	return &withProxy, func() {
+		_ = proto.TargetDisposeBrowserContext{BrowserContextID: res.BrowserContextID}.Call(browser)
+	}, nil
+}
+
+type proxyKey struct{}
+
+// withProxy возвращает контекст запроса, который ProxyTransport отправит
+// через proxy вместо своего прокси.
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyKey{}, proxy)
+}
+
+// ProxyTransport возвращает транспорт HTTP, который ходит через прокси proxyURL
+// везде, кроме хостов из bypass. Хост в bypass вида ".example.com" или
+// "*.example.com" покрывает и все поддомены. Без proxyURL прокси берется
+// из окружения. Запросы задач со своим прокси идут через него с учетными
+// данными proxyURL.
+func ProxyTransport(proxyURL *url.URL, bypass []string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if own, ok := req.Context().Value(proxyKey{}).(*url.URL); ok {
+			if proxyURL != nil && proxyURL.User != nil {
+				withUser := *own
+				withUser.User = proxyURL.User
+				return &withUser, nil
+			}
+			return own, nil
+		}
+		if proxyURL == nil {
+			return http.ProxyFromEnvironment(req)
+		}
+		if bypassProxy(req.URL.Hostname(), bypass) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+	return transport
+}
+
+func bypassProxy(host string, bypass []string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range bypass {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			pattern = suffix
+		}
+		if host == strings.TrimPrefix(pattern, ".") || (strings.HasPrefix(pattern, ".") && strings.HasSuffix(host, pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestProxyTransport(t *testing.T) {
+	global := &url.URL{Scheme: "http", Host: "global:3128", User: url.UserPassword("bob", "pw")}
+	own := &url.URL{Scheme: "socks5", Host: "own:1080"}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		target string
+		want   string
+	}{
+		{"global proxy", context.Background(), "https://example.com", "http://bob:pw@global:3128"},
+		{"bypassed host", context.Background(), "https://intranet.local", ""},
+		{"task proxy with global credentials", withProxy(context.Background(), own), "https://intranet.local", "socks5://bob:pw@own:1080"},
+	}
+	transport := ProxyTransport(global, []string{".local"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(tt.ctx, http.MethodGet, tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := transport.Proxy(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := urlString(got); s != tt.want {
+				t.Errorf("proxy = %q, want %q", s, tt.want)
+			}
+		})
+	}
+	if own.User != nil {
+		t.Error("ProxyTransport changed the task proxy URL")
+	}
+}
+
+func urlString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,119 @@
+package scraper
+
+import (
+	"encoding/base64"
+	"log/slog"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/rx3lixir/ish3ikin/internal/replay"
+)
+
+// recordResponses сохраняет в store ответы страницы вместе с телами.
+// Подписку нужно сделать до навигации; возвращаемая функция снимает ее
+// и ждет сохранения начатых ответов.
+func recordResponses(page *rod.Page, store *replay.Store, logger *slog.Logger) func() {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		requests = make(map[proto.NetworkRequestID]*replay.Response)
+	)
+	save := func(resp *replay.Response) {
+		if err := store.Save(*resp); err != nil {
+			logger.Warn("⭕ Failed to record response", "url:", resp.URL, "error:", err)
+		}
+	}
+
+	listener, cancel := page.WithCancel()
+	go listener.EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			// Редирект приходит тем же запросом: сохраняем ответ с Location
+			if prev, ok := requests[e.RequestID]; ok && e.RedirectResponse != nil {
+				prev.Status = e.RedirectResponse.Status
+				prev.Header = recordedHeader(e.RedirectResponse.Headers)
+				save(prev)
+			}
+			requests[e.RequestID] = &replay.Response{Method: e.Request.Method, URL: e.Request.URL}
+		},
+		func(e *proto.NetworkResponseReceived) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if resp, ok := requests[e.RequestID]; ok {
+				resp.Status = e.Response.Status
+				resp.Header = recordedHeader(e.Response.Headers)
+			}
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			mu.Lock()
+			resp, ok := requests[e.RequestID]
+			delete(requests, e.RequestID)
+			mu.Unlock()
+			if !ok || resp.Status == 0 {
+				return
+			}
+
+			// Тело запрашиваем вне обработчика событий, чтобы не задерживать их
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				body, err := proto.NetworkGetResponseBody{RequestID: e.RequestID}.Call(page)
+				if err != nil {
+					logger.Debug("⭕ No body to record", "url:", resp.URL, "error:", err)
+					return
+				}
+				resp.Body = []byte(body.Body)
+				if body.Base64Encoded {
+					if resp.Body, err = base64.StdEncoding.DecodeString(body.Body); err != nil {
+						logger.Warn("⭕ Failed to decode response body", "url:", resp.URL, "error:", err)
+						return
+					}
+				}
+				save(resp)
+			}()
+		},
+	)()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// replayResponses отвечает на все запросы страницы ответами из store.
+// Запросы без записи завершаются ошибкой сети, чтобы прогон не зависел
+// от живых сайтов. Возвращаемая функция снимает перехват.
+func replayResponses(page *rod.Page, store *replay.Store, logger *slog.Logger) (func(), error) {
+	router := page.HijackRequests()
+	err := router.Add("*", "", func(h *rod.Hijack) {
+		url := h.Request.URL().String()
+		resp, err := store.Load(h.Request.Method(), url)
+		if err != nil {
+			logger.Debug("⭕ Request is not recorded", "url:", url, "error:", err)
+			h.Response.Fail(proto.NetworkErrorReasonInternetDisconnected)
+			return
+		}
+		h.Response.Payload().ResponseCode = resp.Status
+		for name, value := range resp.Header {
+			h.Response.SetHeader(name, value)
+		}
+		h.Response.SetBody(resp.Body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	go router.Run()
+	return func() { _ = router.Stop() }, nil
+}
+
+func recordedHeader(headers proto.NetworkHeaders) map[string]string {
+	header := make(map[string]string, len(headers))
+	for name, value := range headers {
+		header[name] = value.Str()
+	}
+	return header
+}
@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// HTTPError возвращается, если основной документ ответил статусом 4xx/5xx,
+// а задача требует считать это ошибкой.
+type HTTPError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("page responded with status %d: %s", e.StatusCode, e.URL)
+}
+
+// watchDocument подписывается на ответ основного документа страницы.
+// Подписку нужно сделать до навигации; возвращаемая функция снимает ее
+// и отдает полученный ответ (или nil, если ответа не было).
+func watchDocument(page *rod.Page) func() *proto.NetworkResponse {
+	responses := make(chan *proto.NetworkResponse, 1)
+
+	listener, cancel := page.WithCancel()
+	go listener.EachEvent(func(e *proto.NetworkResponseReceived) bool {
+		if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != page.FrameID {
+			return false
+		}
+		responses <- e.Response
+		return true
+	})()
+
+	return func() *proto.NetworkResponse {
+		cancel()
+		select {
+		case resp := <-responses:
+			return resp
+		default:
+			return nil
+		}
+	}
+}
+
+// responseFields превращает ответ документа в поля результата.
+func responseFields(resp *proto.NetworkResponse, headers []string) map[string]string {
+	fields := map[string]string{
+		"StatusCode": strconv.Itoa(resp.Status),
+		"FinalURL":   resp.URL,
+	}
+
+	for _, want := range headers {
+		fields["Header."+want] = ""
+		for name, value := range resp.Headers {
+			if strings.EqualFold(name, want) {
+				fields["Header."+want] = value.Str()
+				break
+			}
+		}
+	}
+	return fields
+}
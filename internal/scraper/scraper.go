@@ -2,33 +2,48 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/log"
 	"github.com/go-rod/rod"
-	"github.com/go-rod/stealth"
+	"github.com/rx3lixir/ish3ikin/internal/captcha"
 	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/replay"
 )
 
+// Scraper извлекает из задачи одну или несколько записей результата.
 type Scraper interface {
-	Scrape(ctx context.Context, task taskconfig.Task) (map[string]string, error)
+	Scrape(ctx context.Context, task taskconfig.Task) ([]map[string]string, error)
 }
 
 type RodScraper struct {
 	Browser *rod.Browser
-	Logger  log.Logger
+	Logger  *slog.Logger
+	// CaptchaSolver вызывается, если на странице обнаружена капча. Может быть nil.
+	CaptchaSolver captcha.Solver
+	// DebugDir - каталог для скриншотов и HTML неудачных задач. Пустой отключает сохранение.
+	DebugDir string
+	// NavigationTimeout и ExtractionTimeout - лимиты по умолчанию для задач,
+	// в которых они не заданы. Нулевое значение снимает лимит.
+	NavigationTimeout time.Duration
+	ExtractionTimeout time.Duration
+	// Cassette записывает ответы страниц или отдает записанные вместо сети. Может быть nil.
+	Cassette *replay.Store
 }
 
-func NewRodScraper(browser *rod.Browser, logger log.Logger) *RodScraper {
+func NewRodScraper(browser *rod.Browser, logger *slog.Logger) *RodScraper {
 	return &RodScraper{
 		Browser: browser,
 		Logger:  logger,
 	}
 }
 
-// Scrape выполняет скрапинг и возвращает результаты.
-func (r *RodScraper) Scrape(ctx context.Context, task taskconfig.Task) (map[string]string, error) {
+// Scrape выполняет скрапинг и возвращает результаты. Обычная задача дает
+// одну запись, сценарий из шагов (Steps) - по записи на каждую итерацию foreach.
+func (r *RodScraper) Scrape(ctx context.Context, task taskconfig.Task) (records []map[string]string, err error) {
 	r.Logger.Info("🌐 Starting scraping", "url:", task.URL)
 
 	select {
@@ -37,10 +52,58 @@ func (r *RodScraper) Scrape(ctx context.Context, task taskconfig.Task) (map[stri
 	default:
 	}
 
-	page, err := stealth.Page(r.Browser)
+	browser, release, err := proxyBrowser(r.Browser, task.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	page, err := newPage(browser, task.Stealth)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %v", err)
 	}
+	defer page.Close()
+
+	if r.DebugDir != "" {
+		defer func() {
+			switch {
+			case err != nil:
+				r.saveArtifacts(page, task, err.Error())
+			case allFieldsEmpty(records, task):
+				r.saveArtifacts(page, task, "all fields are empty")
+			}
+		}()
+	}
+
+	if err := emulate(browser, page, task); err != nil {
+		return nil, fmt.Errorf("failed to apply emulation: %w", err)
+	}
+
+	if err := applyFingerprint(page, task.Stealth); err != nil {
+		return nil, err
+	}
+
+	if err := applyHeaders(page, task); err != nil {
+		return nil, err
+	}
+
+	if r.Cassette != nil && r.Cassette.Replaying() {
+		// Запросы перехватывает replay, авторизация не нужна
+		stopReplay, err := replayResponses(page, r.Cassette, r.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up replay: %w", err)
+		}
+		defer stopReplay()
+	} else {
+		if r.Cassette != nil {
+			defer recordResponses(page, r.Cassette, r.Logger)()
+		}
+		stopAuth, err := authorize(page, task)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up authorization: %w", err)
+		}
+		defer stopAuth()
+	}
 
 	select {
 	case <-ctx.Done():
@@ -48,59 +111,162 @@ func (r *RodScraper) Scrape(ctx context.Context, task taskconfig.Task) (map[stri
 	default:
 	}
 
-	err = page.Navigate(task.URL)
+	var console *consoleRecorder
+	if task.CaptureConsole {
+		console = recordConsole(page, r.Logger, task.URL)
+		defer console.Stop()
+	}
+
+	if task.HAR != "" {
+		har := recordHAR(page, task)
+		defer func() {
+			path, err := har.Save(task.HAR, task)
+			if err != nil {
+				r.Logger.Warn("⭕ Failed to save HAR", "url:", task.URL, "error:", err)
+				return
+			}
+			r.Logger.Info("📼 HAR saved", "path:", path)
+		}()
+	}
+
+	defer r.handleDialogs(page, task.Dialogs)()
+
+	document := watchDocument(page)
+
+	navTimeout := timeoutFor(task.NavigationTimeout, r.NavigationTimeout)
+	nav, cancelNav := withTimeout(page.Context(ctx), navTimeout)
+	defer cancelNav()
+
+	var waitIdle func()
+	if task.WaitUntil == taskconfig.WaitIdle {
+		waitIdle = nav.WaitRequestIdle(requestIdle, nil, nil, nil)
+	}
+
+	err = nav.Navigate(task.URL)
 	if err != nil {
+		document()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("navigation timed out after %s: %w", navTimeout, err)
+		}
 		return nil, fmt.Errorf("failed to navigate to page: %v", err)
 	}
 
-	err = page.WaitLoad()
-	if err != nil {
-		r.Logger.Warn("⭕ Page did not load fully", "url:", task.URL, "error:", err)
+	if err := waitPage(nav, task, waitIdle); err != nil {
+		r.Logger.Warn("⭕ Page did not load fully", "url:", task.URL, "wait:", task.WaitUntil, "error:", err)
+	}
+	if task.WaitSelector != "" {
+		if _, err := nav.Element(task.WaitSelector); err != nil {
+			r.Logger.Warn("⭕ Wait selector did not appear", "url:", task.URL, "selector:", task.WaitSelector, "error:", err)
+		}
+	}
+	cancelNav()
+
+	response := document()
+	if response == nil {
+		r.Logger.Warn("⭕ No document response captured", "url:", task.URL)
+	} else if response.Status >= 400 && task.FailOnHTTPError {
+		return nil, &HTTPError{StatusCode: response.Status, URL: response.URL}
+	}
+
+	// Все, что дальше, ограничено лимитом на извлечение и контекстом задачи.
+	work, cancelWork := withTimeout(page.Context(ctx), timeoutFor(task.ExtractionTimeout, r.ExtractionTimeout))
+	defer cancelWork()
+
+	r.dismissConsent(work, task.Dialogs)
+
+	if err := r.handleCaptcha(ctx, work, task); err != nil {
+		return nil, fmt.Errorf("captcha: %w", err)
+	}
+
+	if err := r.runActions(ctx, work, task.Actions); err != nil {
+		return nil, err
 	}
 
 	results := make(map[string]string)
 	results["URL"] = task.URL
 	results["Type"] = task.Type
-
-	for key, selector := range task.Selectors {
-		select {
-		case <-ctx.Done():
-			r.Logger.Warn("⭕ Scraping canceled during selector processing", "key:", key)
-			return results, fmt.Errorf("scraping canceled: %w", ctx.Err())
-		default:
+	if response != nil {
+		for key, value := range responseFields(response, task.ResponseHeaders) {
+			results[key] = value
 		}
+	}
 
-		if selector == "" {
-			results[key] = ""
-			continue
+	if len(task.Steps) > 0 {
+		vars := map[string]string{"URL": task.URL}
+		records, err := r.runSteps(ctx, work, task.Steps, vars, results)
+		// Сообщения консоли относятся ко всей странице, их получает каждая запись
+		if console != nil {
+			messages := strings.Join(console.Stop(), "\n")
+			for _, record := range records {
+				record["Console"] = messages
+			}
 		}
+		return records, err
+	}
+
+	if err := r.extractFields(ctx, work, task.Selectors, results); err != nil {
+		return []map[string]string{results}, err
+	}
 
-		elements, err := page.Elements(selector)
-		if err != nil || len(elements) == 0 {
-			r.Logger.Warn("⭕ No elements found", "selector:", selector, "error:", err)
-			results[key] = ""
-			continue
+	if len(task.Structured) > 0 {
+		items, err := structuredData(work)
+		if err != nil {
+			r.Logger.Warn("⭕ Failed to extract structured data", "url:", task.URL, "error:", err)
 		}
 
-		var texts []string
-		for _, element := range elements {
-			select {
-			case <-ctx.Done():
-				r.Logger.Warn("⭕ Scraping canceled during element processing", "key:", key)
-				return results, fmt.Errorf("scraping canceled: %w", ctx.Err())
-			default:
+		for key, path := range task.Structured {
+			value, ok := lookupStructured(items, path)
+			if !ok {
+				r.Logger.Warn("⭕ No structured data found", "path:", path)
 			}
-			text, err := element.Text()
-			if err != nil {
-				r.Logger.Warn("⭕ Failed to get text for element", "selector:", selector, "error:", err)
-				continue
-			}
-			texts = append(texts, text)
+			results[key] = value
 		}
+	}
+
+	if console != nil {
+		results["Console"] = strings.Join(console.Stop(), "\n")
+	}
+
+	return []map[string]string{results}, nil
+}
 
-		results[key] = strings.Join(texts, "\n")
-		r.Logger.Info("✅ Successfully scraped", "key:", key, "count:", len(texts))
+// timeoutFor возвращает лимит задачи в секундах или значение по умолчанию.
+func timeoutFor(seconds int, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
 	}
+	return fallback
+}
 
-	return results, nil
+// withTimeout ограничивает страницу лимитом d. cancel освобождает таймер
+// лимита, без лимита он ничего не делает.
+const (
+	// requestIdle - сколько страница не должна отправлять запросы для WaitIdle.
+	requestIdle = 500 * time.Millisecond
+	// domStable - сколько DOM не должен меняться для WaitStable.
+	domStable = time.Second
+)
+
+// waitPage ждет загрузки страницы по стратегии из WaitUntil. waitIdle -
+// ожидание тишины в сети, подготовленное до навигации для WaitIdle.
+func waitPage(page *rod.Page, task taskconfig.Task, waitIdle func()) error {
+	if err := page.WaitLoad(); err != nil {
+		return err
+	}
+	switch task.WaitUntil {
+	case taskconfig.WaitIdle:
+		waitIdle()
+		return page.GetContext().Err()
+	case taskconfig.WaitStable:
+		return page.WaitDOMStable(domStable, 0)
+	}
+	return nil
+}
+
+func withTimeout(page *rod.Page, d time.Duration) (*rod.Page, func()) {
+	if d <= 0 {
+		return page, func() {}
+	}
+	limited := page.Timeout(d)
+	return limited, func() { limited.CancelTimeout() }
 }
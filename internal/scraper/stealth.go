@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/go-rod/stealth"
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+)
+
+// fingerprintJS переопределяет свойства navigator и параметры WebGL до загрузки
+// скриптов страницы. Пустые значения в конфиге оставляют свойство без изменений.
+const fingerprintJS = `(fp) => {
+	const define = (obj, prop, value) => Object.defineProperty(obj, prop, { get: () => value, configurable: true });
+	if (fp.platform) define(Navigator.prototype, "platform", fp.platform);
+	if (fp.languages && fp.languages.length) {
+		define(Navigator.prototype, "languages", Object.freeze(fp.languages.slice()));
+		define(Navigator.prototype, "language", fp.languages[0]);
+	}
+	if (fp.userAgent) define(Navigator.prototype, "userAgent", fp.userAgent);
+
+	const patch = (proto) => {
+		if (!proto || (!fp.webglVendor && !fp.webglRenderer)) return;
+		const getParameter = proto.getParameter;
+		proto.getParameter = function (param) {
+			if (param === 37445 && fp.webglVendor) return fp.webglVendor;
+			if (param === 37446 && fp.webglRenderer) return fp.webglRenderer;
+			return getParameter.call(this, param);
+		};
+	};
+	patch(window.WebGLRenderingContext && WebGLRenderingContext.prototype);
+	patch(window.WebGL2RenderingContext && WebGL2RenderingContext.prototype);
+}`
+
+type fingerprint struct {
+	UserAgent     string   `json:"userAgent,omitempty"`
+	Platform      string   `json:"platform,omitempty"`
+	Languages     []string `json:"languages,omitempty"`
+	WebGLVendor   string   `json:"webglVendor,omitempty"`
+	WebGLRenderer string   `json:"webglRenderer,omitempty"`
+}
+
+// newPage создает страницу с патчами stealth или без них, в зависимости от задачи.
+func newPage(browser *rod.Browser, s *taskconfig.Stealth) (*rod.Page, error) {
+	if s.On() {
+		return stealth.Page(browser)
+	}
+	return browser.Page(proto.TargetCreateTarget{})
+}
+
+// applyFingerprint подменяет отдельные параметры отпечатка браузера.
+func applyFingerprint(page *rod.Page, s *taskconfig.Stealth) error {
+	if s == nil {
+		return nil
+	}
+
+	fp := fingerprint{
+		UserAgent:     s.UserAgent,
+		Platform:      s.Platform,
+		Languages:     s.Languages,
+		WebGLVendor:   s.WebGLVendor,
+		WebGLRenderer: s.WebGLRenderer,
+	}
+	if fp.UserAgent == "" && fp.Platform == "" && len(fp.Languages) == 0 &&
+		fp.WebGLVendor == "" && fp.WebGLRenderer == "" {
+		return nil
+	}
+
+	if s.UserAgent != "" {
+		err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+			UserAgent: s.UserAgent,
+			Platform:  s.Platform,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to override user agent: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(fp)
+	if err != nil {
+		return fmt.Errorf("failed to encode fingerprint: %w", err)
+	}
+	if _, err := page.EvalOnNewDocument(fmt.Sprintf("(%s)(%s)", fingerprintJS, data)); err != nil {
+		return fmt.Errorf("failed to inject fingerprint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// structuredDataJS собирает на странице все объекты schema.org: содержимое
+// тегов JSON-LD и элементы microdata (itemscope/itemprop), приведенные к тому же виду.
+const structuredDataJS = `() => {
+	const items = [];
+
+	const push = (value) => {
+		if (Array.isArray(value)) { value.forEach(push); return; }
+		if (!value || typeof value !== "object") return;
+		if (Array.isArray(value["@graph"])) value["@graph"].forEach(push);
+		items.push(value);
+	};
+
+	document.querySelectorAll('script[type="application/ld+json"]').forEach((script) => {
+		try { push(JSON.parse(script.textContent)); } catch (e) {}
+	});
+
+	const propValue = (el) => {
+		if (el.hasAttribute("itemscope")) return readItem(el);
+		if (el.hasAttribute("content")) return el.getAttribute("content");
+		switch (el.tagName) {
+			case "A": case "LINK": case "AREA": return el.href;
+			case "IMG": case "AUDIO": case "VIDEO": case "SOURCE": case "IFRAME": case "EMBED": return el.src;
+			case "META": return el.getAttribute("content") || "";
+			case "TIME": return el.getAttribute("datetime") || el.textContent.trim();
+			case "DATA": case "METER": return el.getAttribute("value") || el.textContent.trim();
+		}
+		return el.textContent.trim();
+	};
+
+	const readItem = (root) => {
+		const item = {};
+		const type = root.getAttribute("itemtype");
+		if (type) item["@type"] = type.trim().split(/\s+/)[0];
+
+		const walk = (el) => {
+			for (const child of el.children) {
+				if (child.hasAttribute("itemprop")) {
+					const value = propValue(child);
+					for (const name of child.getAttribute("itemprop").trim().split(/\s+/)) {
+						if (name in item) {
+							if (!Array.isArray(item[name])) item[name] = [item[name]];
+							item[name].push(value);
+						} else {
+							item[name] = value;
+						}
+					}
+				}
+				if (!child.hasAttribute("itemscope")) walk(child);
+			}
+		};
+		walk(root);
+		return item;
+	};
+
+	document.querySelectorAll("[itemscope]:not([itemprop])").forEach((el) => push(readItem(el)));
+
+	return JSON.stringify(items);
+}`
+
+// structuredData извлекает со страницы все объекты schema.org.
+func structuredData(page *rod.Page) ([]interface{}, error) {
+	res, err := page.Eval(structuredDataJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate structured data script: %w", err)
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal([]byte(res.Value.Str()), &items); err != nil {
+		return nil, fmt.Errorf("failed to decode structured data: %w", err)
+	}
+	return items, nil
+}
+
+// lookupStructured находит значение по пути вида "Product.offers.price".
+// Первый сегмент - тип schema.org, остальные - свойства. Массивы можно
+// индексировать числом, иначе берется первый элемент, содержащий нужное свойство.
+func lookupStructured(items []interface{}, path string) (string, bool) {
+	segments := strings.Split(path, ".")
+	if len(segments) == 0 || segments[0] == "" {
+		return "", false
+	}
+
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok || !hasSchemaType(obj, segments[0]) {
+			continue
+		}
+		if value, ok := walkPath(obj, segments[1:]); ok {
+			return formatStructured(value), true
+		}
+	}
+	return "", false
+}
+
+// hasSchemaType проверяет, что @type объекта совпадает с искомым типом.
+// Учитываются полные IRI вида "https://schema.org/Product" и списки типов.
+func hasSchemaType(obj map[string]interface{}, want string) bool {
+	matches := func(t string) bool {
+		if i := strings.LastIndexAny(t, "/#:"); i >= 0 {
+			t = t[i+1:]
+		}
+		return strings.EqualFold(t, want)
+	}
+
+	switch t := obj["@type"].(type) {
+	case string:
+		return matches(t)
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && matches(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func walkPath(value interface{}, segments []string) (interface{}, bool) {
+	if len(segments) == 0 {
+		return value, value != nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		next, ok := v[segments[0]]
+		if !ok {
+			return nil, false
+		}
+		return walkPath(next, segments[1:])
+	case []interface{}:
+		if idx, err := strconv.Atoi(segments[0]); err == nil {
+			if idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			return walkPath(v[idx], segments[1:])
+		}
+		for _, elem := range v {
+			if found, ok := walkPath(elem, segments); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func formatStructured(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
@@ -2,37 +2,106 @@ package scraper
 
 import (
 	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
 
-	"github.com/charmbracelet/log"
 	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
 )
 
 type ScraperTask struct {
 	Task    taskconfig.Task
-	Context context.Context
 	Scraper Scraper
-	Logger  *log.Logger
+	Logger  *slog.Logger
 }
 
-func NewScraperTask(task taskconfig.Task, ctx context.Context, scraper Scraper, logger log.Logger) *ScraperTask {
+func NewScraperTask(task taskconfig.Task, scraper Scraper, logger *slog.Logger) *ScraperTask {
 	return &ScraperTask{
 		Task:    task,
-		Context: ctx,
 		Scraper: scraper,
-		Logger:  &logger,
+		Logger:  logger,
 	}
 }
 
-func (s *ScraperTask) Execute() (interface{}, error) {
-	res, err := s.Scraper.Scrape(s.Context, s.Task)
+// Name возвращает имя задачи из конфига, а если его нет - URL.
+func (s *ScraperTask) Name() string {
+	if s.Task.Name != "" {
+		return s.Task.Name
+	}
+	return s.Task.URL
+}
+
+// Priority возвращает приоритет задачи из конфига.
+func (s *ScraperTask) Priority() int {
+	return s.Task.Priority
+}
+
+// DependsOn возвращает имена задач, от которых зависит задача.
+func (s *ScraperTask) DependsOn() []string {
+	return s.Task.DependsOn
+}
+
+// Timeout возвращает лимит времени задачи из конфига, 0 - лимит пула.
+func (s *ScraperTask) Timeout() time.Duration {
+	return time.Duration(s.Task.TimeoutSeconds) * time.Second
+}
+
+// MaxRetries возвращает число повторов задачи из конфига, -1 - значение пула.
+func (s *ScraperTask) MaxRetries() int {
+	if s.Task.Retries == nil {
+		return -1
+	}
+	return *s.Task.Retries
+}
+
+// Key возвращает хост задачи, чтобы пул мог ограничить число
+// одновременных запросов к одному сайту.
+func (s *ScraperTask) Key() string {
+	u, err := url.Parse(s.Task.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// UniqueKey возвращает нормализованный URL задачи для удаления дубликатов:
+// схема и хост без учета регистра, без фрагмента.
+func (s *ScraperTask) UniqueKey() string {
+	u, err := url.Parse(s.Task.URL)
+	if err != nil {
+		return s.Task.URL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	return u.String()
+}
+
+func (s *ScraperTask) Execute(ctx context.Context) ([]map[string]string, error) {
+	res, err := s.Scraper.Scrape(ctx, s.Task)
 	if err != nil {
 		return nil, err
 	}
+	for _, record := range res {
+		record["TaskID"] = s.Task.ID
+		for name, value := range s.Task.Vars {
+			record["Param."+name] = value
+		}
+	}
 
-	s.Logger.Infof("Scraped Result for %v: %s", s.Task.URL, res)
+	s.Logger.Debug("Scraped Result", "url:", s.Task.URL, "result:", res)
 	return res, nil
 }
 
+// OnStart вызывается пулом перед каждой попыткой выполнения.
+func (s *ScraperTask) OnStart(attempt int) {
+	if attempt > 1 {
+		s.Logger.Debug("Scraping again", "task id:", s.Task.ID, "url:", s.Task.URL, "attempt:", attempt)
+	}
+}
+
+// OnError вызывается пулом, когда задача окончательно завершилась ошибкой.
 func (s *ScraperTask) OnError(err error) {
-	s.Logger.Error("Failed to scrape a task")
+	s.Logger.Error("Failed to scrape a task", "task id:", s.Task.ID, "url:", s.Task.URL, "error:", err)
 }
@@ -0,0 +1,89 @@
+package seen
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var tasksBucket = []byte("tasks")
+
+// Bolt хранит ключи в файле bbolt вместе со временем, когда истекает их срок.
+// Истекшие записи удаляются при открытии файла.
+type Bolt struct {
+	db *bolt.DB
+}
+
+func openBolt(path string) (*Bolt, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create seen store directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open seen store %s: %w", path, err)
+	}
+	now := time.Now()
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(tasksBucket)
+		if err != nil {
+			return err
+		}
+		var expired [][]byte
+		err = bucket.ForEach(func(k, v []byte) error {
+			if !now.Before(expiry(v)) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize seen store %s: %w", path, err)
+	}
+	return &Bolt{db: db}, nil
+}
+
+func (b *Bolt) Fresh(_ context.Context, key string) (bool, error) {
+	var fresh bool
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(tasksBucket).Get([]byte(key)); v != nil {
+			fresh = time.Now().Before(expiry(v))
+		}
+		return nil
+	})
+	return fresh, err
+}
+
+func (b *Bolt) Mark(_ context.Context, key string, ttl time.Duration) error {
+	v := binary.BigEndian.AppendUint64(nil, uint64(time.Now().Add(ttl).UnixNano()))
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tasksBucket).Put([]byte(key), v)
+	})
+}
+
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
+
+// expiry разбирает время истечения записи. Поврежденная запись считается
+// истекшей.
+func expiry(v []byte) time.Time {
+	if len(v) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(v)))
+}
@@ -0,0 +1,62 @@
+package seen
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRedisPrefix - префикс ключей, если в адресе нет параметра key.
+const defaultRedisPrefix = "ish3ikin:seen:"
+
+// Redis хранит каждый ключ задачи отдельным ключом, срок которого истекает
+// средствами Redis. Хранилище можно делить между несколькими экземплярами.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// openRedis подключается к Redis по адресу redis://host:port/db?key=prefix.
+func openRedis(u *url.URL) (*Redis, error) {
+	q := u.Query()
+	prefix := q.Get("key")
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	q.Del("key")
+	u.RawQuery = q.Encode()
+
+	opts, err := redis.ParseURL(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+	return NewRedis(redis.NewClient(opts), prefix), nil
+}
+
+// NewRedis создает хранилище на ключах с префиксом prefix.
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (r *Redis) Fresh(ctx context.Context, key string) (bool, error) {
+	n, err := r.client.Exists(ctx, r.prefix+key).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check seen task: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *Redis) Mark(ctx context.Context, key string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, r.prefix+key, time.Now().Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to mark seen task: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с Redis.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
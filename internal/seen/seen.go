@@ -0,0 +1,43 @@
+// Package seen запоминает задачи, которые уже выполнялись, на заданный срок,
+// чтобы повторные запуски пропускали свежие страницы и выполняли только
+// новые и устаревшие. Задачи узнаются по строковому ключу, например имени
+// и URL задачи.
+package seen
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Store - хранилище ключей выполненных задач.
+type Store interface {
+	// Fresh сообщает, что key выполнялся и его срок еще не истек.
+	Fresh(ctx context.Context, key string) (bool, error)
+	// Mark запоминает key на срок ttl.
+	Mark(ctx context.Context, key string, ttl time.Duration) error
+	// Close освобождает файл или соединение хранилища.
+	Close() error
+}
+
+// Open открывает хранилище по адресу:
+//
+//	/path/to/seen.bolt                        - файл bbolt
+//	redis://[:password@]host:port/db?key=name - ключи Redis с префиксом name
+func Open(addr string) (Store, error) {
+	if !strings.Contains(addr, "://") {
+		return openBolt(addr)
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse seen store url: %w", err)
+	}
+	switch u.Scheme {
+	case "redis", "rediss":
+		return openRedis(u)
+	default:
+		return nil, fmt.Errorf("unsupported seen store scheme %q", u.Scheme)
+	}
+}
@@ -0,0 +1,171 @@
+package seen
+
+import (
+	"context"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	bolt "go.etcd.io/bbolt"
+)
+
+func openTestStore(t *testing.T, path string) Store {
+	t.Helper()
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return store
+}
+
+func fresh(t *testing.T, store Store, key string) bool {
+	t.Helper()
+	ok, err := store.Fresh(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Fresh(%q): %v", key, err)
+	}
+	return ok
+}
+
+func TestBoltFreshness(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t, filepath.Join(t.TempDir(), "runs", "seen.bolt"))
+	defer store.Close()
+
+	if fresh(t, store, "news\x00https://example.com/") {
+		t.Error("unknown key is fresh")
+	}
+	if err := store.Mark(ctx, "news\x00https://example.com/", time.Hour); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if !fresh(t, store, "news\x00https://example.com/") {
+		t.Error("marked key is not fresh")
+	}
+	// Другая задача с тем же URL хранится отдельно
+	if fresh(t, store, "prices\x00https://example.com/") {
+		t.Error("key of another task with the same URL is fresh")
+	}
+
+	if err := store.Mark(ctx, "old", -time.Second); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if fresh(t, store, "old") {
+		t.Error("expired key is fresh")
+	}
+}
+
+func TestBoltPrunesExpired(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "seen.bolt")
+	store := openTestStore(t, path)
+	if err := store.Mark(ctx, "expired", -time.Second); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if err := store.Mark(ctx, "kept", time.Hour); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Открытие удаляет истекшие записи и сохраняет остальные
+	store = openTestStore(t, path)
+	if !fresh(t, store, "kept") {
+		t.Error("key is not fresh after reopening")
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("bolt.Open: %v", err)
+	}
+	defer db.Close()
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(tasksBucket).Get([]byte("expired")) != nil {
+			t.Error("expired key was not pruned")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+}
+
+func TestBoltExpiresWhileClosed(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "seen.bolt")
+	store := openTestStore(t, path)
+	if err := store.Mark(ctx, "short", 50*time.Millisecond); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if err := store.Mark(ctx, "long", time.Hour); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	store = openTestStore(t, path)
+	defer store.Close()
+	if fresh(t, store, "short") {
+		t.Error("key is fresh after its TTL passed while the store was closed")
+	}
+	if !fresh(t, store, "long") {
+		t.Error("key with a TTL left is not fresh after reopening")
+	}
+}
+
+func TestRedisFreshness(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	store := NewRedis(redis.NewClient(&redis.Options{Addr: srv.Addr()}), "test:")
+	defer store.Close()
+
+	if fresh(t, store, "news") {
+		t.Error("unknown key is fresh")
+	}
+	if err := store.Mark(ctx, "news", time.Minute); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if !fresh(t, store, "news") || !srv.Exists("test:news") {
+		t.Error("marked key is not fresh or not stored under the prefix")
+	}
+	srv.FastForward(2 * time.Minute)
+	if fresh(t, store, "news") {
+		t.Error("key is fresh after its TTL")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	at := time.Now().Add(time.Minute).Truncate(time.Nanosecond)
+	v := binary.BigEndian.AppendUint64(nil, uint64(at.UnixNano()))
+	if got := expiry(v); !got.Equal(at) {
+		t.Errorf("expiry = %v, want %v", got, at)
+	}
+	if got := expiry([]byte("bad")); !got.IsZero() {
+		t.Errorf("expiry of a damaged value = %v, want zero time", got)
+	}
+}
+
+func TestOpenScheme(t *testing.T) {
+	if _, err := Open("memcached://localhost:11211"); err == nil {
+		t.Error("Open accepted an unsupported scheme")
+	}
+	store, err := Open("redis://localhost:6379/0?key=test:")
+	if err != nil {
+		t.Fatalf("Open(redis): %v", err)
+	}
+	defer store.Close()
+	r, ok := store.(*Redis)
+	if !ok {
+		t.Fatalf("Open(redis) = %T, want *Redis", store)
+	}
+	if r.prefix != "test:" {
+		t.Errorf("prefix = %q, want test:", r.prefix)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxArtifacts ограничивает число артефактов в панели.
+	maxArtifacts = 50
+	// artifactTimeLayout - время в имени артефакта, см. scraper.saveArtifacts.
+	artifactTimeLayout = "20060102-150405"
+)
+
+// Artifact - скриншот и HTML страницы неудачной задачи из каталога
+// отладки. Файлы называются <имя задачи>_<время>.png и .html.
+type Artifact struct {
+	Task       string
+	Time       time.Time
+	Screenshot string `json:",omitempty"`
+	HTML       string `json:",omitempty"`
+}
+
+// Artifacts возвращает артефакты из ArtifactsDir, новые первыми.
+func (s *Server) Artifacts() ([]Artifact, error) {
+	artifacts := []Artifact{}
+	if s.opts.ArtifactsDir == "" {
+		return artifacts, nil
+	}
+	files, err := os.ReadDir(s.opts.ArtifactsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return artifacts, nil
+		}
+		return nil, fmt.Errorf("failed to read debug artifacts: %w", err)
+	}
+
+	byBase := make(map[string]*Artifact)
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || !artifactExt(ext) {
+			continue
+		}
+		base := strings.TrimSuffix(f.Name(), ext)
+		a, ok := byBase[base]
+		if !ok {
+			a = &Artifact{Task: base}
+			if i := strings.LastIndex(base, "_"); i >= 0 {
+				if t, err := time.ParseInLocation(artifactTimeLayout, base[i+1:], time.Local); err == nil {
+					a.Task, a.Time = base[:i], t
+				}
+			}
+			if a.Time.IsZero() {
+				if info, err := f.Info(); err == nil {
+					a.Time = info.ModTime()
+				}
+			}
+			byBase[base] = a
+		}
+		if ext == ".png" {
+			a.Screenshot = f.Name()
+		} else {
+			a.HTML = f.Name()
+		}
+	}
+
+	for _, a := range byBase {
+		artifacts = append(artifacts, *a)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Time.After(artifacts[j].Time) })
+	if len(artifacts) > maxArtifacts {
+		artifacts = artifacts[:maxArtifacts]
+	}
+	return artifacts, nil
+}
+
+// artifact отдает файл артефакта. HTML страницы отдается в песочнице,
+// чтобы ее скрипты не выполнялись от имени панели.
+func (s *Server) artifact(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("file")
+	if s.opts.ArtifactsDir == "" || name != filepath.Base(name) || !artifactExt(filepath.Ext(name)) {
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(w, req, filepath.Join(s.opts.ArtifactsDir, name))
+}
+
+func artifactExt(ext string) bool {
+	return ext == ".png" || ext == ".html"
+}
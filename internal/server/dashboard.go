@@ -0,0 +1,17 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed ui/index.html
+var dashboardHTML []byte
+
+// dashboard отдает веб-панель: задачи планировщика, последние запуски,
+// доля успешных завершений задач, их последние значения и скриншоты ошибок.
+func (s *Server) dashboard(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(dashboardHTML)
+}
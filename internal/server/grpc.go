@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	pb "github.com/rx3lixir/ish3ikin/pkg/scraperpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCOptions возвращает опции gRPC-сервера, проверяющие токен, если он задан.
+// Токен передается в метаданных "authorization: Bearer <token>".
+func (s *Server) GRPCOptions() []grpc.ServerOption {
+	if s.opts.Token == "" {
+		return nil
+	}
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || !s.validToken(values[0]) {
+			return status.Error(codes.Unauthenticated, "missing or invalid token")
+		}
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// RegisterGRPC регистрирует сервис Scraper на gRPC-сервере.
+func (s *Server) RegisterGRPC(gs *grpc.Server) {
+	pb.RegisterScraperServer(gs, &grpcService{server: s})
+}
+
+// grpcService реализует gRPC-сервис поверх запусков Server.
+type grpcService struct {
+	pb.UnimplementedScraperServer
+	server *Server
+}
+
+func (g *grpcService) SubmitTasks(ctx context.Context, req *pb.SubmitTasksRequest) (*pb.Run, error) {
+	tasks := make([]taskconfig.Task, 0, len(req.GetTasks()))
+	for _, t := range req.GetTasks() {
+		task := taskconfig.Task{Name: t.GetName(), URL: t.GetUrl(), Engine: t.GetEngine()}
+		if len(t.GetSelectors()) > 0 {
+			task.Selectors = make(map[string]taskconfig.Selector, len(t.GetSelectors()))
+			for field, selector := range t.GetSelectors() {
+				task.Selectors[field] = taskconfig.ParseSelector(selector)
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	if len(req.GetTasksJson()) > 0 {
+		parsed, err := taskconfig.Parse("request", req.GetTasksJson())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		tasks = append(tasks, parsed...)
+	}
+	tasks, err := CheckTasks(tasks)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	run, err := g.server.Submit(tasks)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	g.server.opts.Logger.Info("📥 Run submitted", "run id:", run.ID, "tasks:", len(tasks), "via:", "grpc")
+	return runProto(run), nil
+}
+
+func (g *grpcService) Results(req *pb.ResultsRequest, stream grpc.ServerStreamingServer[pb.TaskResult]) error {
+	err := g.server.Watch(stream.Context(), req.GetRunId(), func(task TaskStatus, records []map[string]string) error {
+		result := &pb.TaskResult{RunId: req.GetRunId(), Task: taskProto(task), Records: make([]*pb.Record, len(records))}
+		for i, record := range records {
+			result.Records[i] = &pb.Record{Fields: record}
+		}
+		return stream.Send(result)
+	})
+	return grpcError(err)
+}
+
+func (g *grpcService) GetRun(ctx context.Context, req *pb.GetRunRequest) (*pb.Run, error) {
+	run, err := g.server.Status(req.GetRunId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return runProto(run), nil
+}
+
+func (g *grpcService) CancelRun(ctx context.Context, req *pb.CancelRunRequest) (*pb.Run, error) {
+	run, err := g.server.Cancel(req.GetRunId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return runProto(run), nil
+}
+
+// grpcError переводит ошибку запуска в статус gRPC.
+func grpcError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrFinished):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrBusy):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func runProto(run RunStatus) *pb.Run {
+	p := &pb.Run{
+		Id:            run.ID,
+		Status:        run.Status,
+		CreatedUnixMs: run.Created.UnixMilli(),
+		Total:         int32(run.Total),
+		Queued:        int32(run.Queued),
+		Running:       int32(run.Running),
+		Succeeded:     int32(run.Succeeded),
+		Failed:        int32(run.Failed),
+		Canceled:      int32(run.Canceled),
+		Records:       int32(run.Records),
+	}
+	if run.Finished != nil {
+		p.FinishedUnixMs = run.Finished.UnixMilli()
+	}
+	for _, task := range run.Tasks {
+		p.Tasks = append(p.Tasks, taskProto(task))
+	}
+	return p
+}
+
+func taskProto(task TaskStatus) *pb.TaskStatus {
+	return &pb.TaskStatus{
+		Id:         task.ID,
+		Name:       task.Name,
+		Url:        task.URL,
+		Status:     task.Status,
+		Attempts:   int32(task.Attempts),
+		Records:    int32(task.Records),
+		DurationMs: task.duration.Milliseconds(),
+		Error:      task.Error,
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/rx3lixir/ish3ikin/pkg/scraperpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient поднимает gRPC-сервис s в памяти и возвращает клиент к нему.
+func newGRPCClient(t *testing.T, s *Server) pb.ScraperClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer(s.GRPCOptions()...)
+	s.RegisterGRPC(gs)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewScraperClient(conn)
+}
+
+func TestGRPCSubmitAndResults(t *testing.T) {
+	client := newGRPCClient(t, newServer(t, Options{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	run, err := client.SubmitTasks(ctx, &pb.SubmitTasksRequest{
+		Tasks:     []*pb.Task{{Name: "ok", Url: "https://example.com/ok", Selectors: map[string]string{"Title": "h1"}}},
+		TasksJson: []byte(`{"Name": "fail", "URL": "https://example.com/fail", "Selectors": {"Title": "h1"}}`),
+	})
+	if err != nil {
+		t.Fatalf("SubmitTasks: %v", err)
+	}
+	if run.GetTotal() != 2 {
+		t.Errorf("run total = %d, want 2", run.GetTotal())
+	}
+
+	stream, err := client.Results(ctx, &pb.ResultsRequest{RunId: run.GetId()})
+	if err != nil {
+		t.Fatalf("Results: %v", err)
+	}
+	got := make(map[string]*pb.TaskResult)
+	for {
+		result, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		got[result.GetTask().GetName()] = result
+	}
+	if ok := got["ok"]; ok == nil || ok.GetTask().GetStatus() != TaskSucceeded || len(ok.GetRecords()) != 1 ||
+		ok.GetRecords()[0].GetFields()["URL"] != "https://example.com/ok" {
+		t.Errorf("result of ok = %v, want a succeeded task with its record", ok)
+	}
+	if fail := got["fail"]; fail == nil || fail.GetTask().GetStatus() != TaskFailed || fail.GetTask().GetError() != "page is broken" {
+		t.Errorf("result of fail = %v, want a failed task with its error", fail)
+	}
+
+	final, err := client.GetRun(ctx, &pb.GetRunRequest{RunId: run.GetId()})
+	if err != nil {
+		t.Fatalf("GetRun: %v", err)
+	}
+	if final.GetStatus() != RunFailed || final.GetSucceeded() != 1 || final.GetFailed() != 1 {
+		t.Errorf("run = %v, want failed with one succeeded and one failed task", final)
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	client := newGRPCClient(t, newServer(t, Options{Token: "s3cret", MaxRuns: 1}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	authorized := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cret")
+	blocking := &pb.SubmitTasksRequest{Tasks: []*pb.Task{{Url: "https://example.com/block", Selectors: map[string]string{"Title": "h1"}}}}
+
+	_, err := client.GetRun(ctx, &pb.GetRunRequest{RunId: "x"})
+	checkCode(t, "GetRun without token", err, codes.Unauthenticated)
+
+	_, err = client.GetRun(authorized, &pb.GetRunRequest{RunId: "missing"})
+	checkCode(t, "GetRun of an unknown run", err, codes.NotFound)
+
+	_, err = client.SubmitTasks(authorized, &pb.SubmitTasksRequest{Tasks: []*pb.Task{{Name: "no url"}}})
+	checkCode(t, "SubmitTasks without URL", err, codes.InvalidArgument)
+
+	run, err := client.SubmitTasks(authorized, blocking)
+	if err != nil {
+		t.Fatalf("SubmitTasks: %v", err)
+	}
+	_, err = client.SubmitTasks(authorized, blocking)
+	checkCode(t, "SubmitTasks above MaxRuns", err, codes.ResourceExhausted)
+
+	canceled, err := client.CancelRun(authorized, &pb.CancelRunRequest{RunId: run.GetId()})
+	if err != nil || canceled.GetStatus() != RunCanceled {
+		t.Fatalf("CancelRun = %v, %v, want a canceled run", canceled, err)
+	}
+	_, err = client.CancelRun(authorized, &pb.CancelRunRequest{RunId: run.GetId()})
+	checkCode(t, "CancelRun of a finished run", err, codes.FailedPrecondition)
+}
+
+func checkCode(t *testing.T, call string, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("%s: code %s (%v), want %s", call, got, err, want)
+	}
+}
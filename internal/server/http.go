@@ -0,0 +1,179 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxBodySize ограничивает размер запроса с задачами.
+const maxBodySize = 10 << 20
+
+// Handler возвращает обработчик веб-панели и REST API:
+//
+//	GET  /                   - веб-панель
+//	POST /tasks              - запустить задачу или пакет задач
+//	GET  /runs               - список запусков
+//	GET  /runs/{id}          - состояние запуска и его задач
+//	GET  /runs/{id}/results  - записи запуска, собранные к этому моменту
+//	POST /runs/{id}/cancel   - отменить запуск
+//	GET  /jobs               - задачи планировщика
+//	GET  /stats              - итоги задач по запускам в памяти
+//	GET  /artifacts          - скриншоты и HTML неудачных задач
+//	GET  /artifacts/{file}   - файл артефакта
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("POST /tasks", s.submit)
+	api.HandleFunc("GET /runs", s.list)
+	api.HandleFunc("GET /runs/{id}", s.get)
+	api.HandleFunc("GET /runs/{id}/results", s.results)
+	api.HandleFunc("POST /runs/{id}/cancel", s.cancel)
+	api.HandleFunc("GET /jobs", s.jobs)
+	api.HandleFunc("GET /stats", s.stats)
+	api.HandleFunc("GET /artifacts", s.artifacts)
+	api.HandleFunc("GET /artifacts/{file}", s.artifact)
+
+	mux := http.NewServeMux()
+	// Сама панель данных не содержит и открывается без токена,
+	// данные она запрашивает у API с токеном.
+	mux.HandleFunc("GET /{$}", s.dashboard)
+	mux.Handle("/", s.authorize(api))
+	return mux
+}
+
+// authorize проверяет токен, если он задан.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !s.validToken(req.Header.Get("Authorization")) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// validToken сравнивает заголовок Authorization с токеном сервера.
+func (s *Server) validToken(header string) bool {
+	want := "Bearer " + s.opts.Token
+	return subtle.ConstantTimeCompare([]byte(header), []byte(want)) == 1
+}
+
+// submit принимает задачи и запускает их.
+func (s *Server) submit(w http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("failed to read request: %w", err))
+		return
+	}
+	tasks, err := ParseTasks(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	status, err := s.Submit(tasks)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+	s.opts.Logger.Info("📥 Run submitted", "run id:", status.ID, "tasks:", len(tasks), "remote:", req.RemoteAddr)
+	w.Header().Set("Location", "/runs/"+status.ID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+func (s *Server) list(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, s.Runs())
+}
+
+func (s *Server) get(w http.ResponseWriter, req *http.Request) {
+	status, err := s.Status(req.PathValue("id"))
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// results отдает записи запуска: JSON-массивом или, с ?format=jsonl,
+// по записи в строке.
+func (s *Server) results(w http.ResponseWriter, req *http.Request) {
+	records, err := s.Results(req.PathValue("id"))
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, records)
+	case "jsonl":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return
+			}
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown format %q, expected json or jsonl", format))
+	}
+}
+
+func (s *Server) cancel(w http.ResponseWriter, req *http.Request) {
+	status, err := s.Cancel(req.PathValue("id"))
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) jobs(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, s.Jobs())
+}
+
+func (s *Server) stats(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, s.Stats())
+}
+
+func (s *Server) artifacts(w http.ResponseWriter, req *http.Request) {
+	artifacts, err := s.Artifacts()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, artifacts)
+}
+
+// writeStatusError отвечает кодом, соответствующим ошибке запуска.
+func writeStatusError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrFinished):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, ErrBusy):
+		writeError(w, http.StatusTooManyRequests, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"Error": strings.TrimSpace(err.Error())})
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+// stubTask возвращает запись с URL задачи. Задачи с "block" в URL ждут
+// отмены, с "fail" - завершаются ошибкой.
+type stubTask struct {
+	task taskconfig.Task
+}
+
+func (t stubTask) Name() string  { return t.task.Name }
+func (t stubTask) OnError(error) {}
+
+func (t stubTask) Execute(ctx context.Context) ([]map[string]string, error) {
+	switch {
+	case strings.Contains(t.task.URL, "block"):
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case strings.Contains(t.task.URL, "fail"):
+		return nil, errors.New("page is broken")
+	}
+	return []map[string]string{{"URL": t.task.URL}}, nil
+}
+
+// newServer создает сервер, который выполняет задачи через stubTask.
+func newServer(t *testing.T, opts Options) *Server {
+	t.Helper()
+	opts.NewTask = func(task taskconfig.Task) workerpool.Task[[]map[string]string] { return stubTask{task} }
+	opts.Workers = 2
+	opts.PoolOptions = []workerpool.Option{workerpool.WithRetries(0)}
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(opts)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestServer(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(newServer(t, opts).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// call выполняет запрос к API и разбирает ответ в out, если он задан.
+func call(t *testing.T, method, url, body string, out interface{}) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, url, err)
+		}
+	}
+	return resp
+}
+
+// waitFinished опрашивает запуск, пока он не закончится.
+func waitFinished(t *testing.T, srv *httptest.Server, id string) RunStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var status RunStatus
+		call(t, http.MethodGet, srv.URL+"/runs/"+id, "", &status)
+		if status.Status != RunRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("run %s did not finish", id)
+	return RunStatus{}
+}
+
+const tasksJSON = `[
+	{"Name": "ok", "URL": "https://example.com/ok", "Selectors": {"Title": "h1"}},
+	{"Name": "fail", "URL": "https://example.com/fail", "Selectors": {"Title": "h1"}}
+]`
+
+func TestSubmit(t *testing.T) {
+	srv := newTestServer(t, Options{})
+
+	var submitted RunStatus
+	resp := call(t, http.MethodPost, srv.URL+"/tasks", tasksJSON, &submitted)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /tasks = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	if loc := resp.Header.Get("Location"); loc != "/runs/"+submitted.ID {
+		t.Errorf("Location = %q, want /runs/%s", loc, submitted.ID)
+	}
+
+	status := waitFinished(t, srv, submitted.ID)
+	if status.Status != RunFailed || status.Succeeded != 1 || status.Failed != 1 || status.Records != 1 {
+		t.Errorf("run = %+v, want failed with one succeeded and one failed task", status)
+	}
+	for _, task := range status.Tasks {
+		if task.Name == "fail" && task.Error != "page is broken" {
+			t.Errorf("task fail error = %q, want the task error", task.Error)
+		}
+	}
+
+	var records []map[string]string
+	call(t, http.MethodGet, srv.URL+"/runs/"+submitted.ID+"/results", "", &records)
+	if len(records) != 1 || records[0]["URL"] != "https://example.com/ok" {
+		t.Errorf("results = %v, want the record of the ok task", records)
+	}
+}
+
+func TestSubmitRejectsInvalidTasks(t *testing.T) {
+	srv := newTestServer(t, Options{})
+	for name, body := range map[string]string{
+		"not json":   `{`,
+		"no url":     `{"Name": "a", "Selectors": {"Title": "h1"}}`,
+		"har path":   `{"URL": "https://example.com", "Selectors": {"Title": "h1"}, "HAR": "/etc/passwd"}`,
+		"all off":    `{"URL": "https://example.com", "Selectors": {"Title": "h1"}, "Enabled": false}`,
+		"bad depend": `[{"Name": "a", "URL": "https://example.com", "Selectors": {"Title": "h1"}, "DependsOn": ["missing"]}]`,
+	} {
+		var reply map[string]string
+		resp := call(t, http.MethodPost, srv.URL+"/tasks", body, &reply)
+		if resp.StatusCode != http.StatusBadRequest || reply["Error"] == "" {
+			t.Errorf("%s: POST /tasks = %d %v, want 400 with an error", name, resp.StatusCode, reply)
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	srv := newTestServer(t, Options{})
+
+	var submitted RunStatus
+	call(t, http.MethodPost, srv.URL+"/tasks", `{"URL": "https://example.com/block", "Selectors": {"Title": "h1"}}`, &submitted)
+
+	var canceled RunStatus
+	resp := call(t, http.MethodPost, srv.URL+"/runs/"+submitted.ID+"/cancel", "", &canceled)
+	if resp.StatusCode != http.StatusOK || canceled.Status != RunCanceled {
+		t.Fatalf("cancel = %d %+v, want 200 and a canceled run", resp.StatusCode, canceled)
+	}
+
+	if resp := call(t, http.MethodPost, srv.URL+"/runs/"+submitted.ID+"/cancel", "", nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("second cancel = %d, want %d", resp.StatusCode, http.StatusConflict)
+	}
+	if resp := call(t, http.MethodPost, srv.URL+"/runs/missing/cancel", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("cancel of an unknown run = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestMaxRuns(t *testing.T) {
+	srv := newTestServer(t, Options{MaxRuns: 1})
+	blocking := `{"URL": "https://example.com/block", "Selectors": {"Title": "h1"}}`
+
+	var first RunStatus
+	if resp := call(t, http.MethodPost, srv.URL+"/tasks", blocking, &first); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("first run = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	if resp := call(t, http.MethodPost, srv.URL+"/tasks", blocking, nil); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("run above MaxRuns = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+
+	// Отмененный запуск освобождает место
+	call(t, http.MethodPost, srv.URL+"/runs/"+first.ID+"/cancel", "", nil)
+	if resp := call(t, http.MethodPost, srv.URL+"/tasks", tasksJSON, nil); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("run after cancel = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+}
+
+func TestToken(t *testing.T) {
+	srv := newTestServer(t, Options{Token: "s3cret"})
+
+	if resp := call(t, http.MethodGet, srv.URL+"/runs", "", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /runs without token = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/runs", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /runs with token = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	// Панель открывается без токена
+	if resp := call(t, http.MethodGet, srv.URL+"/", "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("GET / without token = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+// Статусы запуска.
+const (
+	RunRunning = "running"
+	// RunSucceeded - все задачи выполнены без ошибок.
+	RunSucceeded = "succeeded"
+	// RunFailed - хотя бы одна задача завершилась ошибкой.
+	RunFailed   = "failed"
+	RunCanceled = "canceled"
+)
+
+// Статусы задачи запуска.
+const (
+	TaskQueued    = "queued"
+	TaskRunning   = "running"
+	TaskSucceeded = "succeeded"
+	TaskFailed    = "failed"
+	// TaskCanceled - задача не выполнена, потому что запуск отменен.
+	TaskCanceled = "canceled"
+)
+
+// RunStatus - состояние запуска, которое отдает API.
+type RunStatus struct {
+	ID       string
+	Status   string
+	Created  time.Time
+	Finished *time.Time `json:",omitempty"`
+	// Total - число задач запуска, остальные счетчики - по статусам.
+	Total     int
+	Queued    int
+	Running   int
+	Succeeded int
+	Failed    int
+	Canceled  int
+	Records   int
+	Tasks     []TaskStatus `json:",omitempty"`
+}
+
+// TaskStatus - состояние задачи запуска.
+type TaskStatus struct {
+	ID       string
+	Name     string
+	URL      string
+	Status   string
+	Attempts int    `json:",omitempty"`
+	Records  int    `json:",omitempty"`
+	Duration string `json:",omitempty"`
+	Error    string `json:",omitempty"`
+
+	duration time.Duration
+}
+
+// run - запуск задач, присланных одним запросом. У каждого запуска свой пул.
+type run struct {
+	id      string
+	created time.Time
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu       sync.Mutex
+	finished time.Time
+	canceled bool
+	tasks    []TaskStatus
+	specs    []taskconfig.Task
+	// byPool переводит номер задачи в пуле в индекс tasks.
+	byPool  map[int]int
+	records []map[string]string
+	// settled - индексы задач в порядке завершения, taskRecords - их записи.
+	settled     []int
+	taskRecords [][]map[string]string
+	// changed закрывается и заменяется, когда завершается задача.
+	changed chan struct{}
+}
+
+func newRun(id string, tasks []taskconfig.Task, cancel context.CancelFunc) *run {
+	r := &run{
+		id:      id,
+		created: time.Now(),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		tasks:   make([]TaskStatus, len(tasks)),
+		specs:   tasks,
+		byPool:  make(map[int]int, len(tasks)),
+		changed: make(chan struct{}),
+
+		taskRecords: make([][]map[string]string, len(tasks)),
+	}
+	for i, task := range tasks {
+		r.tasks[i] = TaskStatus{ID: task.ID, Name: task.Name, URL: task.URL, Status: TaskQueued}
+	}
+	return r
+}
+
+// hooks отмечают начало выполнения задач запуска.
+func (r *run) hooks() workerpool.Hooks {
+	return workerpool.Hooks{
+		OnTaskStart: func(e workerpool.TaskEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if i, ok := r.byPool[e.TaskID]; ok {
+				r.tasks[i].Status = TaskRunning
+				r.tasks[i].Attempts = e.Attempt
+			}
+		},
+	}
+}
+
+// collect забирает результаты пула, пока он не закончит работу, и передает
+// каждый в onTask, если он задан.
+func (r *run) collect(pool *workerpool.Pool[[]map[string]string], runErr <-chan error, onTask func(string, taskconfig.Task, []map[string]string, error)) {
+	defer close(r.done)
+	for res := range pool.Results() {
+		r.mu.Lock()
+		i, ok := r.byPool[res.TaskID]
+		if ok {
+			task := &r.tasks[i]
+			task.Attempts = res.Attempts
+			task.duration = res.Duration
+			task.Duration = res.Duration.Round(time.Millisecond).String()
+			if res.Err != nil {
+				task.Status, task.Error = TaskFailed, res.Err.Error()
+			} else {
+				task.Status, task.Records = TaskSucceeded, len(res.Value)
+				r.records = append(r.records, res.Value...)
+				r.taskRecords[i] = res.Value
+			}
+			r.settle(i)
+		}
+		r.mu.Unlock()
+		if ok && onTask != nil {
+			onTask(r.id, r.specs[i], res.Value, res.Err)
+		}
+	}
+	<-runErr
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range pool.Abandoned() {
+		if i, ok := r.byPool[t.TaskID]; ok {
+			r.tasks[i].Status = TaskCanceled
+			r.settle(i)
+		}
+	}
+	r.finished = time.Now()
+	r.notify()
+}
+
+// settle отмечает, что задача i завершена. Вызывается под mu.
+func (r *run) settle(i int) {
+	r.settled = append(r.settled, i)
+	r.notify()
+}
+
+// notify будит тех, кто ждет в watch. Вызывается под mu.
+func (r *run) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// watch вызывает fn для каждой завершенной задачи по порядку завершения,
+// пока запуск не закончится или не отменят ctx.
+func (r *run) watch(ctx context.Context, fn func(TaskStatus, []map[string]string) error) error {
+	type settled struct {
+		task    TaskStatus
+		records []map[string]string
+	}
+	next := 0
+	for {
+		r.mu.Lock()
+		batch := make([]settled, 0, len(r.settled)-next)
+		for _, i := range r.settled[next:] {
+			batch = append(batch, settled{task: r.tasks[i], records: r.taskRecords[i]})
+		}
+		next = len(r.settled)
+		changed, finished := r.changed, !r.finished.IsZero()
+		r.mu.Unlock()
+
+		for _, s := range batch {
+			if err := fn(s.task, s.records); err != nil {
+				return err
+			}
+		}
+		if finished {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// stats добавляет в byName итоги завершенных задач запуска. Запуски
+// передаются по порядку создания, поэтому последние значения перезаписываются.
+func (r *run) stats(byName map[string]*TaskStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, task := range r.tasks {
+		if task.Status != TaskSucceeded && task.Status != TaskFailed {
+			continue
+		}
+		st, ok := byName[task.Name]
+		if !ok {
+			st = &TaskStats{Name: task.Name}
+			byName[task.Name] = st
+		}
+		st.URL = task.URL
+		st.Runs++
+		st.LastStatus, st.LastRun, st.LastError = task.Status, r.created, task.Error
+		if task.Status == TaskFailed {
+			st.Failed++
+			continue
+		}
+		st.Succeeded++
+		if records := r.taskRecords[i]; len(records) > 0 {
+			st.LastValues = records[len(records)-1]
+		}
+	}
+}
+
+// status возвращает состояние запуска, с задачами, если withTasks.
+func (r *run) status(withTasks bool) RunStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := RunStatus{ID: r.id, Created: r.created, Total: len(r.tasks), Records: len(r.records)}
+	for _, task := range r.tasks {
+		switch task.Status {
+		case TaskQueued:
+			s.Queued++
+		case TaskRunning:
+			s.Running++
+		case TaskSucceeded:
+			s.Succeeded++
+		case TaskFailed:
+			s.Failed++
+		case TaskCanceled:
+			s.Canceled++
+		}
+	}
+	switch {
+	case r.finished.IsZero():
+		s.Status = RunRunning
+	case r.canceled:
+		s.Status = RunCanceled
+	case s.Failed > 0 || s.Canceled > 0:
+		s.Status = RunFailed
+	default:
+		s.Status = RunSucceeded
+	}
+	if !r.finished.IsZero() {
+		finished := r.finished
+		s.Finished = &finished
+	}
+	if withTasks {
+		s.Tasks = append([]TaskStatus(nil), r.tasks...)
+	}
+	return s
+}
+
+// results возвращает записи, собранные к этому моменту.
+func (r *run) results() []map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(make([]map[string]string, 0, len(r.records)), r.records...)
+}
+
+// stop отменяет запуск: задачи из очереди не выполняются, выполняемые прерываются.
+func (r *run) stop() {
+	r.mu.Lock()
+	if r.finished.IsZero() {
+		r.canceled = true
+	}
+	r.mu.Unlock()
+	r.cancel()
+}
+
+// isFinished сообщает, что пул запуска закончил работу.
+func (r *run) isFinished() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
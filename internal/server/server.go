@@ -0,0 +1,365 @@
+// Package server выполняет задачи скрапинга, присланные другими системами
+// или запущенные по расписанию, и отдает их статус и результаты через REST
+// и веб-панель (см. Handler) и gRPC (см. RegisterGRPC). Запуски хранятся
+// в памяти процесса.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	"github.com/rx3lixir/ish3ikin/internal/scheduler"
+	"github.com/rx3lixir/ish3ikin/pkg/workerpool"
+)
+
+var (
+	// ErrNotFound - запуска нет или он уже удален из памяти.
+	ErrNotFound = errors.New("run not found")
+	// ErrFinished - запуск уже закончен и не может быть отменен.
+	ErrFinished = errors.New("run is already finished")
+	// ErrBusy - уже идет MaxRuns запусков.
+	ErrBusy = errors.New("too many active runs")
+)
+
+// Options - настройки сервера.
+type Options struct {
+	// NewTask создает задачу пула для присланной задачи.
+	NewTask func(taskconfig.Task) workerpool.Task[[]map[string]string]
+	// Workers - число воркеров пула каждого запуска.
+	Workers int
+	// PoolOptions применяются к пулу каждого запуска.
+	PoolOptions []workerpool.Option
+	// KeepRuns - сколько завершенных запусков хранить в памяти, 0 - без ограничения.
+	KeepRuns int
+	// MaxRuns - сколько запусков может идти одновременно, 0 - без ограничения.
+	MaxRuns int
+	// Token, если задан, требуется в заголовке "Authorization: Bearer <token>".
+	Token string
+	// Scheduler, если задан, - планировщик, задачи которого показывает панель.
+	Scheduler *scheduler.Scheduler
+	// ArtifactsDir - каталог скриншотов и HTML неудачных задач (--debug-artifacts).
+	ArtifactsDir string
+	// OnTaskDone, если задан, вызывается с результатом каждой завершенной задачи.
+	OnTaskDone func(runID string, task taskconfig.Task, records []map[string]string, err error)
+	// OnFinish, если задан, вызывается с итогами каждого завершенного запуска.
+	OnFinish func(RunStatus)
+	Logger   *slog.Logger
+}
+
+// Server выполняет присланные задачи и хранит запуски в памяти.
+type Server struct {
+	opts Options
+	ctx  context.Context
+	stop context.CancelFunc
+	// finishing - запуски, которые еще сообщают о завершении.
+	finishing sync.WaitGroup
+
+	mu   sync.Mutex
+	runs map[string]*run
+	// order - идентификаторы запусков в порядке создания.
+	order []string
+	// active - число незавершенных запусков, см. MaxRuns.
+	active int
+}
+
+// New создает сервер. Запуски работают, пока не вызван Close.
+func New(opts Options) *Server {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Server{
+		opts: opts,
+		ctx:  ctx,
+		stop: stop,
+		runs: make(map[string]*run),
+	}
+}
+
+// Close отменяет все запуски и ждет, пока их пулы остановятся.
+func (s *Server) Close() {
+	s.stop()
+	s.mu.Lock()
+	runs := make([]*run, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, r)
+	}
+	s.mu.Unlock()
+	for _, r := range runs {
+		r.stop()
+		<-r.done
+	}
+	s.finishing.Wait()
+}
+
+// ParseTasks разбирает и проверяет присланные задачи: JSON одной задачи,
+// массива задач или объекта с Tasks, как в файле задач. Переменные
+// окружения и секреты сервера в них не подставляются.
+func ParseTasks(data []byte) ([]taskconfig.Task, error) {
+	tasks, err := taskconfig.Parse("request", data)
+	if err != nil {
+		return nil, err
+	}
+	return CheckTasks(tasks)
+}
+
+// CheckTasks отбрасывает выключенные задачи, проверяет остальные
+// и выдает им ID.
+func CheckTasks(tasks []taskconfig.Task) ([]taskconfig.Task, error) {
+	tasks, _ = taskconfig.SplitEnabled(tasks)
+	if len(tasks) == 0 {
+		return nil, errors.New("no enabled tasks in the request")
+	}
+	// Для одиночных задач имя необязательно
+	for i := range tasks {
+		if tasks[i].Name == "" {
+			tasks[i].Name = tasks[i].URL
+		}
+	}
+	if err := taskconfig.Validate("request", tasks); err != nil {
+		return nil, fmt.Errorf("invalid tasks:\n%w", err)
+	}
+	// Путь HAR указывает на файловую систему сервера
+	for _, task := range tasks {
+		if task.HAR != "" {
+			return nil, fmt.Errorf("task %s: HAR is not allowed in submitted tasks", task.Name)
+		}
+	}
+	if err := taskconfig.AssignIDs(tasks); err != nil {
+		return nil, fmt.Errorf("invalid task IDs: %w", err)
+	}
+	if err := taskconfig.CheckDependencies(tasks); err != nil {
+		return nil, fmt.Errorf("invalid task dependencies: %w", err)
+	}
+	return tasks, nil
+}
+
+// Submit запускает проверенные задачи и возвращает состояние нового запуска.
+// Если уже идет MaxRuns запусков, возвращает ErrBusy.
+func (s *Server) Submit(tasks []taskconfig.Task) (RunStatus, error) {
+	s.mu.Lock()
+	if s.opts.MaxRuns > 0 && s.active >= s.opts.MaxRuns {
+		s.mu.Unlock()
+		return RunStatus{}, fmt.Errorf("%w: limit is %d", ErrBusy, s.opts.MaxRuns)
+	}
+	s.active++
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	r := newRun(newRunID(), tasks, cancel)
+
+	opts := append(append([]workerpool.Option(nil), s.opts.PoolOptions...), workerpool.WithHooks(r.hooks()))
+	pool, err := workerpool.NewPool[[]map[string]string](s.opts.Workers, len(tasks), opts...)
+	if err != nil {
+		cancel()
+		s.release()
+		return RunStatus{}, fmt.Errorf("failed to create worker pool: %w", err)
+	}
+	// Пул еще не запущен, поэтому хуки не читают byPool одновременно с записью
+	for i, task := range tasks {
+		id, err := pool.AddTask(ctx, s.opts.NewTask(task))
+		if err != nil {
+			cancel()
+			s.release()
+			return RunStatus{}, fmt.Errorf("failed to add task %s: %w", task.ID, err)
+		}
+		r.byPool[id] = i
+	}
+	pool.Close()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- pool.Run(ctx)
+	}()
+	s.finishing.Add(1)
+	go func() {
+		defer s.finishing.Done()
+		r.collect(pool, runErr, s.opts.OnTaskDone)
+		s.release()
+		status := r.status(false)
+		s.opts.Logger.Info("🏁 Run finished", "run id:", r.id, "status:", status.Status,
+			"succeeded:", status.Succeeded, "failed:", status.Failed, "canceled:", status.Canceled, "records:", status.Records)
+		if s.opts.OnFinish != nil {
+			s.opts.OnFinish(r.status(true))
+		}
+	}()
+
+	s.mu.Lock()
+	s.runs[r.id] = r
+	s.order = append(s.order, r.id)
+	s.evict()
+	s.mu.Unlock()
+	return r.status(false), nil
+}
+
+// release освобождает место запуска, занятое в Submit.
+func (s *Server) release() {
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+}
+
+// evict удаляет самые старые завершенные запуски сверх KeepRuns.
+// Вызывается под mu.
+func (s *Server) evict() {
+	if s.opts.KeepRuns <= 0 {
+		return
+	}
+	finished := 0
+	for _, id := range s.order {
+		if s.runs[id].isFinished() {
+			finished++
+		}
+	}
+	order := s.order[:0]
+	for _, id := range s.order {
+		if finished > s.opts.KeepRuns && s.runs[id].isFinished() {
+			delete(s.runs, id)
+			finished--
+			continue
+		}
+		order = append(order, id)
+	}
+	s.order = order
+}
+
+// Runs возвращает состояние всех запусков, новые первыми.
+func (s *Server) Runs() []RunStatus {
+	s.mu.Lock()
+	runs := make([]RunStatus, 0, len(s.order))
+	for _, id := range s.order {
+		runs = append(runs, s.runs[id].status(false))
+	}
+	s.mu.Unlock()
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Created.After(runs[j].Created) })
+	return runs
+}
+
+// Status возвращает состояние запуска вместе с его задачами.
+func (s *Server) Status(id string) (RunStatus, error) {
+	r, err := s.lookup(id)
+	if err != nil {
+		return RunStatus{}, err
+	}
+	return r.status(true), nil
+}
+
+// Results возвращает записи запуска, собранные к этому моменту.
+func (s *Server) Results(id string) ([]map[string]string, error) {
+	r, err := s.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.results(), nil
+}
+
+// Cancel отменяет запуск и ждет, пока его пул остановится.
+func (s *Server) Cancel(id string) (RunStatus, error) {
+	r, err := s.lookup(id)
+	if err != nil {
+		return RunStatus{}, err
+	}
+	if r.isFinished() {
+		return RunStatus{}, fmt.Errorf("%w: %s", ErrFinished, id)
+	}
+	r.stop()
+	<-r.done
+	s.opts.Logger.Info("🛑 Run canceled", "run id:", id)
+	return r.status(true), nil
+}
+
+// Wait ждет, пока запуск закончится или отменят ctx, и возвращает
+// состояние запуска.
+func (s *Server) Wait(ctx context.Context, id string) (RunStatus, error) {
+	r, err := s.lookup(id)
+	if err != nil {
+		return RunStatus{}, err
+	}
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return RunStatus{}, ctx.Err()
+	}
+	return r.status(true), nil
+}
+
+// Jobs возвращает задачи планировщика, если он задан.
+func (s *Server) Jobs() []scheduler.Job {
+	if s.opts.Scheduler == nil {
+		return []scheduler.Job{}
+	}
+	return s.opts.Scheduler.Jobs()
+}
+
+// TaskStats - итоги задачи по запускам, которые хранятся в памяти.
+type TaskStats struct {
+	Name string
+	URL  string
+	// Runs - сколько раз задача завершилась, успешно или с ошибкой.
+	Runs      int
+	Succeeded int
+	Failed    int
+	// SuccessRate - доля успешных завершений в процентах.
+	SuccessRate float64
+	LastStatus  string
+	LastRun     time.Time
+	LastError   string `json:",omitempty"`
+	// LastValues - последняя запись последнего успешного запуска с записями.
+	LastValues map[string]string `json:",omitempty"`
+}
+
+// Stats возвращает итоги задач по имени в порядке имен.
+func (s *Server) Stats() []TaskStats {
+	s.mu.Lock()
+	runs := make([]*run, 0, len(s.order))
+	for _, id := range s.order {
+		runs = append(runs, s.runs[id])
+	}
+	s.mu.Unlock()
+
+	byName := make(map[string]*TaskStats)
+	for _, r := range runs {
+		r.stats(byName)
+	}
+	stats := make([]TaskStats, 0, len(byName))
+	for _, st := range byName {
+		st.SuccessRate = float64(st.Succeeded) * 100 / float64(st.Runs)
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Watch вызывает fn для каждой завершенной задачи запуска: сначала для уже
+// завершенных, потом по мере завершения остальных. Возвращает nil, когда
+// запуск закончен, ошибку fn или ошибку ctx.
+func (s *Server) Watch(ctx context.Context, id string, fn func(TaskStatus, []map[string]string) error) error {
+	r, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+	return r.watch(ctx, fn)
+}
+
+// lookup находит запуск по id.
+func (s *Server) lookup(id string) (*run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return r, nil
+}
+
+// newRunID возвращает случайный идентификатор запуска.
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,188 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rx3lixir/ish3ikin/internal/config/taskconfig"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	pendingBucket   = []byte("pending")
+	completedBucket = []byte("completed")
+	recordsBucket   = []byte("records")
+	tasksBucket     = []byte("tasks")
+	buckets         = [][]byte{pendingBucket, completedBucket, recordsBucket, tasksBucket}
+)
+
+// TaskInfo - задача запуска, как она названа в конфиге. По ней задача
+// находится, когда состояние читается без файла задач.
+type TaskInfo struct {
+	ID   string
+	Name string
+	URL  string
+}
+
+// Store хранит на диске состояние запуска: какие задачи еще не выполнены,
+// а какие завершились успешно и с какими записями. По нему прерванный
+// запуск можно продолжить, не потеряв уже выгруженные результаты.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open открывает или создает файл состояния.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize state file %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Reset забывает состояние предыдущего запуска.
+func (s *Store) Reset() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddPending отмечает задачи как ожидающие выполнения.
+func (s *Store) AddPending(tasks map[string]TaskInfo) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		pending, info := tx.Bucket(pendingBucket), tx.Bucket(tasksBucket)
+		for key, task := range tasks {
+			data, err := json.Marshal(task)
+			if err != nil {
+				return fmt.Errorf("failed to encode task: %w", err)
+			}
+			if err := pending.Put([]byte(key), []byte(task.URL)); err != nil {
+				return err
+			}
+			if err := info.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MarkDone переносит задачу из ожидающих в выполненные и сохраняет
+// ее записи.
+func (s *Store) MarkDone(key string, records []map[string]string) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(pendingBucket).Delete([]byte(key)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(recordsBucket).Put([]byte(key), data); err != nil {
+			return err
+		}
+		return tx.Bucket(completedBucket).Put([]byte(key), []byte(time.Now().Format(time.RFC3339)))
+	})
+}
+
+// Records возвращает записи, сохраненные для выполненной задачи.
+func (s *Store) Records(key string) ([]map[string]string, error) {
+	var records []map[string]string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(recordsBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &records); err != nil {
+			return fmt.Errorf("failed to decode records of %s: %w", key, err)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// Done сообщает, была ли задача выполнена в предыдущем запуске.
+func (s *Store) Done(key string) (bool, error) {
+	var done bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		done = tx.Bucket(completedBucket).Get([]byte(key)) != nil
+		return nil
+	})
+	return done, err
+}
+
+// Pending возвращает ключи невыполненных задач.
+func (s *Store) Pending() ([]string, error) {
+	return s.keys(pendingBucket)
+}
+
+// Completed возвращает ключи выполненных задач.
+func (s *Store) Completed() ([]string, error) {
+	return s.keys(completedBucket)
+}
+
+func (s *Store) keys(bucket []byte) ([]string, error) {
+	var keys []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// Task возвращает задачу по ключу. Для состояний, записанных до того,
+// как задачи стали сохраняться, известен только URL невыполненной задачи.
+func (s *Store) Task(key string) (TaskInfo, error) {
+	var task TaskInfo
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket(tasksBucket).Get([]byte(key)); data != nil {
+			return json.Unmarshal(data, &task)
+		}
+		task.URL = string(tx.Bucket(pendingBucket).Get([]byte(key)))
+		return nil
+	})
+	return task, err
+}
+
+// TaskKey возвращает ключ задачи, не зависящий от запуска: хеш ее конфигурации.
+// Измененная в конфиге задача считается новой. ID не учитывается, потому что
+// выданный по номеру ID меняется при перестановке задач.
+func TaskKey(task taskconfig.Task) string {
+	task.ID = ""
+	data, err := json.Marshal(task)
+	if err != nil {
+		data = []byte(task.URL)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	refreshInterval = 500 * time.Millisecond
+	// recentLimit и logLimit - сколько завершенных задач и строк лога видно в панели.
+	recentLimit = 8
+	logLimit    = 6
+	barWidth    = 30
+)
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	headerStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("8"))
+	okStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	failStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	retryStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	barFullStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("4"))
+)
+
+type (
+	statsMsg     Stats
+	logMsg       string
+	taskStartMsg struct {
+		id, name, url string
+		attempt       int
+		at            time.Time
+	}
+	taskRetryMsg struct {
+		id      string
+		attempt int
+		err     error
+	}
+	taskDoneMsg struct {
+		id       string
+		records  int
+		duration time.Duration
+		err      error
+	}
+)
+
+// taskRow - строка задачи в панели.
+type taskRow struct {
+	id, name, url string
+	attempt       int
+	started       time.Time
+	lastErr       error
+	records       int
+	duration      time.Duration
+	err           error
+}
+
+type model struct {
+	cancel   func()
+	stats    Stats
+	running  map[string]*taskRow
+	recent   []*taskRow
+	logs     []string
+	width    int
+	quitting bool
+}
+
+func newModel(cancel func()) *model {
+	return &model{cancel: cancel, running: make(map[string]*taskRow), width: 100}
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			m.quitting = true
+			m.cancel()
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case statsMsg:
+		m.stats = Stats(msg)
+	case logMsg:
+		m.logs = appendLimited(m.logs, string(msg), logLimit)
+	case taskStartMsg:
+		row, ok := m.running[msg.id]
+		if !ok {
+			row = &taskRow{id: msg.id, name: msg.name, url: msg.url}
+			m.running[msg.id] = row
+		}
+		row.attempt = msg.attempt
+		row.started = msg.at
+	case taskRetryMsg:
+		if row, ok := m.running[msg.id]; ok {
+			row.lastErr = msg.err
+		}
+	case taskDoneMsg:
+		row, ok := m.running[msg.id]
+		if !ok {
+			row = &taskRow{id: msg.id}
+		}
+		delete(m.running, msg.id)
+		row.records, row.duration, row.err = msg.records, msg.duration, msg.err
+		m.recent = appendLimited(m.recent, row, recentLimit)
+	}
+	return m, nil
+}
+
+func (m *model) View() string {
+	if m.quitting {
+		return ""
+	}
+	var b strings.Builder
+	s := m.stats
+	finished := s.Done + s.Failed
+	total := finished + s.Running + s.Queued
+
+	fmt.Fprintf(&b, "%s  %s  %s %d/%d\n", titleStyle.Render("ish3ikin"), s.Elapsed.Round(time.Second), bar(finished, total), finished, total)
+	throughput := 0.0
+	if s.Elapsed > 0 {
+		throughput = float64(finished) / s.Elapsed.Seconds()
+	}
+	fmt.Fprintf(&b, "%s  %s  running %d  queued %d  %s  %.2f tasks/s",
+		okStyle.Render(fmt.Sprintf("done %d", s.Done)), failStyle.Render(fmt.Sprintf("failed %d", s.Failed)),
+		s.Running, s.Queued, retryStyle.Render(fmt.Sprintf("retried %d", s.Retried)), throughput)
+	if s.ETA > 0 {
+		fmt.Fprintf(&b, "  eta %s", s.ETA.Round(time.Second))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(headerStyle.Render("RUNNING") + "\n")
+	rows := make([]*taskRow, 0, len(m.running))
+	for _, row := range m.running {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].started.Before(rows[j].started) })
+	for _, row := range rows {
+		line := fmt.Sprintf("  %-6s %s  %s  %s", row.id, row.name, dimStyle.Render(row.url), time.Since(row.started).Round(time.Second))
+		if row.attempt > 1 {
+			line += retryStyle.Render(fmt.Sprintf("  attempt %d", row.attempt))
+			if row.lastErr != nil {
+				line += dimStyle.Render(": " + row.lastErr.Error())
+			}
+		}
+		b.WriteString(m.fit(line) + "\n")
+	}
+	if len(rows) == 0 {
+		b.WriteString(dimStyle.Render("  -") + "\n")
+	}
+
+	b.WriteString("\n" + headerStyle.Render("RECENT") + "\n")
+	for i := len(m.recent) - 1; i >= 0; i-- {
+		row := m.recent[i]
+		var line string
+		if row.err != nil {
+			line = failStyle.Render("  ✗ ") + fmt.Sprintf("%-6s %s  %s", row.id, row.name, failStyle.Render(row.err.Error()))
+		} else {
+			line = okStyle.Render("  ✓ ") + fmt.Sprintf("%-6s %s  %d records  %s", row.id, row.name, row.records, row.duration.Round(time.Millisecond))
+		}
+		b.WriteString(m.fit(line) + "\n")
+	}
+	if len(m.recent) == 0 {
+		b.WriteString(dimStyle.Render("  -") + "\n")
+	}
+
+	b.WriteString("\n" + headerStyle.Render("LOG") + "\n")
+	for _, line := range m.logs {
+		b.WriteString(m.fit(dimStyle.Render("  "+line)) + "\n")
+	}
+	b.WriteString("\n" + dimStyle.Render("q: stop the run") + "\n")
+	return b.String()
+}
+
+// fit обрезает строку по ширине терминала.
+func (m *model) fit(line string) string {
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+}
+
+func bar(done, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * barWidth / total
+	}
+	return barFullStyle.Render(strings.Repeat("█", filled)) + dimStyle.Render(strings.Repeat("░", barWidth-filled))
+}
+
+func appendLimited[T any](list []T, item T, limit int) []T {
+	list = append(list, item)
+	if len(list) > limit {
+		list = list[len(list)-limit:]
+	}
+	return list
+}
@@ -0,0 +1,120 @@
+// Package tui - живая панель прогресса запуска в терминале.
+package tui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Stats - счетчики пула, которые панель опрашивает раз в refreshInterval, см. Watch.
+type Stats struct {
+	Queued  int
+	Running int
+	Done    int
+	Failed  int
+	Retried int
+	Elapsed time.Duration
+	ETA     time.Duration
+}
+
+// Dashboard показывает статус задач, глубину очереди, счетчики
+// и пропускную способность во время запуска. События задач передаются
+// методами Task*, сообщения лога - через Writer.
+type Dashboard struct {
+	program *tea.Program
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New создает панель. cancel вызывается, когда пользователь выходит
+// из панели, и должен остановить запуск.
+func New(cancel func()) *Dashboard {
+	return &Dashboard{
+		program: tea.NewProgram(newModel(cancel)),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start запускает панель. Пока панель работает, методы Task* и Writer
+// передают ей события; после Stop они ничего не делают.
+func (d *Dashboard) Start() {
+	go func() {
+		defer close(d.done)
+		_, _ = d.program.Run()
+	}()
+}
+
+// Watch опрашивает stats раз в refreshInterval, пока панель не закрыта.
+func (d *Dashboard) Watch(stats func() Stats) {
+	d.program.Send(statsMsg(stats()))
+	go func() {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.done:
+				return
+			case <-ticker.C:
+				d.program.Send(statsMsg(stats()))
+			}
+		}
+	}()
+}
+
+// Stop закрывает панель и возвращает терминал в обычный режим.
+func (d *Dashboard) Stop() {
+	d.once.Do(func() {
+		d.program.Quit()
+		<-d.done
+	})
+}
+
+// TaskStarted отмечает начало попытки задачи.
+func (d *Dashboard) TaskStarted(id, name, url string, attempt int) {
+	d.program.Send(taskStartMsg{id: id, name: name, url: url, attempt: attempt, at: time.Now()})
+}
+
+// TaskRetrying отмечает неудачную попытку, которая будет повторена.
+func (d *Dashboard) TaskRetrying(id string, attempt int, err error) {
+	d.program.Send(taskRetryMsg{id: id, attempt: attempt, err: err})
+}
+
+// TaskFinished отмечает окончательное завершение задачи.
+func (d *Dashboard) TaskFinished(id string, records int, duration time.Duration, err error) {
+	d.program.Send(taskDoneMsg{id: id, records: records, duration: duration, err: err})
+}
+
+// Writer возвращает io.Writer, строки которого попадают в панель лога.
+func (d *Dashboard) Writer() io.Writer {
+	return &logWriter{send: d.program.Send}
+}
+
+// logWriter разбивает вывод логгера на строки для панели.
+type logWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	send func(tea.Msg)
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Неполную строку оставляем до следующей записи
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			w.send(logMsg(line))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package workerpool
+
+import "sync"
+
+// Unique - необязательный интерфейс задачи для WithDedup. Задачи с одинаковым
+// UniqueKey считаются дубликатами. Пустой ключ не проверяется.
+type Unique interface {
+	UniqueKey() string
+}
+
+// Duplicate описывает задачу, отброшенную как дубликат уже добавленной.
+type Duplicate struct {
+	TaskID int
+	Name   string
+	Key    string
+	// FirstID - номер задачи, которая была добавлена первой и будет выполнена.
+	FirstID int
+}
+
+// WithDedup включает отбрасывание задач с уже встречавшимся UniqueKey.
+// Отброшенные задачи не выполняются и доступны через Duplicates.
+func WithDedup() Option {
+	return func(o *options) {
+		o.dedup = true
+	}
+}
+
+type dedupSet struct {
+	mu         sync.Mutex
+	seen       map[string]int
+	duplicates []Duplicate
+}
+
+// check запоминает ключ задачи и возвращает false, если он уже встречался.
+func (d *dedupSet) check(id int, name, key string) bool {
+	if key == "" {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.seen == nil {
+		d.seen = make(map[string]int)
+	}
+	if first, ok := d.seen[key]; ok {
+		d.duplicates = append(d.duplicates, Duplicate{TaskID: id, Name: name, Key: key, FirstID: first})
+		return false
+	}
+	d.seen[key] = id
+	return true
+}
+
+// Duplicates возвращает задачи, отброшенные как дубликаты.
+func (p *Pool[R]) Duplicates() []Duplicate {
+	p.dedupSet.mu.Lock()
+	defer p.dedupSet.mu.Unlock()
+	return append([]Duplicate(nil), p.dedupSet.duplicates...)
+}
@@ -0,0 +1,139 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Dependent - необязательный интерфейс задачи. Задача запускается только после
+// успешного завершения всех задач с перечисленными именами (Task.Name).
+// Если одна из них завершилась ошибкой, задача не выполняется и получает
+// ErrDependencyFailed.
+type Dependent interface {
+	DependsOn() []string
+}
+
+var (
+	// ErrDependencyFailed - зависимость задачи завершилась ошибкой.
+	ErrDependencyFailed = errors.New("dependency failed")
+	// ErrDependencyMissing - задачи, от которой зависит задача, нет в пуле.
+	ErrDependencyMissing = errors.New("dependency not found")
+)
+
+type waitingTask[R any] struct {
+	qt   queuedTask[R]
+	deps []string
+}
+
+// depTracker следит за завершением задач по именам и держит задачи,
+// зависимости которых еще не выполнены.
+type depTracker[R any] struct {
+	mu sync.Mutex
+	// pending - сколько задач с этим именем еще не завершено.
+	pending map[string]int
+	failed  map[string]bool
+	waiting []waitingTask[R]
+	closed  bool
+}
+
+func newDepTracker[R any]() *depTracker[R] {
+	return &depTracker[R]{
+		pending: make(map[string]int),
+		failed:  make(map[string]bool),
+	}
+}
+
+// add регистрирует задачу. ready равно true, если задачу можно ставить
+// в очередь сразу; ее err заполнен, если зависимости уже не выполнимы.
+func (d *depTracker[R]) add(qt queuedTask[R]) (queuedTask[R], bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	name := qt.task.Name()
+	d.pending[name]++
+
+	deps := dependenciesOf(qt.task)
+	for _, dep := range deps {
+		if dep == name {
+			qt.err = fmt.Errorf("%w: task depends on itself", ErrDependencyFailed)
+			return qt, true
+		}
+	}
+
+	ready, err := d.resolve(deps, false)
+	if !ready {
+		d.waiting = append(d.waiting, waitingTask[R]{qt: qt, deps: deps})
+		return qt, false
+	}
+	qt.err = err
+	return qt, true
+}
+
+// complete отмечает завершение задачи и возвращает задачи, которые
+// теперь можно поставить в очередь.
+func (d *depTracker[R]) complete(name string, err error) []queuedTask[R] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending[name]--
+	if err != nil {
+		d.failed[name] = true
+	}
+	return d.release()
+}
+
+// close вызывается, когда новых задач больше не будет: зависимости
+// от неизвестных имен уже не выполнятся.
+func (d *depTracker[R]) close() []queuedTask[R] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return d.release()
+}
+
+// release забирает из ожидания задачи, чьи зависимости разрешились. Вызывается под mu.
+func (d *depTracker[R]) release() []queuedTask[R] {
+	var ready []queuedTask[R]
+	waiting := d.waiting[:0]
+	for _, w := range d.waiting {
+		ok, err := d.resolve(w.deps, d.closed)
+		if !ok {
+			waiting = append(waiting, w)
+			continue
+		}
+		w.qt.err = err
+		ready = append(ready, w.qt)
+	}
+	d.waiting = waiting
+	return ready
+}
+
+// resolve проверяет зависимости. ready равно false, пока хотя бы одна
+// из них не завершена; err заполнен, если задачу выполнять нельзя.
+func (d *depTracker[R]) resolve(deps []string, closed bool) (ready bool, err error) {
+	for _, dep := range deps {
+		if d.failed[dep] {
+			return true, fmt.Errorf("%w: %s", ErrDependencyFailed, dep)
+		}
+	}
+	for _, dep := range deps {
+		pending, known := d.pending[dep]
+		switch {
+		case known && pending > 0:
+			return false, nil
+		case !known && !closed:
+			return false, nil
+		case !known:
+			return true, fmt.Errorf("%w: %s", ErrDependencyMissing, dep)
+		}
+	}
+	return true, nil
+}
+
+func dependenciesOf[R any](t Task[R]) []string {
+	if d, ok := t.(Dependent); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
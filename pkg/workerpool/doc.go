@@ -0,0 +1,32 @@
+// Package workerpool - пул воркеров с типизированными результатами.
+//
+// Задачи реализуют Task[R] и добавляются через AddTask или TryAddTask.
+// Run запускает воркеров и блокируется, пока очередь не закрыта через Close
+// и не разобрана, либо пока не отменен контекст. Результаты приходят
+// в канал Results, который закрывается после завершения Run.
+//
+//	pool, err := workerpool.NewPool[string](4, 100, workerpool.WithRetries(2))
+//	if err != nil {
+//		return err
+//	}
+//	go func() {
+//		for _, t := range tasks {
+//			pool.AddTask(ctx, t)
+//		}
+//		pool.Close()
+//	}()
+//	go pool.Run(ctx)
+//	for res := range pool.Results() {
+//		// res.Value, res.Err, res.Attempts ...
+//	}
+//
+// Поведение настраивается опциями: WithTaskTimeout, WithRetries, WithBackoff,
+// WithScaling, WithRateLimit, WithKeyLimit, WithDedup, WithUnboundedQueue.
+// Задачи могут дополнительно реализовать Prioritized, Keyed, Unique
+// и Dependent, чтобы управлять порядком запуска, Timed и Retrying - чтобы
+// переопределить лимит времени и число повторов, а Starter и Completer -
+// чтобы узнавать о начале и успешном завершении.
+//
+// Состояние пула доступно через Progress, Metrics, Failures, Duplicates
+// и Abandoned; Pause, Resume и Shutdown управляют работой на ходу.
+package workerpool
@@ -0,0 +1,69 @@
+package workerpool
+
+import "time"
+
+// TaskEvent описывает событие жизненного цикла задачи для Hooks.
+type TaskEvent struct {
+	TaskID  int
+	Name    string
+	Worker  int
+	Attempt int
+	// Duration и Err заполнены для событий завершения попытки.
+	Duration time.Duration
+	Err      error
+}
+
+// Hooks - обработчики событий пула, например для логирования. Любой
+// обработчик может быть nil. Обработчики вызываются из горутин воркеров
+// и не должны надолго блокироваться.
+type Hooks struct {
+	OnWorkerStart func(worker int)
+	OnWorkerStop  func(worker int)
+	OnTaskStart   func(TaskEvent)
+	// OnTaskRetry вызывается после неудачной попытки, которая будет повторена.
+	OnTaskRetry func(TaskEvent)
+	OnTaskDone  func(TaskEvent)
+	// OnTaskError вызывается, когда задача окончательно завершилась ошибкой.
+	OnTaskError func(TaskEvent)
+}
+
+// WithHooks подключает обработчики событий пула.
+func WithHooks(h Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+func (h Hooks) workerStart(worker int) {
+	if h.OnWorkerStart != nil {
+		h.OnWorkerStart(worker)
+	}
+}
+
+func (h Hooks) workerStop(worker int) {
+	if h.OnWorkerStop != nil {
+		h.OnWorkerStop(worker)
+	}
+}
+
+func (h Hooks) taskStart(e TaskEvent) {
+	if h.OnTaskStart != nil {
+		h.OnTaskStart(e)
+	}
+}
+
+// taskFinished вызывает обработчик, соответствующий исходу попытки.
+func (h Hooks) taskFinished(e TaskEvent, retried bool) {
+	var hook func(TaskEvent)
+	switch {
+	case retried:
+		hook = h.OnTaskRetry
+	case e.Err != nil:
+		hook = h.OnTaskError
+	default:
+		hook = h.OnTaskDone
+	}
+	if hook != nil {
+		hook(e)
+	}
+}
@@ -0,0 +1,97 @@
+package workerpool
+
+import "sync"
+
+// Keyed - необязательный интерфейс задачи. Задачи с одинаковым ключом
+// (например, хостом сайта) ограничиваются WithKeyLimit. Пустой ключ
+// ограничению не подлежит.
+type Keyed interface {
+	Key() string
+}
+
+func keyOf[R any](t Task[R]) string {
+	if k, ok := t.(Keyed); ok {
+		return k.Key()
+	}
+	return ""
+}
+
+// WithKeyLimit запрещает выполнять одновременно больше limit задач с одним
+// ключом. Лишние задачи откладываются и запускаются, когда освобождается
+// место, не занимая воркеров в ожидании.
+func WithKeyLimit(limit int) Option {
+	return func(o *options) {
+		o.keyLimit = limit
+	}
+}
+
+// keyLimiter считает выполняемые задачи по ключам и хранит отложенные.
+type keyLimiter[R any] struct {
+	mu      sync.Mutex
+	limit   int
+	running map[string]int
+	parked  map[string][]queuedTask[R]
+}
+
+func newKeyLimiter[R any](limit int) *keyLimiter[R] {
+	return &keyLimiter[R]{
+		limit:   limit,
+		running: make(map[string]int),
+		parked:  make(map[string][]queuedTask[R]),
+	}
+}
+
+// acquire занимает место для задачи. Если мест нет, задача откладывается
+// и acquire возвращает false.
+func (l *keyLimiter[R]) acquire(qt queuedTask[R]) bool {
+	key := keyOf(qt.task)
+	if l == nil || key == "" {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running[key] >= l.limit {
+		l.parked[key] = append(l.parked[key], qt)
+		return false
+	}
+	l.running[key]++
+	return true
+}
+
+// release освобождает место задачи. Если по тому же ключу есть отложенная
+// задача, место сразу передается ей, и она возвращается для выполнения.
+func (l *keyLimiter[R]) release(qt queuedTask[R]) (queuedTask[R], bool) {
+	key := keyOf(qt.task)
+	if l == nil || key == "" {
+		return queuedTask[R]{}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if parked := l.parked[key]; len(parked) > 0 {
+		next := parked[0]
+		if len(parked) == 1 {
+			delete(l.parked, key)
+		} else {
+			l.parked[key] = parked[1:]
+		}
+		return next, true
+	}
+
+	l.running[key]--
+	if l.running[key] == 0 {
+		delete(l.running, key)
+	}
+	return queuedTask[R]{}, false
+}
+
+// drop выбрасывает отложенные задачи.
+func (l *keyLimiter[R]) drop() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.parked = make(map[string][]queuedTask[R])
+}
@@ -0,0 +1,15 @@
+package workerpool
+
+// Starter - задача, которую нужно уведомлять о начале каждой попытки.
+// attempt начинается с 1.
+type Starter interface {
+	OnStart(attempt int)
+}
+
+// Completer - задача, которую нужно уведомлять об успешном завершении.
+// OnComplete вызывается один раз, до отправки результата в Results.
+// Неудачные попытки, которые будут повторены, не вызывают ни OnComplete,
+// ни Task.OnError.
+type Completer[R any] interface {
+	OnComplete(value R)
+}
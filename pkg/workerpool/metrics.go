@@ -0,0 +1,160 @@
+package workerpool
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets - верхние границы корзин гистограмм времени.
+var DefaultBuckets = []time.Duration{
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+}
+
+// Histogram - распределение длительностей. Counts[i] - число наблюдений
+// не больше Bounds[i], последний элемент Counts - наблюдения больше всех границ.
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+func newHistogram(bounds []time.Duration) Histogram {
+	return Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(h.Bounds) && d > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+func (h Histogram) clone() Histogram {
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return h
+}
+
+// Mean возвращает среднее значение или 0, если наблюдений не было.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Metrics - счетчики и гистограммы пула с момента создания.
+type Metrics struct {
+	// Started считает каждую попытку выполнения, включая повторы.
+	Started   uint64
+	Succeeded uint64
+	Failed    uint64
+	Retried   uint64
+	// QueueWait - время от постановки в очередь до начала выполнения.
+	QueueWait Histogram
+	// Execution - время выполнения одной попытки.
+	Execution Histogram
+}
+
+type metricsCollector struct {
+	mu sync.Mutex
+	m  Metrics
+}
+
+func newMetricsCollector() *metricsCollector {
+	return &metricsCollector{m: Metrics{
+		QueueWait: newHistogram(DefaultBuckets),
+		Execution: newHistogram(DefaultBuckets),
+	}}
+}
+
+func (c *metricsCollector) started(wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.Started++
+	c.m.QueueWait.observe(wait)
+}
+
+// finished учитывает завершение попытки. retried - попытка будет повторена,
+// ran - задача действительно выполнялась, а не была отклонена из-за зависимостей.
+func (c *metricsCollector) finished(d time.Duration, err error, retried, ran bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ran {
+		c.m.Execution.observe(d)
+	}
+	switch {
+	case retried:
+		c.m.Retried++
+	case err != nil:
+		c.m.Failed++
+	default:
+		c.m.Succeeded++
+	}
+}
+
+// Metrics возвращает снимок метрик пула.
+func (p *Pool[R]) Metrics() Metrics {
+	p.metrics.mu.Lock()
+	defer p.metrics.mu.Unlock()
+	m := p.metrics.m
+	m.QueueWait = m.QueueWait.clone()
+	m.Execution = m.Execution.clone()
+	return m
+}
+
+// WritePrometheus записывает метрики в текстовом формате Prometheus,
+// например для textfile collector у node_exporter. namespace - префикс имен.
+func (m Metrics) WritePrometheus(w io.Writer, namespace string) error {
+	counters := []struct {
+		name, help string
+		value      uint64
+	}{
+		{"tasks_started_total", "Task execution attempts started.", m.Started},
+		{"tasks_succeeded_total", "Tasks completed successfully.", m.Succeeded},
+		{"tasks_failed_total", "Tasks failed after all attempts.", m.Failed},
+		{"tasks_retried_total", "Task attempts that were retried.", m.Retried},
+	}
+	for _, c := range counters {
+		name := namespace + "_" + c.name
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, c.help, name, name, c.value); err != nil {
+			return err
+		}
+	}
+
+	if err := m.QueueWait.writePrometheus(w, namespace+"_queue_wait_seconds", "Time tasks spent in the queue."); err != nil {
+		return err
+	}
+	return m.Execution.writePrometheus(w, namespace+"_execution_seconds", "Task execution time per attempt.")
+}
+
+func (h Histogram) writePrometheus(w io.Writer, name, help string) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name); err != nil {
+		return err
+	}
+
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", name, bound.Seconds(), cumulative); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %g\n%s_count %d\n",
+		name, h.Count, name, h.Sum.Seconds(), name, h.Count)
+	return err
+}
@@ -0,0 +1,35 @@
+package workerpool
+
+import "time"
+
+// Timed - задача со своим лимитом времени на попытку вместо WithTaskTimeout.
+// Нулевое значение означает лимит пула.
+type Timed interface {
+	Timeout() time.Duration
+}
+
+// Retrying - задача со своим числом повторов вместо WithRetries.
+// Отрицательное значение означает значение пула.
+type Retrying interface {
+	MaxRetries() int
+}
+
+// timeoutFor возвращает лимит времени попытки задачи.
+func (p *Pool[R]) timeoutFor(task Task[R]) time.Duration {
+	if t, ok := task.(Timed); ok {
+		if d := t.Timeout(); d > 0 {
+			return d
+		}
+	}
+	return p.taskTimeout
+}
+
+// retriesFor возвращает число повторов задачи.
+func (p *Pool[R]) retriesFor(task Task[R]) int {
+	if r, ok := task.(Retrying); ok {
+		if n := r.MaxRetries(); n >= 0 {
+			return n
+		}
+	}
+	return p.retries
+}
@@ -0,0 +1,17 @@
+package workerpool
+
+// Pause приостанавливает запуск новых задач. Выполняемые задачи доделываются,
+// очередь сохраняется, а задачи можно продолжать добавлять.
+func (p *Pool[R]) Pause() {
+	p.queue.setPaused(true)
+}
+
+// Resume возобновляет запуск задач после Pause.
+func (p *Pool[R]) Resume() {
+	p.queue.setPaused(false)
+}
+
+// Paused сообщает, стоит ли пул на паузе.
+func (p *Pool[R]) Paused() bool {
+	return p.queue.isPaused()
+}
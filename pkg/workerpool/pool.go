@@ -0,0 +1,641 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Task - задача, которую выполняет пул. Execute должен прекращать работу
+// при отмене переданного контекста.
+type Task[R any] interface {
+	// Name - человекочитаемое имя задачи для логов и результатов.
+	Name() string
+	Execute(ctx context.Context) (R, error)
+	// OnError вызывается, когда задача окончательно завершилась ошибкой.
+	// О начале и успешном завершении задача узнает, если реализует
+	// Starter и Completer.
+	OnError(error)
+}
+
+// Result - итог выполнения задачи. Для неудачных задач заполнено Err,
+// а Value содержит нулевое значение.
+type Result[R any] struct {
+	// TaskID - номер задачи, который вернул AddTask.
+	TaskID int
+	Name   string
+	Value  R
+	// Duration - время последней попытки, Attempts - число сделанных попыток.
+	Duration time.Duration
+	Attempts int
+	Err      error
+}
+
+// TaskError связывает ошибку с задачей, в которой она произошла.
+type TaskError struct {
+	TaskID int
+	Name   string
+	Err    error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d (%s): %v", e.TaskID, e.Name, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError - ошибка задачи, которая завершилась паникой.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v\n%s", e.Value, e.Stack)
+}
+
+var (
+	// ErrTaskTimeout возвращается задаче, которая не уложилась в WithTaskTimeout.
+	ErrTaskTimeout = errors.New("task execution timed out")
+	// ErrQueueFull возвращает TryAddTask, если в очереди нет места.
+	ErrQueueFull = errors.New("task queue is full")
+	// ErrPoolClosed возвращается при добавлении задачи после Close.
+	ErrPoolClosed = errors.New("pool is closed")
+)
+
+type queuedTask[R any] struct {
+	id      int
+	task    Task[R]
+	attempt int
+	// enqueued - время постановки в очередь, для метрики ожидания.
+	enqueued time.Time
+	// err заполнен, если задачу нельзя выполнять из-за зависимостей.
+	// Такая задача сразу завершается с этой ошибкой.
+	err error
+}
+
+type Pool[R any] struct {
+	queue   *taskQueue[R]
+	results chan Result[R]
+	done    chan struct{}
+	nextID  atomic.Int64
+	options
+
+	// inflight хранит имена задач в очереди, в работе и ожидающих повтора.
+	// Очередь закрывается, только когда после Close их не осталось.
+	mu       sync.Mutex
+	inflight map[int]string
+	closing  bool
+
+	keys     *keyLimiter[R]
+	dedupSet dedupSet
+	deps     *depTracker[R]
+	metrics  *metricsCollector
+
+	// Счетчики для Progress.
+	started   time.Time
+	running   atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	busyTime  atomic.Int64
+
+	failuresMu sync.Mutex
+	failures   []*TaskError
+
+	// Состояние воркеров: workers - запущено, idle - ждут задачу.
+	scaleMu    sync.Mutex
+	wg         sync.WaitGroup
+	runCtx     context.Context
+	cancelRun  context.CancelFunc
+	workers    int
+	idle       int
+	nextWorker int
+}
+
+// options содержит настройки, общие для пулов с любым типом результата.
+type options struct {
+	taskTimeout time.Duration
+	retries     int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	minWorkers  int
+	maxWorkers  int
+	idleTimeout time.Duration
+	limiter     *rate.Limiter
+	keyLimit    int
+	dedup       bool
+	unbounded   bool
+	hooks       Hooks
+	fair        bool
+}
+
+// Option настраивает пул при создании.
+type Option func(*options)
+
+// WithTaskTimeout ограничивает время выполнения каждой задачи. Зависшая задача
+// освобождает воркера по истечении лимита и завершается с ErrTaskTimeout.
+func WithTaskTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.taskTimeout = d
+	}
+}
+
+// WithRetries повторяет неудачную задачу до n раз, прежде чем сообщить
+// об ошибке. Отмена контекста и паники не повторяются.
+func WithRetries(n int) Option {
+	return func(o *options) {
+		o.retries = n
+	}
+}
+
+// WithBackoff задает экспоненциальную задержку между повторами: base перед
+// первым повтором, затем вдвое больше, но не дольше max. К задержке
+// добавляется случайный разброс, чтобы повторы не приходили пачкой.
+func WithBackoff(base, max time.Duration) Option {
+	return func(o *options) {
+		o.backoffBase = base
+		o.backoffMax = max
+	}
+}
+
+// WithScaling включает автомасштабирование: пул держит не меньше min воркеров,
+// добавляет новых, пока в очереди больше задач, чем свободных воркеров,
+// и останавливает лишних после простоя. Заменяет numWorkers из NewPool.
+func WithScaling(min, max int) Option {
+	return func(o *options) {
+		o.minWorkers = min
+		o.maxWorkers = max
+	}
+}
+
+// WithRateLimit ограничивает число задач, запускаемых в секунду, независимо
+// от числа воркеров. burst - сколько задач можно запустить разом после простоя.
+// Повторы задач тоже расходуют лимит.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(o *options) {
+		if perSecond <= 0 {
+			o.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		o.limiter = rate.NewLimiter(rate.Limit(perSecond), burst)
+	}
+}
+
+// WithUnboundedQueue снимает ограничение на размер очереди: AddTask никогда
+// не ждет места, а taskChannelSize из NewPool не используется.
+func WithUnboundedQueue() Option {
+	return func(o *options) {
+		o.unbounded = true
+	}
+}
+
+// Создает новый пул воркеров с заданными параметрами
+func NewPool[R any](numWorkers int, taskChannelSize int, opts ...Option) (*Pool[R], error) {
+	p := &Pool[R]{
+		deps:     newDepTracker[R](),
+		inflight: make(map[int]string),
+		metrics:  newMetricsCollector(),
+		results:  make(chan Result[R]),
+		done:     make(chan struct{}),
+		options: options{
+			backoffBase: time.Second,
+			backoffMax:  30 * time.Second,
+			minWorkers:  numWorkers,
+			maxWorkers:  numWorkers,
+			idleTimeout: 10 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(&p.options)
+	}
+
+	if p.unbounded {
+		taskChannelSize = 0
+	} else if taskChannelSize <= 0 {
+		return nil, errors.New("Invalid parameters: number of workers and tasks must be more than zero")
+	}
+	if p.minWorkers <= 0 || p.maxWorkers < p.minWorkers {
+		// Без WithScaling обе границы равны numWorkers
+		if p.minWorkers == numWorkers && p.maxWorkers == numWorkers {
+			return nil, errors.New("Invalid parameters: number of workers and tasks must be more than zero")
+		}
+		return nil, fmt.Errorf("invalid scaling: min %d must be > 0 and max %d >= min", p.minWorkers, p.maxWorkers)
+	}
+	p.queue = newTaskQueue[R](taskChannelSize, p.fair)
+	if p.keyLimit > 0 {
+		p.keys = newKeyLimiter[R](p.keyLimit)
+	}
+	return p, nil
+}
+
+// Функция для получения канала результатов. Канал закрывается, когда Run завершается.
+func (p *Pool[R]) Results() <-chan Result[R] {
+	return p.results
+}
+
+// AddTask ставит задачу в очередь и возвращает ее номер, который придет
+// в Result.TaskID. Если очередь заполнена, ждет места или отмены ctx.
+// Задачи, реализующие Prioritized, выдаются воркерам в порядке приоритета.
+// С WithDedup дубликаты не ставятся в очередь.
+func (p *Pool[R]) AddTask(ctx context.Context, t Task[R]) (int, error) {
+	return p.add(ctx, t, true)
+}
+
+// TryAddTask ставит задачу в очередь без ожидания. Если места нет,
+// возвращает ErrQueueFull.
+func (p *Pool[R]) TryAddTask(t Task[R]) (int, error) {
+	return p.add(context.Background(), t, false)
+}
+
+func (p *Pool[R]) add(ctx context.Context, t Task[R], wait bool) (int, error) {
+	p.mu.Lock()
+	if p.closing {
+		p.mu.Unlock()
+		return 0, ErrPoolClosed
+	}
+	id := int(p.nextID.Add(1))
+	p.inflight[id] = t.Name()
+	p.mu.Unlock()
+
+	if p.dedup {
+		if u, ok := t.(Unique); ok && !p.dedupSet.check(id, t.Name(), u.UniqueKey()) {
+			p.finish(id)
+			return id, nil
+		}
+	}
+
+	// Задачи с невыполненными зависимостями ждут вне очереди.
+	qt, ready := p.deps.add(queuedTask[R]{id: id, task: t, attempt: 1})
+	if !ready {
+		return id, nil
+	}
+	if err := p.queue.push(ctx, qt, wait); err != nil {
+		// Задача не попадет в пул: зависящие от нее задачи не дождутся ее.
+		for _, next := range p.deps.complete(t.Name(), err) {
+			go p.enqueue(next)
+		}
+		p.finish(id)
+		return 0, err
+	}
+	p.grow()
+	return id, nil
+}
+
+// enqueue ставит в очередь задачу, которая уже учтена в пуле. Очередь
+// закрыта, только если Run уже завершился, - тогда задача считается брошенной.
+func (p *Pool[R]) enqueue(qt queuedTask[R]) {
+	if err := p.queue.push(context.Background(), qt, true); err != nil {
+		return
+	}
+	p.grow()
+}
+
+// Close сообщает пулу, что новых задач не будет. Run завершится,
+// когда воркеры разберут очередь.
+func (p *Pool[R]) Close() {
+	p.mu.Lock()
+	p.closing = true
+	if len(p.inflight) == 0 {
+		p.queue.close()
+	}
+	p.mu.Unlock()
+
+	// Ждущие неизвестных задач больше не дождутся и завершатся с ошибкой.
+	for _, qt := range p.deps.close() {
+		go p.enqueue(qt)
+	}
+}
+
+// finish отмечает задачу завершенной и закрывает очередь, если она была последней.
+func (p *Pool[R]) finish(id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inflight, id)
+	if p.closing && len(p.inflight) == 0 {
+		p.queue.close()
+	}
+}
+
+// retry возвращает задачу в очередь после задержки.
+func (p *Pool[R]) retry(qt queuedTask[R]) {
+	time.AfterFunc(p.backoff(qt.attempt), func() {
+		qt.attempt++
+		p.enqueue(qt)
+	})
+}
+
+// backoff возвращает задержку перед повтором после attempt-й попытки.
+func (p *Pool[R]) backoff(attempt int) time.Duration {
+	d := p.backoffBase
+	for i := 1; i < attempt && d < p.backoffMax; i++ {
+		d *= 2
+	}
+	if p.backoffMax > 0 && d > p.backoffMax {
+		d = p.backoffMax
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Половина задержки фиксирована, вторая половина случайна.
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryable сообщает, имеет ли смысл повторять задачу после ошибки.
+func (p *Pool[R]) retryable(ctx context.Context, qt queuedTask[R], err error) bool {
+	if qt.attempt > p.retriesFor(qt.task) || ctx.Err() != nil {
+		return false
+	}
+	var panicErr *PanicError
+	return !errors.As(err, &panicErr)
+}
+
+// Run запускает воркеров и блокируется до тех пор, пока очередь не будет
+// закрыта и разобрана, либо пока не отменят ctx. При отмене выполняемые
+// задачи получают отмененный контекст, а задачи из очереди не запускаются.
+func (p *Pool[R]) Run(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p.scaleMu.Lock()
+	p.started = time.Now()
+	p.runCtx = runCtx
+	p.cancelRun = cancel
+	for i := 0; i < p.minWorkers; i++ {
+		p.spawn()
+	}
+	// Задачи могли накопиться в очереди еще до запуска.
+	for p.workers < p.maxWorkers && p.queue.len() > p.workers {
+		p.spawn()
+	}
+	p.scaleMu.Unlock()
+
+	p.wg.Wait()
+	p.queue.close()
+	close(p.done)
+	close(p.results)
+	return runCtx.Err()
+}
+
+// spawn запускает нового воркера. Вызывается под scaleMu.
+func (p *Pool[R]) spawn() {
+	p.workers++
+	p.nextWorker++
+	p.wg.Add(1) // Увеличиваем счетчик ожидания
+	go func(workerNum int) {
+		defer p.wg.Done() // Уменьшаем счетчик при завершении воркера
+		if !p.worker(p.runCtx, workerNum) {
+			p.scaleMu.Lock()
+			p.workers--
+			p.scaleMu.Unlock()
+		}
+	}(p.nextWorker - 1)
+}
+
+// grow добавляет воркера, если задач в очереди больше, чем свободных воркеров.
+func (p *Pool[R]) grow() {
+	p.scaleMu.Lock()
+	defer p.scaleMu.Unlock()
+	// workers == 0 означает, что Run еще не запущен или уже завершается.
+	if p.workers == 0 || p.workers >= p.maxWorkers {
+		return
+	}
+	if p.queue.len() > p.idle {
+		p.spawn()
+	}
+}
+
+// next ждет следующую задачу. retired равно true, если воркер простаивал
+// дольше idleTimeout и был остановлен как лишний.
+func (p *Pool[R]) next(ctx context.Context) (qt queuedTask[R], ok, retired bool) {
+	for {
+		popCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.minWorkers < p.maxWorkers {
+			popCtx, cancel = context.WithTimeout(ctx, p.idleTimeout)
+		}
+
+		p.scaleMu.Lock()
+		p.idle++
+		p.scaleMu.Unlock()
+
+		qt, ok = p.queue.pop(popCtx)
+		idleOut := popCtx.Err() != nil
+		cancel()
+
+		p.scaleMu.Lock()
+		p.idle--
+		if ok || ctx.Err() != nil || !idleOut {
+			p.scaleMu.Unlock()
+			return qt, ok, false
+		}
+		if p.workers > p.minWorkers {
+			p.workers--
+			p.scaleMu.Unlock()
+			return qt, false, true
+		}
+		p.scaleMu.Unlock()
+	}
+}
+
+// worker выполняет задачи, пока они есть. Возвращает true, если воркер
+// был остановлен при уменьшении пула.
+func (p *Pool[R]) worker(ctx context.Context, workerNum int) bool {
+	p.hooks.workerStart(workerNum)
+	defer p.hooks.workerStop(workerNum)
+
+	for {
+		qt, ok, retired := p.next(ctx)
+		if !ok {
+			return retired
+		}
+		if !p.keys.acquire(qt) {
+			continue
+		}
+
+		// Освободившееся по ключу место сразу занимает отложенная задача.
+		for ok {
+			if !p.process(ctx, qt, workerNum) {
+				return false
+			}
+			qt, ok = p.keys.release(qt)
+		}
+	}
+}
+
+// process выполняет задачу и отправляет результат. Возвращает false,
+// если пул остановлен отменой контекста.
+func (p *Pool[R]) process(ctx context.Context, qt queuedTask[R], workerNum int) bool {
+	var (
+		value R
+		err   = qt.err
+		start = time.Now()
+	)
+	event := TaskEvent{TaskID: qt.id, Name: qt.task.Name(), Worker: workerNum, Attempt: qt.attempt}
+	if err == nil {
+		if p.limiter != nil {
+			if err := p.limiter.Wait(ctx); err != nil {
+				return false
+			}
+		}
+
+		p.running.Add(1)
+		start = time.Now()
+		p.metrics.started(start.Sub(qt.enqueued))
+		p.hooks.taskStart(event)
+		if s, ok := qt.task.(Starter); ok {
+			err = protect(func() { s.OnStart(qt.attempt) })
+		}
+		if err == nil {
+			value, err = p.execute(ctx, qt.task)
+		}
+		// Паника в OnComplete, как и в Execute, - ошибка задачи
+		if c, ok := qt.task.(Completer[R]); ok && err == nil {
+			if err = protect(func() { c.OnComplete(value) }); err != nil {
+				var zero R
+				value = zero
+			}
+		}
+		p.running.Add(-1)
+		p.busyTime.Add(int64(time.Since(start)))
+	}
+
+	res := Result[R]{
+		TaskID:   qt.id,
+		Name:     event.Name,
+		Value:    value,
+		Duration: time.Since(start),
+		Attempts: qt.attempt,
+		Err:      err,
+	}
+	// Задача, прерванная остановкой пула, остается в списке брошенных.
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+
+	retried := err != nil && qt.err == nil && p.retryable(ctx, qt, err)
+	p.metrics.finished(res.Duration, err, retried, qt.err == nil)
+	event.Duration, event.Err = res.Duration, err
+	p.hooks.taskFinished(event, retried)
+	if retried {
+		p.retry(qt)
+		return true
+	}
+	// Зависимые задачи ставятся в очередь до finish, чтобы очередь
+	// не закрылась раньше времени.
+	for _, next := range p.deps.complete(res.Name, err) {
+		go p.enqueue(next)
+	}
+	p.finish(qt.id)
+	if err == nil {
+		p.succeeded.Add(1)
+	} else {
+		p.failed.Add(1)
+		if hookErr := protect(func() { qt.task.OnError(err) }); hookErr != nil {
+			err = errors.Join(err, hookErr)
+			res.Err = err
+		}
+		p.recordFailure(&TaskError{TaskID: qt.id, Name: res.Name, Err: err})
+	}
+
+	select {
+	case p.results <- res:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *Pool[R]) recordFailure(err *TaskError) {
+	p.failuresMu.Lock()
+	defer p.failuresMu.Unlock()
+	p.failures = append(p.failures, err)
+}
+
+// Failures возвращает ошибки всех неудачных задач в порядке их завершения.
+// Полный список доступен после того, как Run вернул управление.
+func (p *Pool[R]) Failures() []*TaskError {
+	p.failuresMu.Lock()
+	defer p.failuresMu.Unlock()
+	return append([]*TaskError(nil), p.failures...)
+}
+
+// Err объединяет ошибки всех неудачных задач через errors.Join.
+// Возвращает nil, если все задачи выполнены успешно.
+func (p *Pool[R]) Err() error {
+	failures := p.Failures()
+	errs := make([]error, len(failures))
+	for i, f := range failures {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
+
+// execute выполняет задачу с учетом лимита времени. Если задача не реагирует
+// на отмену контекста, воркер все равно освобождается по истечении лимита.
+func (p *Pool[R]) execute(ctx context.Context, task Task[R]) (R, error) {
+	timeout := p.timeoutFor(task)
+	if timeout <= 0 {
+		return safeExecute(ctx, task)
+	}
+
+	taskCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		value R
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := safeExecute(taskCtx, task)
+		done <- outcome{value, err}
+	}()
+
+	var zero R
+	select {
+	case o := <-done:
+		if o.err != nil && errors.Is(taskCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return zero, fmt.Errorf("%w after %s: %v", ErrTaskTimeout, timeout, o.err)
+		}
+		return o.value, o.err
+	case <-taskCtx.Done():
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+		return zero, fmt.Errorf("%w after %s", ErrTaskTimeout, timeout)
+	}
+}
+
+// safeExecute превращает панику в задаче в PanicError, чтобы она не роняла
+// процесс вместе с остальными воркерами.
+func safeExecute[R any](ctx context.Context, task Task[R]) (value R, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero R
+			value, err = zero, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return task.Execute(ctx)
+}
+
+// protect вызывает обработчик задачи и превращает его панику в PanicError.
+func protect(hook func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	hook()
+	return nil
+}
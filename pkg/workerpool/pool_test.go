@@ -0,0 +1,785 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTask - настраиваемая задача для тестов.
+type testTask struct {
+	name     string
+	priority int
+	key      string
+	deps     []string
+	delay    time.Duration
+	fails    int
+	panics   bool
+
+	calls atomic.Int32
+	// onStart и onStop вызываются в начале и в конце Execute.
+	onStart func()
+	onStop  func()
+}
+
+func (t *testTask) Name() string        { return t.name }
+func (t *testTask) Priority() int       { return t.priority }
+func (t *testTask) Key() string         { return t.key }
+func (t *testTask) UniqueKey() string   { return t.name }
+func (t *testTask) DependsOn() []string { return t.deps }
+func (t *testTask) OnError(error)       {}
+
+func (t *testTask) Execute(ctx context.Context) (string, error) {
+	n := int(t.calls.Add(1))
+	if t.onStart != nil {
+		t.onStart()
+	}
+	if t.onStop != nil {
+		defer t.onStop()
+	}
+	if t.panics {
+		panic("boom")
+	}
+
+	select {
+	case <-time.After(t.delay):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if n <= t.fails {
+		return "", errors.New("temporary failure")
+	}
+	return t.name, nil
+}
+
+func newPool(t *testing.T, workers, size int, opts ...Option) *Pool[string] {
+	t.Helper()
+	pool, err := NewPool[string](workers, size, opts...)
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	return pool
+}
+
+// run добавляет задачи, дожидается завершения пула и возвращает результаты по именам.
+func run(t *testing.T, pool *Pool[string], tasks ...Task[string]) map[string]Result[string] {
+	t.Helper()
+	for _, task := range tasks {
+		if _, err := pool.AddTask(context.Background(), task); err != nil {
+			t.Fatalf("AddTask(%s): %v", task.Name(), err)
+		}
+	}
+	pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- pool.Run(ctx) }()
+
+	results := make(map[string]Result[string])
+	for res := range pool.Results() {
+		results[res.Name] = res
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return results
+}
+
+func TestNewPoolInvalidParameters(t *testing.T) {
+	if _, err := NewPool[string](0, 1); err == nil {
+		t.Error("expected error for zero workers")
+	}
+	if _, err := NewPool[string](1, 0); err == nil {
+		t.Error("expected error for zero queue size")
+	}
+	for _, scaling := range [][2]int{{3, 2}, {0, 2}} {
+		_, err := NewPool[string](1, 1, WithScaling(scaling[0], scaling[1]))
+		if err == nil || !strings.Contains(err.Error(), "invalid scaling") {
+			t.Errorf("WithScaling(%d, %d): error = %v, want an invalid scaling error", scaling[0], scaling[1], err)
+		}
+	}
+	if _, err := NewPool[string](1, 0, WithUnboundedQueue()); err != nil {
+		t.Errorf("unbounded queue must not require a size: %v", err)
+	}
+}
+
+func TestRunReturnsResults(t *testing.T) {
+	pool := newPool(t, 3, 10)
+	results := run(t, pool, &testTask{name: "a"}, &testTask{name: "b"}, &testTask{name: "c"})
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for name, res := range results {
+		if res.Err != nil || res.Value != name || res.Attempts != 1 {
+			t.Errorf("%s: unexpected result %+v", name, res)
+		}
+	}
+	if err := pool.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+	if p := pool.Progress(); p.Done != 3 || p.Queued != 0 || p.Running != 0 {
+		t.Errorf("Progress() = %+v", p)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	pool := newPool(t, 1, 10, WithRetries(2), WithBackoff(time.Millisecond, 5*time.Millisecond))
+	results := run(t, pool,
+		&testTask{name: "recovers", fails: 2},
+		&testTask{name: "gives-up", fails: 5},
+	)
+
+	if res := results["recovers"]; res.Err != nil || res.Attempts != 3 {
+		t.Errorf("recovers: err=%v attempts=%d, want success after 3 attempts", res.Err, res.Attempts)
+	}
+	if res := results["gives-up"]; res.Err == nil || res.Attempts != 3 {
+		t.Errorf("gives-up: err=%v attempts=%d, want failure after 3 attempts", res.Err, res.Attempts)
+	}
+
+	failures := pool.Failures()
+	if len(failures) != 1 || failures[0].Name != "gives-up" {
+		t.Errorf("Failures() = %v, want only gives-up", failures)
+	}
+	if m := pool.Metrics(); m.Retried != 4 || m.Succeeded != 1 || m.Failed != 1 || m.Started != 6 {
+		t.Errorf("Metrics() = %+v", m)
+	}
+}
+
+func TestPanicBecomesError(t *testing.T) {
+	pool := newPool(t, 2, 10, WithRetries(3))
+	results := run(t, pool, &testTask{name: "panics", panics: true}, &testTask{name: "ok"})
+
+	var panicErr *PanicError
+	if res := results["panics"]; !errors.As(res.Err, &panicErr) || res.Attempts != 1 {
+		t.Errorf("panics: err=%v attempts=%d, want a single PanicError", res.Err, res.Attempts)
+	}
+	if res := results["ok"]; res.Err != nil {
+		t.Errorf("ok: %v", res.Err)
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	pool := newPool(t, 1, 10, WithTaskTimeout(10*time.Millisecond))
+	results := run(t, pool, &testTask{name: "slow", delay: time.Second})
+
+	if err := results["slow"].Err; !errors.Is(err, ErrTaskTimeout) {
+		t.Errorf("err = %v, want ErrTaskTimeout", err)
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	pool := newPool(t, 1, 10)
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+
+	run(t, pool,
+		&testTask{name: "low", priority: 1, onStart: record("low")},
+		&testTask{name: "high", priority: 10, onStart: record("high")},
+		&testTask{name: "default", onStart: record("default")},
+	)
+
+	want := []string{"high", "low", "default"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestKeyLimit(t *testing.T) {
+	pool := newPool(t, 6, 20, WithKeyLimit(2))
+	var running, peak atomic.Int32
+	start := func() {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				return
+			}
+		}
+	}
+	stop := func() { running.Add(-1) }
+
+	var tasks []Task[string]
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		tasks = append(tasks, &testTask{name: name, key: "example.com", delay: 5 * time.Millisecond, onStart: start, onStop: stop})
+	}
+
+	results := run(t, pool, tasks...)
+	if len(results) != len(tasks) {
+		t.Fatalf("got %d results, want %d", len(results), len(tasks))
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency per key = %d, want at most 2", p)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	pool := newPool(t, 2, 10, WithDedup())
+	first := &testTask{name: "same"}
+	second := &testTask{name: "same"}
+	run(t, pool, first, second)
+
+	if calls := first.calls.Load() + second.calls.Load(); calls != 1 {
+		t.Errorf("executed %d times, want 1", calls)
+	}
+	if d := pool.Duplicates(); len(d) != 1 || d[0].FirstID != 1 {
+		t.Errorf("Duplicates() = %+v", d)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	pool := newPool(t, 4, 10)
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+
+	results := run(t, pool,
+		&testTask{name: "details", deps: []string{"listing"}, onStart: record("details")},
+		&testTask{name: "listing", deps: []string{"login"}, onStart: record("listing")},
+		&testTask{name: "login", delay: 5 * time.Millisecond, onStart: record("login")},
+		&testTask{name: "broken", fails: 1},
+		&testTask{name: "after-broken", deps: []string{"broken"}},
+		&testTask{name: "orphan", deps: []string{"missing"}},
+	)
+
+	want := []string{"login", "listing", "details"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+	if err := results["after-broken"].Err; !errors.Is(err, ErrDependencyFailed) {
+		t.Errorf("after-broken: err = %v, want ErrDependencyFailed", err)
+	}
+	if err := results["orphan"].Err; !errors.Is(err, ErrDependencyMissing) {
+		t.Errorf("orphan: err = %v, want ErrDependencyMissing", err)
+	}
+}
+
+func TestTryAddTaskQueueFull(t *testing.T) {
+	pool := newPool(t, 1, 1)
+	if _, err := pool.TryAddTask(&testTask{name: "a"}); err != nil {
+		t.Fatalf("first TryAddTask: %v", err)
+	}
+	if _, err := pool.TryAddTask(&testTask{name: "b"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("err = %v, want ErrQueueFull", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.AddTask(ctx, &testTask{name: "c"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+
+	pool.Close()
+	if _, err := pool.AddTask(context.Background(), &testTask{name: "d"}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("err = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestShutdownReportsAbandoned(t *testing.T) {
+	pool := newPool(t, 1, 10)
+	for _, task := range []*testTask{{name: "fast"}, {name: "stuck", delay: time.Minute}, {name: "queued"}} {
+		if _, err := pool.AddTask(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	go pool.Run(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	abandoned, err := pool.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if len(abandoned) != 2 || abandoned[0].Name != "stuck" || abandoned[1].Name != "queued" {
+		t.Errorf("Abandoned = %+v, want stuck and queued", abandoned)
+	}
+}
+
+func TestStopSkipsQueued(t *testing.T) {
+	pool := newPool(t, 1, 10)
+	started := make(chan struct{})
+	slow := &testTask{name: "slow", delay: 50 * time.Millisecond, onStart: func() { close(started) }}
+	queued := &testTask{name: "queued"}
+	for _, task := range []*testTask{slow, queued, {name: "child", deps: []string{"queued"}}} {
+		if _, err := pool.AddTask(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	go pool.Run(context.Background())
+
+	results := make(chan []string, 1)
+	go func() {
+		var names []string
+		for res := range pool.Results() {
+			names = append(names, res.Name)
+		}
+		results <- names
+	}()
+
+	<-started
+	abandoned, err := pool.Stop(context.Background())
+	if err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if names := <-results; len(names) != 1 || names[0] != "slow" {
+		t.Errorf("results = %v, want only slow", names)
+	}
+	if len(abandoned) != 2 || abandoned[0].Name != "queued" || abandoned[1].Name != "child" {
+		t.Errorf("Abandoned = %+v, want queued and child", abandoned)
+	}
+	if queued.calls.Load() != 0 {
+		t.Error("queued task was executed after Stop")
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	pool := newPool(t, 1, 10)
+	pool.Pause()
+
+	task := &testTask{name: "a"}
+	if _, err := pool.AddTask(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+	pool.Close()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- pool.Run(context.Background()) }()
+
+	time.Sleep(20 * time.Millisecond)
+	if task.calls.Load() != 0 {
+		t.Fatal("task ran while the pool was paused")
+	}
+
+	pool.Resume()
+	for range pool.Results() {
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if task.calls.Load() != 1 {
+		t.Errorf("task ran %d times, want 1", task.calls.Load())
+	}
+}
+
+func TestScalingGrowsAndShrinks(t *testing.T) {
+	pool := newPool(t, 1, 50, WithScaling(1, 4))
+	pool.idleTimeout = 20 * time.Millisecond
+
+	var running, peak atomic.Int32
+	start := func() {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				return
+			}
+		}
+	}
+	stop := func() { running.Add(-1) }
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- pool.Run(context.Background()) }()
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		task := &testTask{name: "burst", delay: 10 * time.Millisecond, onStart: start, onStop: stop}
+		if _, err := pool.AddTask(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for (pool.Progress().Done < 20 || pool.Progress().Workers > 1) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if p := peak.Load(); p < 2 || p > 4 {
+		t.Errorf("peak concurrency = %d, want between 2 and 4", p)
+	}
+	if w := pool.Progress().Workers; w != 1 {
+		t.Errorf("workers after burst = %d, want 1", w)
+	}
+
+	pool.Close()
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestFairScheduling(t *testing.T) {
+	pool := newPool(t, 1, 20, WithFairScheduling())
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	var tasks []Task[string]
+	add := func(key string, n int) {
+		for i := 0; i < n; i++ {
+			tasks = append(tasks, &testTask{name: key + string(rune('0'+i)), key: key, onStart: func() {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, key)
+			}})
+		}
+	}
+	add("a", 6)
+	add("b", 2)
+	run(t, pool, tasks...)
+
+	// Задачи b не должны ждать, пока выполнятся все задачи a.
+	want := []string{"a", "b", "a", "b", "a", "a", "a", "a"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+// lifecycleTask записывает вызовы OnStart, OnComplete и OnError.
+type lifecycleTask struct {
+	*testTask
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (t *lifecycleTask) record(event string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *lifecycleTask) OnStart(attempt int) { t.record(fmt.Sprintf("start %d", attempt)) }
+func (t *lifecycleTask) OnComplete(v string) { t.record("complete " + v) }
+func (t *lifecycleTask) OnError(error)       { t.record("error") }
+
+func TestLifecycleCallbacks(t *testing.T) {
+	ok := &lifecycleTask{testTask: &testTask{name: "ok", fails: 1}}
+	bad := &lifecycleTask{testTask: &testTask{name: "bad", fails: 5}}
+
+	pool := newPool(t, 2, 2, WithRetries(1), WithBackoff(time.Millisecond, time.Millisecond))
+	run(t, pool, ok, bad)
+
+	want := map[*lifecycleTask][]string{
+		ok:  {"start 1", "start 2", "complete ok"},
+		bad: {"start 1", "start 2", "error"},
+	}
+	for task, events := range want {
+		if fmt.Sprint(task.events) != fmt.Sprint(events) {
+			t.Errorf("%s: events = %v, want %v", task.name, task.events, events)
+		}
+	}
+}
+
+// panickingTask паникует в выбранном обработчике.
+type panickingTask struct {
+	*testTask
+	hook string
+}
+
+func (t *panickingTask) OnStart(int) {
+	if t.hook == "start" {
+		panic("start")
+	}
+}
+
+func (t *panickingTask) OnComplete(string) {
+	if t.hook == "complete" {
+		panic("complete")
+	}
+}
+
+func (t *panickingTask) OnError(error) {
+	if t.hook == "error" {
+		panic("error")
+	}
+}
+
+func TestPanickingCallbacks(t *testing.T) {
+	pool := newPool(t, 2, 3)
+	results := run(t, pool,
+		&panickingTask{testTask: &testTask{name: "start"}, hook: "start"},
+		&panickingTask{testTask: &testTask{name: "complete"}, hook: "complete"},
+		&panickingTask{testTask: &testTask{name: "error", fails: 1}, hook: "error"},
+	)
+
+	for _, name := range []string{"start", "complete", "error"} {
+		var panicErr *PanicError
+		if res := results[name]; !errors.As(res.Err, &panicErr) || panicErr.Value != name {
+			t.Errorf("%s: err = %v, want a panic in the hook", name, res.Err)
+		}
+	}
+	if pool.Metrics().Failed != 3 {
+		t.Errorf("failed = %d, want 3", pool.Metrics().Failed)
+	}
+}
+
+// overrideTask задает свои лимит времени и число повторов.
+type overrideTask struct {
+	*testTask
+	timeout time.Duration
+	retries int
+}
+
+func (t *overrideTask) Timeout() time.Duration { return t.timeout }
+func (t *overrideTask) MaxRetries() int        { return t.retries }
+
+func TestPerTaskOverrides(t *testing.T) {
+	retried := &overrideTask{testTask: &testTask{name: "retried", fails: 2}, retries: 2}
+	slow := &overrideTask{testTask: &testTask{name: "slow", delay: time.Second}, timeout: 20 * time.Millisecond, retries: -1}
+
+	pool := newPool(t, 2, 2, WithBackoff(time.Millisecond, time.Millisecond))
+	results := run(t, pool, retried, slow)
+	if res := results["retried"]; res.Err != nil || res.Attempts != 3 {
+		t.Errorf("retried: attempts = %d, err = %v, want 3 attempts and no error", res.Attempts, res.Err)
+	}
+	if res := results["slow"]; !errors.Is(res.Err, ErrTaskTimeout) || res.Attempts != 1 {
+		t.Errorf("slow: attempts = %d, err = %v, want 1 attempt and ErrTaskTimeout", res.Attempts, res.Err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	var (
+		mu     sync.Mutex
+		starts []time.Time
+	)
+	start := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		starts = append(starts, time.Now())
+	}
+
+	pool := newPool(t, 4, 10, WithRateLimit(float64(time.Second/interval), 1))
+	var tasks []Task[string]
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		tasks = append(tasks, &testTask{name: name, onStart: start})
+	}
+	run(t, pool, tasks...)
+
+	if len(starts) != len(tasks) {
+		t.Fatalf("started %d tasks, want %d", len(starts), len(tasks))
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	// Допуск на неточность таймеров
+	const tolerance = 10 * time.Millisecond
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < interval-tolerance {
+			t.Errorf("start %d came %v after the previous one, want at least %v", i, gap, interval)
+		}
+	}
+}
+
+func TestMetricsAfterRun(t *testing.T) {
+	pool := newPool(t, 2, 10, WithRetries(1), WithBackoff(time.Millisecond, time.Millisecond))
+	run(t, pool,
+		&testTask{name: "ok"},
+		&testTask{name: "recovers", fails: 1},
+		&testTask{name: "gives-up", fails: 5},
+		&testTask{name: "panics", panics: true},
+	)
+
+	// Попытки: ok - 1, recovers - 2, gives-up - 2, panics - 1 (паника не повторяется)
+	m := pool.Metrics()
+	if m.Started != 6 || m.Retried != 2 || m.Succeeded != 2 || m.Failed != 2 {
+		t.Errorf("counters = started %d, retried %d, succeeded %d, failed %d, want 6, 2, 2, 2",
+			m.Started, m.Retried, m.Succeeded, m.Failed)
+	}
+	if m.QueueWait.Count != 6 || m.Execution.Count != 6 {
+		t.Errorf("histogram counts = queue wait %d, execution %d, want 6 each", m.QueueWait.Count, m.Execution.Count)
+	}
+	if p := pool.Progress(); p.Done != 2 || p.Failed != 2 || p.Queued != 0 || p.Running != 0 || p.Total() != 4 {
+		t.Errorf("Progress() = %+v, want 2 done and 2 failed", p)
+	}
+}
+
+func TestErrJoinsFailures(t *testing.T) {
+	pool := newPool(t, 2, 10)
+	run(t, pool, &testTask{name: "ok"}, &testTask{name: "fails", fails: 1}, &testTask{name: "panics", panics: true})
+
+	err := pool.Err()
+	if err == nil {
+		t.Fatal("Err() = nil, want the failures")
+	}
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Errorf("Err() = %v, want it to wrap the PanicError", err)
+	}
+	var names []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var taskErr *TaskError
+		if !errors.As(e, &taskErr) {
+			t.Fatalf("joined error %v is not a TaskError", e)
+		}
+		names = append(names, taskErr.Name)
+	}
+	slices.Sort(names)
+	if fmt.Sprint(names) != "[fails panics]" {
+		t.Errorf("failed tasks = %v, want [fails panics]", names)
+	}
+}
+
+func TestProgressWhileRunning(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := &testTask{name: "blocking", onStart: func() {
+		close(started)
+		<-release
+	}}
+
+	pool := newPool(t, 1, 10)
+	for _, task := range []Task[string]{blocking, &testTask{name: "next"}} {
+		if _, err := pool.AddTask(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Close()
+	runErr := make(chan error, 1)
+	go func() { runErr <- pool.Run(context.Background()) }()
+
+	<-started
+	if p := pool.Progress(); p.Running != 1 || p.Queued != 1 || p.Done != 0 || p.Workers != 1 || p.Total() != 2 {
+		t.Errorf("Progress() while running = %+v, want 1 running and 1 queued", p)
+	}
+	close(release)
+	for range pool.Results() {
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if p := pool.Progress(); p.Done != 2 || p.Queued != 0 || p.Running != 0 || p.Elapsed <= 0 {
+		t.Errorf("Progress() after run = %+v, want 2 done", p)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		events  = make(map[string][]string)
+		workers = make(map[string]int)
+	)
+	record := func(kind string) func(TaskEvent) {
+		return func(e TaskEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			event := fmt.Sprintf("%s %d", kind, e.Attempt)
+			if e.Err != nil {
+				event += " err"
+			}
+			events[e.Name] = append(events[e.Name], event)
+		}
+	}
+	worker := func(kind string) func(int) {
+		return func(int) {
+			mu.Lock()
+			defer mu.Unlock()
+			workers[kind]++
+		}
+	}
+
+	pool := newPool(t, 2, 10, WithRetries(1), WithBackoff(time.Millisecond, time.Millisecond), WithHooks(Hooks{
+		OnWorkerStart: worker("start"),
+		OnWorkerStop:  worker("stop"),
+		OnTaskStart:   record("start"),
+		OnTaskRetry:   record("retry"),
+		OnTaskDone:    record("done"),
+		OnTaskError:   record("error"),
+	}))
+	run(t, pool, &testTask{name: "ok"}, &testTask{name: "recovers", fails: 1}, &testTask{name: "gives-up", fails: 5})
+
+	want := map[string][]string{
+		"ok":       {"start 1", "done 1"},
+		"recovers": {"start 1", "retry 1 err", "start 2", "done 2"},
+		"gives-up": {"start 1", "retry 1 err", "start 2", "error 2 err"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("task events = %v, want %v", events, want)
+	}
+	if workers["start"] != 2 || workers["stop"] != 2 {
+		t.Errorf("worker events = %v, want 2 starts and 2 stops", workers)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	m := Metrics{Started: 3, Succeeded: 1, Failed: 1, Retried: 1}
+	bounds := []time.Duration{time.Second, 2 * time.Second}
+	m.QueueWait = newHistogram(bounds)
+	m.Execution = newHistogram(bounds)
+	for _, d := range []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond, 3 * time.Second} {
+		m.Execution.observe(d)
+	}
+
+	var b strings.Builder
+	if err := m.WritePrometheus(&b, "ish"); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	want := `# HELP ish_tasks_started_total Task execution attempts started.
+# TYPE ish_tasks_started_total counter
+ish_tasks_started_total 3
+# HELP ish_tasks_succeeded_total Tasks completed successfully.
+# TYPE ish_tasks_succeeded_total counter
+ish_tasks_succeeded_total 1
+# HELP ish_tasks_failed_total Tasks failed after all attempts.
+# TYPE ish_tasks_failed_total counter
+ish_tasks_failed_total 1
+# HELP ish_tasks_retried_total Task attempts that were retried.
+# TYPE ish_tasks_retried_total counter
+ish_tasks_retried_total 1
+# HELP ish_queue_wait_seconds Time tasks spent in the queue.
+# TYPE ish_queue_wait_seconds histogram
+ish_queue_wait_seconds_bucket{le="1"} 0
+ish_queue_wait_seconds_bucket{le="2"} 0
+ish_queue_wait_seconds_bucket{le="+Inf"} 0
+ish_queue_wait_seconds_sum 0
+ish_queue_wait_seconds_count 0
+# HELP ish_execution_seconds Task execution time per attempt.
+# TYPE ish_execution_seconds histogram
+ish_execution_seconds_bucket{le="1"} 1
+ish_execution_seconds_bucket{le="2"} 2
+ish_execution_seconds_bucket{le="+Inf"} 3
+ish_execution_seconds_sum 5
+ish_execution_seconds_count 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("WritePrometheus output:\n%s\nwant:\n%s", got, want)
+	}
+}
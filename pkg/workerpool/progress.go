@@ -0,0 +1,57 @@
+package workerpool
+
+import "time"
+
+// Progress - снимок состояния пула.
+type Progress struct {
+	// Queued - задачи, которые ждут воркера, повтора или места по ключу.
+	Queued  int
+	Running int
+	Done    int
+	Failed  int
+	Workers int
+	Elapsed time.Duration
+	// AvgDuration - среднее время выполнения задачи с учетом повторов.
+	AvgDuration time.Duration
+	// ETA - оценка оставшегося времени по среднему времени задачи
+	// и текущему числу воркеров. Ноль, если оценить пока нельзя.
+	ETA time.Duration
+}
+
+// Total возвращает общее число известных пулу задач.
+func (p Progress) Total() int {
+	return p.Queued + p.Running + p.Done + p.Failed
+}
+
+// Progress возвращает текущее состояние пула. Безопасно вызывать
+// из любой горутины, в том числе во время Run.
+func (p *Pool[R]) Progress() Progress {
+	p.mu.Lock()
+	inflight := len(p.inflight)
+	p.mu.Unlock()
+
+	p.scaleMu.Lock()
+	workers := p.workers
+	started := p.started
+	p.scaleMu.Unlock()
+
+	running := int(p.running.Load())
+	progress := Progress{
+		Queued:  max(inflight-running, 0),
+		Running: running,
+		Done:    int(p.succeeded.Load()),
+		Failed:  int(p.failed.Load()),
+		Workers: workers,
+	}
+	if !started.IsZero() {
+		progress.Elapsed = time.Since(started)
+	}
+
+	if finished := progress.Done + progress.Failed; finished > 0 {
+		progress.AvgDuration = time.Duration(p.busyTime.Load() / int64(finished))
+		if workers > 0 {
+			progress.ETA = progress.AvgDuration * time.Duration(inflight) / time.Duration(workers)
+		}
+	}
+	return progress
+}
@@ -0,0 +1,243 @@
+package workerpool
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Prioritized - необязательный интерфейс задачи. Задачи с большим приоритетом
+// выдаются воркерам раньше; при равном приоритете соблюдается порядок добавления.
+// Задачи без этого интерфейса имеют приоритет 0.
+type Prioritized interface {
+	Priority() int
+}
+
+func priorityOf[R any](t Task[R]) int {
+	if p, ok := t.(Prioritized); ok {
+		return p.Priority()
+	}
+	return 0
+}
+
+// taskQueue - очередь с приоритетами. Если capacity больше нуля, push может
+// ждать, пока в очереди освободится место; pop ждет, пока она пуста и не закрыта.
+//
+// Задачи хранятся в полосах: без честного планирования полоса одна, с ним -
+// по полосе на ключ задачи (Keyed). pop берет задачу с наибольшим
+// приоритетом, а при равенстве - из полосы, которая дольше всех не обслуживалась.
+type taskQueue[R any] struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	lanes    map[string]*taskHeap[R]
+	fair     bool
+	served   map[string]uint64
+	size     int
+	capacity int
+	seq      uint64
+	closed   bool
+	// paused - pop не выдает задачи, пока очередь на паузе.
+	paused bool
+}
+
+func newTaskQueue[R any](capacity int, fair bool) *taskQueue[R] {
+	q := &taskQueue[R]{
+		capacity: capacity,
+		fair:     fair,
+		lanes:    make(map[string]*taskHeap[R]),
+		served:   make(map[string]uint64),
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	return q
+}
+
+// WithFairScheduling включает честную очередь: задачи с разными ключами
+// (Keyed, например хостами) выдаются по кругу, поэтому сайт с большим числом
+// задач не задерживает остальные. Приоритеты по-прежнему учитываются первыми.
+func WithFairScheduling() Option {
+	return func(o *options) {
+		o.fair = true
+	}
+}
+
+// push добавляет задачу в очередь. Если очередь заполнена, при wait ждет
+// места или отмены ctx, иначе сразу возвращает ErrQueueFull.
+func (q *taskQueue[R]) push(ctx context.Context, qt queuedTask[R], wait bool) error {
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.notFull.Broadcast()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && q.full() {
+		if !wait {
+			return ErrQueueFull
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.notFull.Wait()
+	}
+	if q.closed {
+		return ErrPoolClosed
+	}
+
+	var lane string
+	if q.fair {
+		lane = keyOf(qt.task)
+	}
+	h, ok := q.lanes[lane]
+	if !ok {
+		h = &taskHeap[R]{}
+		q.lanes[lane] = h
+	}
+
+	q.seq++
+	qt.enqueued = time.Now()
+	heap.Push(h, heapItem[R]{task: qt, priority: priorityOf(qt.task), seq: q.seq})
+	q.size++
+	q.notEmpty.Signal()
+	return nil
+}
+
+func (q *taskQueue[R]) full() bool {
+	return q.capacity > 0 && q.size >= q.capacity
+}
+
+// nextLane выбирает полосу для следующей задачи. Вызывается под mu.
+func (q *taskQueue[R]) nextLane() string {
+	var (
+		best     string
+		bestItem heapItem[R]
+		found    bool
+	)
+	for lane, h := range q.lanes {
+		head := (*h)[0]
+		if found {
+			if head.priority < bestItem.priority {
+				continue
+			}
+			if head.priority == bestItem.priority && q.served[lane] > q.served[best] {
+				continue
+			}
+			if head.priority == bestItem.priority && q.served[lane] == q.served[best] && head.seq > bestItem.seq {
+				continue
+			}
+		}
+		best, bestItem, found = lane, head, true
+	}
+	return best
+}
+
+// pop возвращает задачу с наибольшим приоритетом. ok равно false, если
+// очередь закрыта и разобрана или отменен ctx.
+func (q *taskQueue[R]) pop(ctx context.Context) (qt queuedTask[R], ok bool) {
+	stop := context.AfterFunc(ctx, q.wake)
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for (q.size == 0 && !q.closed || q.paused) && ctx.Err() == nil {
+		q.notEmpty.Wait()
+	}
+	if ctx.Err() != nil || q.size == 0 {
+		return qt, false
+	}
+
+	lane := q.nextLane()
+	h := q.lanes[lane]
+	item := heap.Pop(h).(heapItem[R])
+	if h.Len() == 0 {
+		delete(q.lanes, lane)
+	}
+	q.size--
+	q.seq++
+	q.served[lane] = q.seq
+
+	q.notFull.Signal()
+	return item.task, true
+}
+
+// len возвращает число задач в очереди.
+func (q *taskQueue[R]) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// setPaused приостанавливает или возобновляет выдачу задач.
+func (q *taskQueue[R]) setPaused(paused bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.paused = paused
+	q.notEmpty.Broadcast()
+}
+
+func (q *taskQueue[R]) isPaused() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.paused
+}
+
+// close запрещает добавление задач. Оставшиеся в очереди задачи еще можно получить.
+func (q *taskQueue[R]) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
+
+// drain выбрасывает задачи из очереди и закрывает ее.
+func (q *taskQueue[R]) drain() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lanes = make(map[string]*taskHeap[R])
+	q.size = 0
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
+
+func (q *taskQueue[R]) wake() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.notEmpty.Broadcast()
+}
+
+type heapItem[R any] struct {
+	task     queuedTask[R]
+	priority int
+	seq      uint64
+}
+
+// taskHeap реализует heap.Interface: сначала больший приоритет, затем меньший seq.
+type taskHeap[R any] []heapItem[R]
+
+func (h taskHeap[R]) Len() int { return len(h) }
+
+func (h taskHeap[R]) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap[R]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap[R]) Push(x any) { *h = append(*h, x.(heapItem[R])) }
+
+func (h *taskHeap[R]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,65 @@
+package workerpool
+
+import (
+	"context"
+	"sort"
+)
+
+// TaskInfo идентифицирует задачу пула.
+type TaskInfo struct {
+	TaskID int
+	Name   string
+}
+
+// Shutdown перестает принимать задачи и ждет, пока пул доделает начатое,
+// но не дольше, чем живет ctx. По истечении ctx выполняемые задачи
+// отменяются. Возвращает задачи, которые так и не завершились, и ошибку ctx,
+// если пришлось прерывать работу. Run должен быть запущен.
+func (p *Pool[R]) Shutdown(ctx context.Context) ([]TaskInfo, error) {
+	p.Close()
+
+	select {
+	case <-p.done:
+		return p.Abandoned(), nil
+	case <-ctx.Done():
+	}
+
+	p.scaleMu.Lock()
+	cancel := p.cancelRun
+	p.scaleMu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-p.done
+	}
+	return p.Abandoned(), ctx.Err()
+}
+
+// Stop перестает запускать задачи: задачи из очереди, отложенные по ключу
+// и ожидающие зависимостей больше не выполняются и попадают в Abandoned.
+// Выполняемые задачи доделываются, но не дольше, чем живет ctx, как
+// в Shutdown. Run должен быть запущен.
+func (p *Pool[R]) Stop(ctx context.Context) ([]TaskInfo, error) {
+	p.mu.Lock()
+	p.closing = true
+	p.mu.Unlock()
+	p.queue.drain()
+	p.keys.drop()
+	return p.Shutdown(ctx)
+}
+
+// Abandoned возвращает задачи, которые не были выполнены до конца:
+// оставшиеся в очереди, ожидавшие повтора или зависимостей и прерванные
+// отменой. Имеет смысл после того, как Run вернул управление.
+func (p *Pool[R]) Abandoned() []TaskInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	abandoned := make([]TaskInfo, 0, len(p.inflight))
+	for id, name := range p.inflight {
+		abandoned = append(abandoned, TaskInfo{TaskID: id, Name: name})
+	}
+	sort.Slice(abandoned, func(i, j int) bool {
+		return abandoned[i].TaskID < abandoned[j].TaskID
+	})
+	return abandoned
+}